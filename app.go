@@ -2,15 +2,28 @@ package main
 
 import (
 	"context"
-	"encoding/base64"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math/rand/v2"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"time"
+	"todo-ball/apperr"
+	"todo-ball/applog"
+	"todo-ball/dates"
+	"todo-ball/dnd"
+	"todo-ball/focus"
+	"todo-ball/icons"
 	"todo-ball/models"
 	"todo-ball/platform"
+	"todo-ball/quickadd"
+	"todo-ball/remind"
 	"todo-ball/storage"
+	"todo-ball/tracking"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
@@ -22,53 +35,134 @@ type App struct {
 
 	// Internal state
 	currentDockState string
+	ballMenuOpen     atomic.Bool  // Also read by the ball look loop
+	ballShown        BallGeometry // Layout the ball window was last sized for
 
-	// Icons
-	IconConfig IconConfig
+	// Per-state icons from icon_config.json
+	appIcons *iconManager
+
+	// Natural-language parser for QuickAdd; its clock can be swapped in tests
+	quickAdd quickadd.Parser
+
+	// Pomodoro timer shared with the other process through focus.json
+	focus *focus.Manager
+
+	// Time tracker, also shared through a file
+	tracking *tracking.Manager
+
+	// Reminders fired by the ball, held back while the user is busy
+	reminders *remind.Scheduler
+
+	// Manual do-not-disturb, shared through dnd.json
+	dnd *dnd.Manager
+
+	// Imported images such as the custom ball icon
+	assets *icons.Assets
+
+	// Tray menu (main mode only), set once systray is ready
+	tray atomic.Pointer[trayMenu]
 
 	// Flags
 	shouldQuit bool
+	rekeying   atomic.Bool // The ball is stopped while the data is re-encoded
+
+	// Global hotkeys (main mode only)
+	hotkeys           *platform.HotkeyManager
+	hotkeyMu          sync.Mutex
+	registeredHotkeys map[int]platform.Hotkey
 }
 
-func NewApp(mode string, iconConfig IconConfig) *App {
-	store, err := storage.NewStorage()
+func NewApp(mode string, dataDir string) (*App, error) {
+	store, err := storage.NewStorage(dataDir)
 	if err != nil {
-		// Ignore for now
+		return nil, apperr.Wrap(apperr.IO, "storage.init_failed", err)
+	}
+	assets := icons.NewAssets(store.DataDir)
+	// Only the ball shows ball pictures, so only it imports them
+	var ballAssets *icons.Assets
+	if mode == "ball" {
+		ballAssets = assets
 	}
 	return &App{
 		Store:            store,
 		Mode:             mode,
 		currentDockState: "none",
-		IconConfig:       iconConfig,
-	}
+		appIcons:         newIconManager(getAppDir(), ballAssets),
+		focus:            focus.NewManager(store.DataDir),
+		tracking:         tracking.NewManager(store.DataDir),
+		reminders:        remind.NewScheduler(),
+		dnd:              dnd.NewManager(store.DataDir),
+		assets:           assets,
+	}, nil
 }
 
+// Signalled on every change to the data, waking the ball and the main
+// process respectively; see notifyUpdate
 const UpdateEventName = "Local\\TodoBallUpdateEvent"
+const MainUpdateEventName = "Local\\TodoBallMainUpdateEvent"
 const QuitEventName = "Local\\TodoBallQuitEvent"
 
+// Wall-clock due times from the frontend, as sent by <input type="datetime-local">
+const dueLayout = "2006-01-02T15:04"
+
+// Ball window layout in logical (96 DPI) pixels, around the ball itself
+// whose size comes from the config.
+const (
+	ballMenuWidth  = 90  // Window width while the right-click menu is open
+	ballMenuHeight = 105 // Added below the ball for the menu
+	dockedWidth    = 10
+	dockedMargin   = 20 // Docked strip is this much taller than the ball
+
+	// Imported ball icons are stored at twice the largest ball size, sharp up to 200% scaling
+	ballIconPixels = models.MaxBallSize * 2
+)
+
+// BallGeometry is the ball window layout for a config, in logical pixels.
+// Scaled per monitor with platform.MonitorInfo.Scale before calling SetWindowPos.
+type BallGeometry struct {
+	Width        int `json:"width"` // The ball, and the window while idle
+	Height       int `json:"height"`
+	MenuWidth    int `json:"menu_width"` // Window with the menu open
+	MenuHeight   int `json:"menu_height"`
+	DockedWidth  int `json:"docked_width"` // Strip at the screen edge
+	DockedHeight int `json:"docked_height"`
+}
+
+func ballGeometryFor(c models.AppConfig) BallGeometry {
+	w, h := c.BallSize, c.BallSize
+	if c.BallShape == models.BallShapePill {
+		h = w / 2
+	}
+	return BallGeometry{
+		Width:        w,
+		Height:       h,
+		MenuWidth:    max(w, ballMenuWidth),
+		MenuHeight:   h + ballMenuHeight,
+		DockedWidth:  dockedWidth,
+		DockedHeight: h + dockedMargin,
+	}
+}
+
+// GetBallGeometry returns the ball window layout for the current config.
+func (a *App) GetBallGeometry() BallGeometry {
+	return ballGeometryFor(a.Store.Config())
+}
+
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 
+	// Both windows show the focus countdown
+	a.startFocusTicker()
+
 	if a.Mode == "ball" {
 		// Start docking detection loop
 		a.startDockingLoop()
 
+		// Main relaunches the ball after unlocking or re-keying
+		go a.listenRestartBall()
+
 		// Create Event for IPC
-		go func() {
-			hEvent, err := platform.CreateEvent(UpdateEventName)
-			if err == nil && hEvent != 0 {
-				defer platform.CloseHandle(hEvent)
-				for {
-					// Wait for signal
-					platform.WaitForSingleObject(hEvent, platform.INFINITE)
-					// Reload data
-					a.Store.LoadTodos()
-					a.Store.LoadConfig()
-					// Emit event to frontend
-					runtime.EventsEmit(a.ctx, "todos_updated")
-				}
-			}
-		}()
+		go a.listenForUpdates(UpdateEventName)
 
 		// Apply Win32 tweaks
 		go func() {
@@ -82,120 +176,351 @@ func (a *App) startup(ctx context.Context) {
 				time.Sleep(100 * time.Millisecond)
 			}
 
-			if hwnd != 0 {
+			if hwnd == 0 {
+				slog.Warn("ball window not found, skipping Win32 tweaks")
+			} else {
 				platform.MakeFrameless(hwnd) // Force remove caption/border
 				platform.HideFromTaskbar(hwnd)
 				platform.SetTopMost(hwnd)
 				// platform.SetWindowCircular(hwnd, 80, 80) // Disable region to allow resizing
+				// Opacity, fading and click-through are applied natively
+				a.startBallLook(hwnd)
+				// Out of the way during fullscreen apps and presentations
+				a.startBusyWatcher(hwnd)
 			}
 		}()
 	} else {
+		// Started here rather than before wails.Run so its refresh loop
+		// can stop with a.ctx
+		a.startTray()
+
+		// Changes made from the ball reach the tray and the list
+		go a.listenForUpdates(MainUpdateEventName)
+
 		// Main window logic
 		go func() {
 			time.Sleep(500 * time.Millisecond)
-			hwnd := platform.FindWindow("待办事项")
-			if hwnd != 0 {
-				iconPath := a.IconConfig.MainWindowIcon
-				if iconPath == "" {
-					iconPath = GetAppIconPath()
-				}
-				platform.SetWindowIcon(hwnd, iconPath, platform.ICON_SMALL)
-				platform.SetWindowIcon(hwnd, iconPath, platform.ICON_BIG)
-			}
+			// Window and tray icons follow the state and icon_config.json
+			a.startIconWatcher()
 
 			// Listen for Quit Event
 			go func() {
 				hEvent, err := platform.CreateEvent(QuitEventName)
-				if err == nil && hEvent != 0 {
-					defer platform.CloseHandle(hEvent)
-					for {
-						status, _ := platform.WaitForSingleObject(hEvent, platform.INFINITE)
-						if status == platform.WAIT_OBJECT_0 {
-							a.shouldQuit = true
-							runtime.Quit(a.ctx)
-							return
-						}
+				if err != nil || hEvent == 0 {
+					slog.Error("CreateEvent failed, quit from ball won't reach main", "event", QuitEventName, "err", err)
+					return
+				}
+				defer platform.CloseHandle(hEvent)
+				for {
+					status, err := platform.WaitForSingleObject(hEvent, platform.INFINITE)
+					if err != nil {
+						slog.Error("WaitForSingleObject failed", "event", QuitEventName, "err", err)
+						return
+					}
+					if status == platform.WAIT_OBJECT_0 {
+						slog.Info("quit event received")
+						a.shouldQuit = true
+						runtime.Quit(a.ctx)
+						return
 					}
 				}
 			}()
 
-			// Launch ball if not running
-			ballHwnd := platform.FindWindow("悬浮球")
-			if ballHwnd == 0 {
-				exe, err := os.Executable()
-				if err == nil {
-					// Log launch attempt
-					if logFile, err := os.OpenFile("startup.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err == nil {
-						logFile.WriteString(fmt.Sprintf("%s Launching ball subprocess: %s\n", time.Now().Format(time.RFC3339), exe))
-						logFile.Close()
-					}
+			// Icons picked before assets were managed are still file paths
+			a.migrateCustomIcon()
 
-					cmd := exec.Command(exe, "-mode", "ball")
-					cmd.Dir = filepath.Dir(exe)
-					if err := cmd.Start(); err != nil {
-						if logFile, err := os.OpenFile("startup.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err == nil {
-							logFile.WriteString(fmt.Sprintf("%s Failed to start ball: %s\n", time.Now().Format(time.RFC3339), err.Error()))
-							logFile.Close()
-						}
-					}
-				}
+			// Reminders saved before todos had several
+			a.migrateReminderDays()
+
+			// Move old completed todos out of the lists
+			a.startArchiver()
+
+			// Pause time tracking while the user is away
+			a.startIdleWatcher()
+
+			// Bring deferred todos back when they start
+			a.startDeferWatcher()
+
+			// Register global hotkeys
+			if err := a.startHotkeys(); err != nil {
+				slog.Error("register hotkeys failed", "err", err)
+			}
+
+			// Launch ball if not running
+			if platform.FindWindow("悬浮球") == 0 {
+				a.launchBall()
 			}
 		}()
 	}
 }
 
+// launchBall starts the floating ball subprocess
+func (a *App) launchBall() {
+	if a.rekeying.Load() {
+		slog.Info("not launching ball while re-encoding")
+		return
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		slog.Error("cannot locate executable to launch ball", "err", err)
+		return
+	}
+	slog.Info("launching ball subprocess", "exe", exe)
+
+	args := a.processArgs("ball")
+	stdin := a.keyStdin()
+	if stdin != nil {
+		args = append(args, "-key-stdin")
+	}
+	cmd := exec.Command(exe, args...)
+	cmd.Dir = filepath.Dir(exe)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	if err := cmd.Start(); err != nil {
+		slog.Error("failed to start ball", "exe", exe, "err", err)
+	}
+}
+
+// processArgs builds the command line for the other process so both
+// share the same data directory.
+func (a *App) processArgs(mode string) []string {
+	return []string{"-mode", mode, "-data-dir", a.Store.DataDir}
+}
+
 // GetTodos returns the list of todo items
-func (a *App) GetTodos() []models.TodoItem {
-	return a.Store.GetTodos()
+func (a *App) GetTodos() ([]models.TodoItem, error) {
+	if a.Store.IsLocked() {
+		return nil, apperr.New(apperr.Locked, "store.locked")
+	}
+	return a.Store.GetTodos(), nil
+}
+
+// parseDue reads a due date sent by the frontend: the wall-clock time in
+// timeZone as "2006-01-02T15:04", or just the day if allDay. timeZone is
+// an IANA name; "" means the computer's zone.
+func parseDue(dueTimeStr string, timeZone string, allDay bool) (time.Time, error) {
+	if !dates.ValidZone(timeZone) {
+		return time.Time{}, apperr.Invalid("time_zone", "todo.invalid_time_zone", timeZone)
+	}
+	layout := dueLayout
+	if allDay {
+		layout = time.DateOnly
+	}
+	wall, err := time.Parse(layout, dueTimeStr)
+	if err != nil {
+		e := apperr.Invalid("due_date", "todo.invalid_due_date", dueTimeStr)
+		e.Err = err
+		return time.Time{}, e
+	}
+	return wall, nil
 }
 
-// AddTodo adds a new todo item
-func (a *App) AddTodo(title string, dueTimeStr string, reminderDays int) {
-	dueDate, _ := time.Parse(time.RFC3339, dueTimeStr)
+// newTodoID returns an ID for a todo created at now. The clock alone can
+// repeat, within a process and between the main window and the ball, so a
+// random suffix follows it.
+func newTodoID(now time.Time) string {
+	return fmt.Sprintf("%d-%08x", now.UnixNano(), rand.Uint32())
+}
 
-	item := models.TodoItem{
-		ID:           fmt.Sprintf("%d", time.Now().UnixNano()),
-		Title:        title,
-		Completed:    false,
-		DueDate:      dueDate,
-		ReminderDays: reminderDays,
+// findTodo returns the todo with id from the active list.
+func (a *App) findTodo(id string) (models.TodoItem, error) {
+	for _, t := range a.Store.GetTodos() {
+		if t.ID == id {
+			return t, nil
+		}
 	}
+	return models.TodoItem{}, apperr.New(apperr.NotFound, "todo.not_found")
+}
+
+// AddTodo adds a new todo item. See parseDue for the due date and
+// models.Reminder for the reminders.
+func (a *App) AddTodo(title string, dueTimeStr string, timeZone string, allDay bool, reminders []models.Reminder) error {
+	wall, err := parseDue(dueTimeStr, timeZone, allDay)
+	if err != nil {
+		return err
+	}
+
+	item := models.TodoItem{
+		ID:        newTodoID(time.Now()),
+		Title:     title,
+		Completed: false,
+		CreatedAt: time.Now(),
+		Reminders: reminders,
+	}.WithDue(wall, timeZone, allDay)
 	// Wait for save to complete before notifying
-	if err := a.Store.AddTodo(item); err == nil {
-		a.notifyUpdate()
+	if err := a.Store.AddTodo(item); err != nil {
+		return storeError("todo.save_failed", err)
+	}
+	a.notifyUpdate()
+	return nil
+}
+
+// UpdateTodo changes the title, due date and reminders of a todo in the
+// active list, taking the same arguments as AddTodo. The rest of the todo
+// is kept.
+func (a *App) UpdateTodo(id string, title string, dueTimeStr string, timeZone string, allDay bool, reminders []models.Reminder) error {
+	item, err := a.findTodo(id)
+	if err != nil {
+		return err
 	}
+	wall, err := parseDue(dueTimeStr, timeZone, allDay)
+	if err != nil {
+		return err
+	}
+
+	item = item.WithDue(wall, timeZone, allDay)
+	item.Title = title
+	item.Reminders = reminders
+	// Replaced by the list above
+	item.ReminderDays = 0
+	if err := a.Store.UpdateTodo(item); err != nil {
+		return storeError("todo.save_failed", err)
+	}
+	a.notifyUpdate()
+	return nil
+}
+
+// QuickAdd parses a free-text line such as "Send report tomorrow 5pm !high #work remind 2d"
+// and adds the resulting todo. Days and times are read in timeZone, "" for
+// the computer's zone. Returns a *quickadd.ParseError if the line is rejected.
+func (a *App) QuickAdd(text string, timeZone string) (models.TodoItem, error) {
+	if !dates.ValidZone(timeZone) {
+		return models.TodoItem{}, apperr.Invalid("time_zone", "todo.invalid_time_zone", timeZone)
+	}
+	parser := a.quickAdd
+	parser.Location = dates.Zone(timeZone)
+	res, err := parser.Parse(text)
+	if err != nil {
+		var pe *quickadd.ParseError
+		if errors.As(err, &pe) {
+			e := apperr.Invalid("text", "quickadd."+string(pe.Code), pe.Fragment)
+			e.Err = err
+			return models.TodoItem{}, e
+		}
+		return models.TodoItem{}, apperr.Wrap(apperr.Internal, "internal", err)
+	}
+
+	now := time.Now()
+	if parser.Now != nil {
+		now = parser.Now()
+	}
+
+	// No date given: due at the end of today
+	dueDate, allDay := res.DueDate, res.AllDay
+	if dueDate.IsZero() {
+		dueDate, allDay = dates.EndOfDay(now, parser.Location), true
+	}
+
+	// The default reminder unless the text asked for another
+	var reminders []models.Reminder
+	switch {
+	case res.Reminder > 0:
+		reminders = []models.Reminder{models.RemindBefore(res.Reminder)}
+	case a.Store.Config().NotificationDays > 0:
+		reminders = []models.Reminder{{Before: a.Store.Config().NotificationDays, Unit: models.UnitDays}}
+	}
+
+	item := models.TodoItem{
+		ID:        newTodoID(now),
+		Title:     res.Title,
+		DueDate:   dueDate,
+		TimeZone:  timeZone,
+		AllDay:    allDay,
+		CreatedAt: now,
+		Reminders: reminders,
+		Priority:  res.Priority,
+		Tags:      res.Tags,
+	}
+	if err := a.Store.AddTodo(item); err != nil {
+		return models.TodoItem{}, storeError("todo.save_failed", err)
+	}
+	a.notifyUpdate()
+	return item, nil
 }
 
 // ToggleTodo toggles the completed status of a todo item
-func (a *App) ToggleTodo(id string) {
+func (a *App) ToggleTodo(id string) error {
 	// Wait for save to complete before notifying
-	if err := a.Store.ToggleTodo(id); err == nil {
-		a.notifyUpdate()
+	if err := a.Store.ToggleTodo(id); err != nil {
+		return storeError("todo.save_failed", err)
 	}
+	a.notifyUpdate()
+	return nil
 }
 
 // DeleteTodo deletes a todo item
-func (a *App) DeleteTodo(id string) {
+func (a *App) DeleteTodo(id string) error {
 	// Wait for save to complete before notifying
-	if err := a.Store.DeleteTodo(id); err == nil {
-		a.notifyUpdate()
+	if err := a.Store.DeleteTodo(id); err != nil {
+		return storeError("todo.save_failed", err)
 	}
+	a.notifyUpdate()
+	return nil
 }
 
-// GetConfig returns the application configuration
-func (a *App) GetConfig() models.AppConfig {
-	a.Store.LoadConfig() // Reload from disk to ensure freshness
+// validationError converts model field errors into a binding error
+func validationError(verrs models.ValidationErrors) error {
+	fields := make([]*apperr.Error, len(verrs))
+	for i, f := range verrs {
+		fields[i] = apperr.Invalid(f.Field, "validation."+f.Rule, f.Params...)
+	}
+	return apperr.InvalidFields(verrs, fields...)
+}
 
-	// Sync with actual registry state
-	enabled := platform.IsAutoStartEnabled()
-	if a.Store.Config.StartOnBoot != enabled {
-		a.Store.Config.StartOnBoot = enabled
-		// Update stored config to match reality, but don't trigger save yet to avoid IO loop?
-		// Actually, we should probably save it if it's different, or just return the corrected value.
-		// Let's just return the corrected value.
+// storeError maps storage errors to binding errors.
+// key is the message used for I/O failures.
+func storeError(key string, err error) error {
+	var verrs models.ValidationErrors
+	switch {
+	case errors.As(err, &verrs):
+		return validationError(verrs)
+	case errors.Is(err, storage.ErrNotFound):
+		return apperr.Wrap(apperr.NotFound, "todo.not_found", err)
+	case errors.Is(err, storage.ErrDuplicateID):
+		return apperr.Wrap(apperr.Conflict, "todo.duplicate_id", err)
+	case errors.Is(err, storage.ErrListNotFound):
+		return apperr.Wrap(apperr.NotFound, "list.not_found", err)
+	case errors.Is(err, storage.ErrDuplicateList):
+		return apperr.Wrap(apperr.Conflict, "list.duplicate", err)
+	case errors.Is(err, storage.ErrDeleteDefault):
+		return apperr.Wrap(apperr.Conflict, "list.delete_default", err)
+	case errors.Is(err, storage.ErrThemeNotFound):
+		return apperr.Wrap(apperr.NotFound, "theme.not_found", err)
+	case errors.Is(err, storage.ErrBuiltinTheme):
+		return apperr.Wrap(apperr.Conflict, "theme.builtin", err)
+	case errors.Is(err, storage.ErrTooManyThemes):
+		return apperr.Wrap(apperr.Conflict, "theme.too_many", err, models.MaxThemes)
+	case errors.Is(err, storage.ErrLocked):
+		return apperr.Wrap(apperr.Locked, "store.locked", err)
+	case errors.Is(err, storage.ErrWrongPassphrase):
+		e := apperr.Wrap(apperr.Validation, "encryption.wrong_passphrase", err)
+		e.Field = "passphrase"
+		return e
+	case errors.Is(err, storage.ErrPassphraseTooShort):
+		e := apperr.Wrap(apperr.Validation, "encryption.too_short", err, storage.MinPassphraseLength)
+		e.Field = "passphrase"
+		return e
+	case errors.Is(err, storage.ErrAlreadyEncrypted):
+		return apperr.Wrap(apperr.Conflict, "encryption.already_enabled", err)
+	case errors.Is(err, storage.ErrNotEncrypted):
+		return apperr.Wrap(apperr.Conflict, "encryption.not_enabled", err)
+	}
+	slog.Error("storage operation failed", "op", key, "err", err)
+	return apperr.Wrap(apperr.IO, key, err)
+}
+
+// GetConfig returns the application configuration
+func (a *App) GetConfig() (models.AppConfig, error) {
+	// Reload from disk to ensure freshness
+	if err := a.Store.LoadConfig(); err != nil {
+		return a.Store.Config(), apperr.Wrap(apperr.IO, "config.load_failed", err)
 	}
 
-	return a.Store.Config
+	// Report the actual registry state; it is saved with the next change
+	cfg := a.Store.Config()
+	cfg.StartOnBoot = platform.IsAutoStartEnabled()
+	return cfg, nil
 }
 
 // OpenMain opens the main window (launches executable in main mode if not running)
@@ -213,9 +538,14 @@ func (a *App) OpenMain() {
 	} else {
 		// Launch main
 		exe, err := os.Executable()
-		if err == nil {
-			cmd := exec.Command(exe, "-mode", "main")
-			cmd.Start()
+		if err != nil {
+			slog.Error("cannot locate executable to launch main", "err", err)
+			return
+		}
+		slog.Info("launching main process", "exe", exe)
+		cmd := exec.Command(exe, a.processArgs("main")...)
+		if err := cmd.Start(); err != nil {
+			slog.Error("failed to start main", "exe", exe, "err", err)
 		}
 	}
 }
@@ -227,8 +557,12 @@ func (a *App) FullQuit() {
 	// Signal global quit event so other process knows to actually quit, not hide
 	hEvent, err := platform.CreateEvent(QuitEventName)
 	if err == nil && hEvent != 0 {
-		platform.SetEvent(hEvent)
+		if err := platform.SetEvent(hEvent); err != nil {
+			slog.Error("SetEvent failed", "event", QuitEventName, "err", err)
+		}
 		platform.CloseHandle(hEvent)
+	} else {
+		slog.Error("CreateEvent failed", "event", QuitEventName, "err", err)
 	}
 
 	if a.Mode == "main" {
@@ -254,25 +588,23 @@ func (a *App) SetBallMenuState(open bool) {
 	}
 	hwnd := platform.FindWindow("悬浮球")
 	if hwnd != 0 {
+		scale := func(px int) int { return px }
+		if mon, err := platform.GetMonitorInfoForWindow(hwnd); err == nil {
+			scale = mon.Scale
+		}
+		g := a.GetBallGeometry()
+		a.ballMenuOpen.Store(open)
+		a.ballShown = g
+		width, height := scale(g.Width), scale(g.Height)
 		if open {
-			platform.SetWindowPos(hwnd, 0, 0, 100, 160, platform.SWP_NOMOVE|platform.SWP_NOZORDER)
-			platform.SetWindowLong(hwnd, platform.GWL_EXSTYLE, platform.WS_EX_LAYERED|platform.WS_EX_TOOLWINDOW)
-		} else {
-			platform.SetWindowPos(hwnd, 0, 0, 100, 80, platform.SWP_NOMOVE|platform.SWP_NOZORDER)
-			platform.SetWindowLong(hwnd, platform.GWL_EXSTYLE, platform.WS_EX_LAYERED|platform.WS_EX_TOOLWINDOW)
+			width, height = scale(g.MenuWidth), scale(g.MenuHeight)
+		}
+		if err := platform.SetWindowPos(hwnd, 0, 0, width, height, platform.SWP_NOMOVE|platform.SWP_NOZORDER); err != nil {
+			slog.Warn("SetWindowPos failed", "op", "ball_menu", "open", open, "err", err)
 		}
 	}
 }
 
-// GetImageBase64 reads an image file and returns base64 string
-func (a *App) GetImageBase64(path string) string {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return ""
-	}
-	return base64.StdEncoding.EncodeToString(data)
-}
-
 func (a *App) BeforeClose(ctx context.Context) (prevent bool) {
 	if a.Mode == "ball" {
 		// If ball is closing (e.g. Alt+F4), trigger full quit
@@ -304,14 +636,61 @@ func (a *App) BeforeClose(ctx context.Context) (prevent bool) {
 	return true
 }
 
+// notifyUpdate tells both processes, this one included, that the data
+// changed. Each reloads it and refreshes what shows it, see
+// listenForUpdates.
 func (a *App) notifyUpdate() {
-	hEvent, err := platform.OpenEvent(UpdateEventName)
-	if err == nil && hEvent != 0 {
-		platform.SetEvent(hEvent)
+	for _, name := range []string{UpdateEventName, MainUpdateEventName} {
+		hEvent, err := platform.OpenEvent(name)
+		if err != nil || hEvent == 0 {
+			// Process not running yet; it reads fresh data on startup
+			slog.Debug("OpenEvent failed", "event", name, "err", err)
+			continue
+		}
+		if err := platform.SetEvent(hEvent); err != nil {
+			slog.Warn("SetEvent failed", "event", name, "err", err)
+		}
 		platform.CloseHandle(hEvent)
 	}
 }
 
+// listenForUpdates reloads the data each time eventName is signalled,
+// then refreshes this process's views of it: the ball's size, or the
+// tray and icons in the main process, and the frontend.
+func (a *App) listenForUpdates(eventName string) {
+	hEvent, err := platform.CreateEvent(eventName)
+	if err != nil || hEvent == 0 {
+		slog.Error("CreateEvent failed, changes will only show by polling", "event", eventName, "err", err)
+		return
+	}
+	defer platform.CloseHandle(hEvent)
+	for {
+		// Wait for signal
+		if _, err := platform.WaitForSingleObject(hEvent, platform.INFINITE); err != nil {
+			slog.Error("WaitForSingleObject failed", "event", eventName, "err", err)
+			return
+		}
+		// Reload data. Config first: it names the active list
+		if err := a.Store.LoadLists(); err != nil {
+			slog.Warn("reload lists failed", "err", err)
+		}
+		if err := a.Store.LoadConfig(); err != nil {
+			slog.Warn("reload config failed", "err", err)
+		}
+		if err := a.Store.LoadTodos(); err != nil && !errors.Is(err, storage.ErrLocked) {
+			slog.Warn("reload todos failed", "err", err)
+		}
+		if a.Mode == "ball" {
+			a.resizeBall()
+		} else {
+			a.refreshTray()
+			a.updateIcons()
+		}
+		// Emit event to frontend
+		runtime.EventsEmit(a.ctx, "todos_updated")
+	}
+}
+
 // CheckDocking checks if the window is near the edge of the screen
 func (a *App) CheckDocking() string {
 	if a.Mode != "ball" {
@@ -334,22 +713,19 @@ func (a *App) CheckDocking() string {
 		return "none"
 	}
 
-	monitorRect, err := platform.GetMonitorRectForWindow(hwnd)
+	mon, err := platform.GetMonitorInfoForWindow(hwnd)
 	if err != nil {
 		return "none"
 	}
+	work := mon.Work
 
-	// Window width is 100. Ball is 80 (centered, so 10px padding on each side).
-	// User requires at least 1/4 of ball (20px) to be off-screen.
-	// Left side: Ball Left (rect.Left + 10) <= monitorRect.Left - 20
-	// => rect.Left <= monitorRect.Left - 30
-	if rect.Left <= monitorRect.Left-30 {
+	// Dock once at least a quarter of the ball is off the work area.
+	// All lengths are logical pixels, scaled by the monitor DPI.
+	quarter := mon.Scale(a.GetBallGeometry().Width / 4)
+	if int(rect.Left) <= int(work.Left)-quarter {
 		return "left"
 	}
-
-	// Right side: Ball Right (rect.Left + 90) >= monitorRect.Right + 20
-	// => rect.Left >= monitorRect.Right - 70
-	if rect.Left >= monitorRect.Right-70 {
+	if int(rect.Right) >= int(work.Right)+quarter {
 		return "right"
 	}
 
@@ -366,7 +742,7 @@ func (a *App) Dock(side string) {
 		return
 	}
 
-	monitorRect, err := platform.GetMonitorRectForWindow(hwnd)
+	mon, err := platform.GetMonitorInfoForWindow(hwnd)
 	if err != nil {
 		return
 	}
@@ -375,25 +751,24 @@ func (a *App) Dock(side string) {
 		return
 	}
 
-	width := 10
-	height := 100
-	y := int(rect.Top)
-
-	if y < int(monitorRect.Top) {
-		y = int(monitorRect.Top)
-	}
-	if y+height > int(monitorRect.Bottom) {
-		y = int(monitorRect.Bottom) - height
-	}
+	// Dock inside the work area so the strip never ends up behind the taskbar
+	work := mon.Work
+	g := a.GetBallGeometry()
+	a.ballShown = g
+	width := mon.Scale(g.DockedWidth)
+	height := mon.Scale(g.DockedHeight)
+	y := clamp(int(rect.Top), int(work.Top), int(work.Bottom)-height)
 
 	var x int
 	if side == "left" {
-		x = int(monitorRect.Left)
+		x = int(work.Left)
 	} else {
-		x = int(monitorRect.Right) - width
+		x = int(work.Right) - width
 	}
 
-	platform.SetWindowPos(hwnd, x, y, width, height, platform.SWP_NOZORDER)
+	if err := platform.SetWindowPos(hwnd, x, y, width, height, platform.SWP_NOZORDER); err != nil {
+		slog.Warn("SetWindowPos failed", "op", "dock", "side", side, "x", x, "y", y, "err", err)
+	}
 }
 
 func (a *App) startDockingLoop() {
@@ -440,10 +815,17 @@ func (a *App) Undock(side string) {
 		return
 	}
 
+	mon, err := platform.GetMonitorInfoForWindow(hwnd)
+	if err != nil {
+		return
+	}
+
 	// Restore size
-	width := 80
-	height := 80
-	y := int(rect.Top)
+	work := mon.Work
+	g := a.GetBallGeometry()
+	a.ballShown = g
+	width := mon.Scale(g.Width)
+	height := mon.Scale(g.Height)
 
 	var x int
 	if side == "left" {
@@ -451,14 +833,30 @@ func (a *App) Undock(side string) {
 	} else {
 		x = int(rect.Right) - width
 	}
+	x = clamp(x, int(work.Left), int(work.Right)-width)
+	y := clamp(int(rect.Top), int(work.Top), int(work.Bottom)-height)
 
-	platform.SetWindowPos(hwnd, x, y, width, height, platform.SWP_NOZORDER)
+	if err := platform.SetWindowPos(hwnd, x, y, width, height, platform.SWP_NOZORDER); err != nil {
+		slog.Warn("SetWindowPos failed", "op", "undock", "side", side, "x", x, "y", y, "err", err)
+	}
 
 	// Reset state
 	a.currentDockState = "none"
 	runtime.EventsEmit(a.ctx, "dock_state_change", "none")
 }
 
+// resizeBall applies a changed ball size or shape to the window.
+func (a *App) resizeBall() {
+	if a.Mode != "ball" || a.GetBallGeometry() == a.ballShown {
+		return
+	}
+	if a.currentDockState != "none" {
+		a.Dock(a.currentDockState)
+		return
+	}
+	a.SetBallMenuState(a.ballMenuOpen.Load())
+}
+
 // SetWindowSize wrapper
 func (a *App) SetWindowSize(w, h int) {
 	runtime.WindowSetSize(a.ctx, w, h)
@@ -474,39 +872,64 @@ func (a *App) GetMode() string {
 	return a.Mode
 }
 
-// SelectFile opens a file dialog to select an image
-func (a *App) SelectFile() string {
-	selection, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "Select Icon",
-		Filters: []runtime.FileFilter{
-			{DisplayName: "Images", Pattern: "*.png;*.jpg;*.jpeg;*.ico"},
-		},
-	})
-	if err != nil {
-		return ""
-	}
-	return selection
-}
-
 // UpdateConfig updates the app config
 func (a *App) UpdateConfig(config models.AppConfig) error {
-	// If in main mode, apply window size changes immediately
-	if a.Mode == "main" && config.WindowWidth > 0 && config.WindowHeight > 0 {
-		runtime.WindowSetSize(a.ctx, config.WindowWidth, config.WindowHeight)
+	if err := models.ValidateConfig(config); err != nil {
+		return validationError(err.(models.ValidationErrors))
+	}
+	// Icons come from SelectFile, never straight from a path
+	if config.CustomIconPath != "" && !icons.IsName(config.CustomIconPath) {
+		return apperr.Invalid("custom_icon_path", "asset.not_found")
+	}
+
+	// Apply hotkeys first so conflicts are reported before anything is
+	// saved. If a later step fails the old ones are registered again, so
+	// the hotkeys in use always match the saved config.
+	old := a.Store.Config()
+	if err := a.applyHotkeys(config.Hotkeys); err != nil {
+		return err
+	}
+	restoreHotkeys := func() {
+		if err := a.applyHotkeys(old.Hotkeys); err != nil {
+			slog.Error("restore hotkeys failed", "err", err)
+		}
 	}
 
 	// Apply AutoStart setting
+	wasAutoStart := platform.IsAutoStartEnabled()
 	if err := platform.SetAutoStart(config.StartOnBoot); err != nil {
-		fmt.Printf("Error setting auto-start: %v\n", err)
-		return fmt.Errorf("设置开机自启失败: %w", err)
+		slog.Error("SetAutoStart failed", "enable", config.StartOnBoot, "err", err)
+		restoreHotkeys()
+		return apperr.Wrap(apperr.IO, "config.autostart_failed", err)
 	}
 
 	// Wait for save to complete before notifying
-	if err := a.Store.UpdateConfig(config); err == nil {
-		a.notifyUpdate()
-	} else {
-		return fmt.Errorf("保存配置失败: %w", err)
+	if err := a.Store.UpdateConfig(config); err != nil {
+		slog.Error("save config failed", "err", err)
+		restoreHotkeys()
+		if err := platform.SetAutoStart(wasAutoStart); err != nil {
+			slog.Error("restore autostart failed", "enable", wasAutoStart, "err", err)
+		}
+		return storeError("config.save_failed", err)
+	}
+	applog.SetLevel(config.LogLevel)
+
+	// If in main mode, apply window size changes immediately
+	if a.Mode == "main" && config.WindowWidth > 0 && config.WindowHeight > 0 {
+		runtime.WindowSetSize(a.ctx, config.WindowWidth, config.WindowHeight)
 	}
+	a.notifyUpdate()
 
 	return nil
 }
+
+// clamp limits v to [lo, hi]. If the range is empty, lo wins.
+func clamp(v, lo, hi int) int {
+	if v > hi {
+		v = hi
+	}
+	if v < lo {
+		v = lo
+	}
+	return v
+}
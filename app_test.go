@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"todo-ball/apperr"
+	"todo-ball/models"
+	"todo-ball/quickadd"
+	"todo-ball/storage"
+)
+
+// quickAddApp returns an App over an empty data directory whose
+// QuickAdd parser reads the clock as now.
+func quickAddApp(t *testing.T, now time.Time) *App {
+	t.Helper()
+	store, err := storage.NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &App{Store: store, quickAdd: quickadd.Parser{Now: func() time.Time { return now }}}
+}
+
+func TestQuickAdd(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("zone Asia/Shanghai not available: %v", err)
+	}
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, shanghai)
+	a := quickAddApp(t, now)
+
+	item, err := a.QuickAdd("Send report tomorrow 5pm !high #work remind 2d", "Asia/Shanghai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 3, 5, 17, 0, 0, 0, shanghai); !item.DueDate.Equal(want) || item.AllDay {
+		t.Errorf("due = %s all-day %v, want %s", item.DueDate, item.AllDay, want)
+	}
+	if item.Title != "Send report" || item.Priority != models.PriorityHigh || len(item.Tags) != 1 || item.Tags[0] != "work" {
+		t.Errorf("item = %+v", item)
+	}
+	if len(item.Reminders) != 1 || item.Reminders[0] != (models.Reminder{Before: 2, Unit: models.UnitDays}) {
+		t.Errorf("reminders = %+v, want 2 days before", item.Reminders)
+	}
+	if !item.CreatedAt.Equal(now) || !strings.HasPrefix(item.ID, "1772589600000000000-") {
+		t.Errorf("created %s id %q, want the fake clock", item.CreatedAt, item.ID)
+	}
+	if len(a.Store.Todos) != 1 || a.Store.Todos[0].ID != item.ID {
+		t.Errorf("stored %d todos, want the new one", len(a.Store.Todos))
+	}
+
+	// No date: due at the end of today with the default reminder
+	item, err = a.QuickAdd("water plants", "Asia/Shanghai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 3, 5, 0, 0, 0, 0, shanghai); !item.DueDate.Equal(want) || !item.AllDay {
+		t.Errorf("undated due = %s all-day %v, want %s all day", item.DueDate, item.AllDay, want)
+	}
+	if days := a.Store.Config().NotificationDays; days > 0 {
+		if len(item.Reminders) != 1 || item.Reminders[0].Before != days {
+			t.Errorf("undated reminders = %+v, want %d days before", item.Reminders, days)
+		}
+	}
+}
+
+func TestQuickAddErrors(t *testing.T) {
+	a := quickAddApp(t, time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC))
+	tests := []struct {
+		text, zone string
+		key        string
+	}{
+		{"call mom", "Not/AZone", "todo.invalid_time_zone"},
+		{"#work", "", "quickadd.empty_title"},
+		{"party in 99999999 days", "", "quickadd.invalid_date"},
+		{"party in 9223372036 hours", "", "quickadd.invalid_date"},
+		{"party remind 99999999999w", "", "quickadd.invalid_reminder"},
+	}
+	for _, tt := range tests {
+		_, err := a.QuickAdd(tt.text, tt.zone)
+		var e *apperr.Error
+		if !errors.As(err, &e) || e.Code != apperr.Validation || e.Key != tt.key {
+			t.Errorf("QuickAdd(%q) error = %v, want %s", tt.text, err, tt.key)
+		}
+	}
+	if len(a.Store.Todos) != 0 {
+		t.Errorf("rejected lines stored %d todos", len(a.Store.Todos))
+	}
+}
@@ -0,0 +1,166 @@
+// Package apperr defines the error type returned by every App binding.
+// Each error carries a stable code the frontend can switch on and a
+// message looked up in a small catalog so it can be shown to the user.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type Code string
+
+const (
+	Validation Code = "validation" // Bad input from the user or frontend
+	NotFound   Code = "not_found"  // Referenced item does not exist
+	IO         Code = "io"         // Disk, registry or OS call failed
+	Conflict   Code = "conflict"   // Clashes with existing state (duplicate ID, hotkey in use)
+	Locked     Code = "locked"     // Data is encrypted and not unlocked yet
+	Internal   Code = "internal"   // Anything else
+)
+
+// Locale used for Error() and Format. The UI is Chinese by default.
+var Locale = "zh-CN"
+
+type Error struct {
+	Code  Code
+	Key   string // Message catalog key, see messages.go
+	Args  []any  // Arguments for the catalog format string
+	Field string // Offending field for validation errors, if any
+	Err   error  // Underlying cause, reported as detail
+
+	// Per-field errors when several fields failed validation at once
+	Fields []*Error
+}
+
+func New(code Code, key string, args ...any) *Error {
+	return &Error{Code: code, Key: key, Args: args}
+}
+
+func Wrap(code Code, key string, err error, args ...any) *Error {
+	return &Error{Code: code, Key: key, Args: args, Err: err}
+}
+
+// Invalid returns a validation error for a single field.
+func Invalid(field, key string, args ...any) *Error {
+	return &Error{Code: Validation, Key: key, Args: args, Field: field}
+}
+
+// InvalidFields bundles several field errors into one validation error.
+func InvalidFields(cause error, fields ...*Error) *Error {
+	e := &Error{Code: Validation, Key: "validation.failed", Err: cause, Fields: fields}
+	if len(fields) > 0 {
+		e.Field = fields[0].Field
+	}
+	return e
+}
+
+func (e *Error) Error() string {
+	msg := e.Message(Locale)
+	if e.Err != nil {
+		return msg + ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Message returns the user-facing text in the given locale,
+// falling back to Chinese and then to the key itself.
+func (e *Error) Message(locale string) string {
+	texts, ok := messages[e.Key]
+	if !ok {
+		return e.Key
+	}
+	format, ok := texts[locale]
+	if !ok {
+		format = texts["zh-CN"]
+	}
+
+	args := e.Args
+	switch {
+	case len(e.Fields) > 0:
+		// Summary of all field messages
+		parts := make([]string, len(e.Fields))
+		for i, f := range e.Fields {
+			parts[i] = f.Message(locale)
+		}
+		args = []any{strings.Join(parts, separator(locale))}
+	case strings.HasPrefix(e.Key, "validation.") && e.Field != "":
+		// Field rules are phrased around the field's display name
+		args = append([]any{Label(e.Field, locale)}, args...)
+	}
+
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Label returns the display name of a JSON field, or the field itself.
+func Label(field, locale string) string {
+	texts, ok := fieldLabels[field]
+	if !ok {
+		return field
+	}
+	if label, ok := texts[locale]; ok {
+		return label
+	}
+	return texts["zh-CN"]
+}
+
+func separator(locale string) string {
+	if locale == "zh-CN" {
+		return "；"
+	}
+	return "; "
+}
+
+// CodeOf returns the code of err, or Internal if err is not an *Error.
+func CodeOf(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return Internal
+}
+
+// Payload is what the frontend receives when a binding rejects.
+type Payload struct {
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+	Detail  string `json:"detail,omitempty"`
+
+	// Field name to message, for highlighting several invalid inputs
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// Format converts a binding error into a Payload.
+// Plug it into options.App.ErrorFormatter.
+func Format(err error) any {
+	var e *Error
+	if !errors.As(err, &e) {
+		e = Wrap(Internal, "internal", err)
+	}
+	p := Payload{
+		Code:    e.Code,
+		Message: e.Message(Locale),
+		Field:   e.Field,
+	}
+	if e.Err != nil {
+		p.Detail = e.Err.Error()
+	}
+	if len(e.Fields) > 0 {
+		p.Fields = map[string]string{}
+		for _, f := range e.Fields {
+			p.Fields[f.Field] = f.Message(Locale)
+		}
+	} else if e.Code == Validation && e.Field != "" {
+		p.Fields = map[string]string{e.Field: p.Message}
+	}
+	return p
+}
@@ -0,0 +1,317 @@
+package apperr
+
+// messages maps catalog keys to format strings per locale
+var messages = map[string]map[string]string{
+	"internal": {
+		"zh-CN": "内部错误",
+		"en":    "internal error",
+	},
+	"storage.init_failed": {
+		"zh-CN": "无法打开数据目录",
+		"en":    "cannot open data directory",
+	},
+	"todo.not_found": {
+		"zh-CN": "任务不存在或已被删除",
+		"en":    "todo not found",
+	},
+	"todo.duplicate_id": {
+		"zh-CN": "任务已存在",
+		"en":    "todo already exists",
+	},
+	"todo.save_failed": {
+		"zh-CN": "保存任务失败",
+		"en":    "failed to save todos",
+	},
+	"todo.invalid_due_date": {
+		"zh-CN": "截止时间格式无效: %s",
+		"en":    "invalid due date: %s",
+	},
+	"todo.invalid_time_zone": {
+		"zh-CN": "未知的时区: %s",
+		"en":    "unknown time zone: %s",
+	},
+	"list.not_found": {
+		"zh-CN": "清单不存在",
+		"en":    "list not found",
+	},
+	"list.duplicate": {
+		"zh-CN": "已存在同名清单",
+		"en":    "a list with this name already exists",
+	},
+	"list.delete_default": {
+		"zh-CN": "默认清单不能删除",
+		"en":    "the default list cannot be deleted",
+	},
+	"list.save_failed": {
+		"zh-CN": "保存清单失败",
+		"en":    "failed to save lists",
+	},
+	"store.locked": {
+		"zh-CN": "数据已加密，请先输入密码解锁",
+		"en":    "data is encrypted, enter the passphrase to unlock",
+	},
+	"encryption.wrong_passphrase": {
+		"zh-CN": "密码错误",
+		"en":    "wrong passphrase",
+	},
+	"encryption.too_short": {
+		"zh-CN": "密码至少需要 %d 个字符",
+		"en":    "passphrase must be at least %d characters",
+	},
+	"encryption.already_enabled": {
+		"zh-CN": "数据已经加密",
+		"en":    "encryption is already enabled",
+	},
+	"encryption.not_enabled": {
+		"zh-CN": "数据未加密",
+		"en":    "encryption is not enabled",
+	},
+	"encryption.failed": {
+		"zh-CN": "更新加密设置失败",
+		"en":    "failed to update encryption",
+	},
+	"encryption.ball_running": {
+		"zh-CN": "悬浮球未能退出，请关闭悬浮球后重试",
+		"en":    "the floating ball did not exit, close it and try again",
+	},
+	"archive.invalid_date": {
+		"zh-CN": "日期格式无效: %s",
+		"en":    "invalid date: %s",
+	},
+	"stats.invalid_range": {
+		"zh-CN": "统计范围无效: %s",
+		"en":    "invalid stats range: %s",
+	},
+	"report.invalid_week": {
+		"zh-CN": "周格式无效: %s (应为 2024-W09)",
+		"en":    "invalid week: %s (expected 2024-W09)",
+	},
+	"report.invalid_format": {
+		"zh-CN": "报告格式无效: %s",
+		"en":    "invalid report format: %s",
+	},
+	"focus.running": {
+		"zh-CN": "已有正在进行的专注计时",
+		"en":    "a focus timer is already running",
+	},
+	"focus.not_running": {
+		"zh-CN": "当前没有专注计时",
+		"en":    "no focus timer is running",
+	},
+	"focus.paused": {
+		"zh-CN": "专注计时已暂停",
+		"en":    "the focus timer is paused",
+	},
+	"focus.not_paused": {
+		"zh-CN": "专注计时没有暂停",
+		"en":    "the focus timer is not paused",
+	},
+	"focus.save_failed": {
+		"zh-CN": "保存专注记录失败",
+		"en":    "failed to save focus sessions",
+	},
+	"tracking.not_running": {
+		"zh-CN": "当前没有在计时",
+		"en":    "time tracking is not running",
+	},
+	"tracking.save_failed": {
+		"zh-CN": "保存工时记录失败",
+		"en":    "failed to save time entries",
+	},
+	"report.save_failed": {
+		"zh-CN": "保存报告失败",
+		"en":    "failed to save report",
+	},
+	"theme.not_found": {
+		"zh-CN": "主题不存在",
+		"en":    "theme not found",
+	},
+	"theme.builtin": {
+		"zh-CN": "内置主题不能修改或删除，请换一个名称",
+		"en":    "built-in themes cannot be changed or deleted, use another name",
+	},
+	"theme.too_many": {
+		"zh-CN": "最多只能保存 %v 个自定义主题",
+		"en":    "at most %v custom themes can be saved",
+	},
+	"theme.invalid_file": {
+		"zh-CN": "不是有效的主题文件",
+		"en":    "not a valid theme file",
+	},
+	"theme.load_failed": {
+		"zh-CN": "读取主题失败",
+		"en":    "failed to load themes",
+	},
+	"theme.save_failed": {
+		"zh-CN": "保存主题失败",
+		"en":    "failed to save theme",
+	},
+	"dnd.invalid_duration": {
+		"zh-CN": "免打扰时长须在 0 到 %d 小时之间",
+		"en":    "do-not-disturb must last between 0 and %d hours",
+	},
+	"dnd.save_failed": {
+		"zh-CN": "保存免打扰状态失败",
+		"en":    "failed to save do-not-disturb",
+	},
+	"config.load_failed": {
+		"zh-CN": "读取配置失败",
+		"en":    "failed to load settings",
+	},
+	"config.save_failed": {
+		"zh-CN": "保存配置失败",
+		"en":    "failed to save settings",
+	},
+	"config.autostart_failed": {
+		"zh-CN": "设置开机自启失败",
+		"en":    "failed to update start on boot",
+	},
+	"hotkey.invalid": {
+		"zh-CN": "快捷键 %s 无效",
+		"en":    "invalid hotkey for %s",
+	},
+	"hotkey.duplicate": {
+		"zh-CN": "快捷键冲突: %s 同时用于 %s 和 %s",
+		"en":    "hotkey %s is assigned to both %s and %s",
+	},
+	"hotkey.in_use": {
+		"zh-CN": "快捷键 %s (%s) 已被其他程序占用",
+		"en":    "hotkey %s (%s) is used by another application",
+	},
+	"hotkey.register_failed": {
+		"zh-CN": "注册快捷键 %s (%s) 失败",
+		"en":    "failed to register hotkey %s (%s)",
+	},
+	"hotkey.restore_failed": {
+		"zh-CN": "注册快捷键 %s (%s) 失败，且原有的快捷键未能恢复: %s",
+		"en":    "failed to register hotkey %s (%s), and could not restore the previous hotkeys for: %s",
+	},
+	"quickadd.empty_title": {
+		"zh-CN": "请输入任务内容",
+		"en":    "title is empty",
+	},
+	"quickadd.invalid_priority": {
+		"zh-CN": "无法识别的优先级: %s",
+		"en":    "unknown priority: %s",
+	},
+	"quickadd.invalid_date": {
+		"zh-CN": "无法识别的日期: %s",
+		"en":    "invalid date: %s",
+	},
+	"quickadd.invalid_time": {
+		"zh-CN": "无法识别的时间: %s",
+		"en":    "invalid time: %s",
+	},
+	"quickadd.invalid_reminder": {
+		"zh-CN": "无法识别的提醒设置: %s",
+		"en":    "invalid reminder: %s",
+	},
+	"quickadd.conflicting_dates": {
+		"zh-CN": "包含多个日期或时间: %s",
+		"en":    "more than one date or time: %s",
+	},
+	"validation.failed": {
+		"zh-CN": "输入无效: %s",
+		"en":    "invalid input: %s",
+	},
+	"validation.required": {
+		"zh-CN": "%s不能为空",
+		"en":    "%s is required",
+	},
+	"validation.range": {
+		"zh-CN": "%s必须在 %v 到 %v 之间",
+		"en":    "%s must be between %v and %v",
+	},
+	"validation.length": {
+		"zh-CN": "%s不能超过 %v 个字符",
+		"en":    "%s must be at most %v characters",
+	},
+	"validation.format": {
+		"zh-CN": "%s格式无效 (应为 %v)",
+		"en":    "%s has an invalid format (expected %v)",
+	},
+	"validation.not_after": {
+		"zh-CN": "%s不能晚于 %v",
+		"en":    "%s must not be after %v",
+	},
+	"validation.one_of": {
+		"zh-CN": "%s必须是 %v 之一",
+		"en":    "%s must be one of %v",
+	},
+	"file.read_failed": {
+		"zh-CN": "读取文件失败",
+		"en":    "failed to read file",
+	},
+	"dialog.failed": {
+		"zh-CN": "打开文件对话框失败",
+		"en":    "failed to open file dialog",
+	},
+	"asset.unsupported": {
+		"zh-CN": "不支持的图片格式，请选择 PNG、JPEG、GIF 或 ICO 图片",
+		"en":    "unsupported image, choose a PNG, JPEG, GIF or ICO file",
+	},
+	"asset.too_large": {
+		"zh-CN": "图片文件不能超过 %v MB",
+		"en":    "image file must be at most %v MB",
+	},
+	"asset.too_large_pixels": {
+		"zh-CN": "图片宽高不能超过 %v 像素",
+		"en":    "image must be at most %v pixels wide and high",
+	},
+	"asset.not_found": {
+		"zh-CN": "图片不存在，请重新选择",
+		"en":    "image not found, choose it again",
+	},
+	"asset.save_failed": {
+		"zh-CN": "保存图片失败",
+		"en":    "failed to save image",
+	},
+}
+
+// fieldLabels maps JSON field names to display names per locale
+var fieldLabels = map[string]map[string]string{
+	"id":                  {"zh-CN": "ID", "en": "ID"},
+	"title":               {"zh-CN": "任务内容", "en": "Title"},
+	"due_date":            {"zh-CN": "截止时间", "en": "Due date"},
+	"time_zone":           {"zh-CN": "时区", "en": "Time zone"},
+	"defer_until":         {"zh-CN": "开始日期", "en": "Start date"},
+	"filter":              {"zh-CN": "筛选", "en": "Filter"},
+	"reminder_days":       {"zh-CN": "提醒天数", "en": "Reminder days"},
+	"reminders":           {"zh-CN": "提醒", "en": "Reminders"},
+	"priority":            {"zh-CN": "优先级", "en": "Priority"},
+	"tags":                {"zh-CN": "标签", "en": "Tags"},
+	"theme_color":         {"zh-CN": "主题颜色", "en": "Theme colour"},
+	"floating_opacity":    {"zh-CN": "悬浮球透明度", "en": "Ball opacity"},
+	"edge_light_color":    {"zh-CN": "悬浮球颜色", "en": "Ball colour"},
+	"reminder_color":      {"zh-CN": "提醒颜色", "en": "Reminder colour"},
+	"notification_days":   {"zh-CN": "默认提醒天数", "en": "Default reminder days"},
+	"floating_ball_mode":  {"zh-CN": "悬浮球模式", "en": "Ball mode"},
+	"window_width":        {"zh-CN": "窗口宽度", "en": "Window width"},
+	"window_height":       {"zh-CN": "窗口高度", "en": "Window height"},
+	"hotkeys":             {"zh-CN": "快捷键", "en": "Hotkeys"},
+	"log_level":           {"zh-CN": "日志级别", "en": "Log level"},
+	"ball_count_mode":     {"zh-CN": "悬浮球计数方式", "en": "Ball count mode"},
+	"archive_after_days":  {"zh-CN": "自动归档天数", "en": "Archive after days"},
+	"from":                {"zh-CN": "开始日期", "en": "From"},
+	"to":                  {"zh-CN": "结束日期", "en": "To"},
+	"range":               {"zh-CN": "统计范围", "en": "Range"},
+	"focus_work_minutes":  {"zh-CN": "专注时长", "en": "Focus length"},
+	"focus_break_minutes": {"zh-CN": "休息时长", "en": "Break length"},
+	"idle_minutes":        {"zh-CN": "空闲暂停时间", "en": "Idle minutes"},
+	"week":                {"zh-CN": "周", "en": "Week"},
+	"format":              {"zh-CN": "格式", "en": "Format"},
+	"name":                {"zh-CN": "名称", "en": "Name"},
+	"color":               {"zh-CN": "颜色", "en": "Colour"},
+	"text":                {"zh-CN": "输入内容", "en": "Input"},
+	"custom_icon_path":    {"zh-CN": "自定义图标", "en": "Custom icon"},
+	"color_mode":          {"zh-CN": "外观模式", "en": "Colour mode"},
+	"theme_name":          {"zh-CN": "主题名称", "en": "Theme name"},
+	"ball_size":           {"zh-CN": "悬浮球大小", "en": "Ball size"},
+	"ball_shape":          {"zh-CN": "悬浮球形状", "en": "Ball shape"},
+	"ball_content":        {"zh-CN": "悬浮球显示内容", "en": "Ball content"},
+	"fade_after_seconds":  {"zh-CN": "自动淡出时间", "en": "Fade after"},
+	"fade_opacity":        {"zh-CN": "淡出后透明度", "en": "Faded opacity"},
+	"busy_ball_action":    {"zh-CN": "忙碌时悬浮球", "en": "Ball while busy"},
+	"quiet_hours":         {"zh-CN": "免打扰时段", "en": "Quiet hours"},
+	"minutes":             {"zh-CN": "时长", "en": "Minutes"},
+}
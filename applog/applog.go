@@ -0,0 +1,60 @@
+// Package applog sets up the process-wide slog logger.
+// Both the main and ball processes append to the same rotating file in the
+// app data directory; every entry carries the process mode.
+package applog
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	DirName    = "logs"
+	FileName   = "todo-ball.log"
+	MaxSize    = 1 << 20 // Rotate after 1 MiB
+	MaxBackups = 3
+)
+
+var level = new(slog.LevelVar) // Info by default
+
+// Setup installs a default slog logger writing to dir/logs/todo-ball.log.
+// If the file can't be opened, logs go to stderr and the error is returned.
+func Setup(dir, mode string) (io.Closer, error) {
+	var w io.Writer = os.Stderr
+	var closer io.Closer = io.NopCloser(nil)
+
+	rf, err := openRotating(filepath.Join(dir, DirName, FileName), MaxSize, MaxBackups)
+	if err == nil {
+		w = rf
+		closer = rf
+	}
+
+	handler := slog.NewTextHandler(w, &slog.HandlerOptions{Level: level})
+	slog.SetDefault(slog.New(handler).With("mode", mode))
+	return closer, err
+}
+
+// SetLevel changes the level at runtime. Unknown names are ignored.
+func SetLevel(name string) {
+	if l, ok := ParseLevel(name); ok {
+		level.Set(l)
+	}
+}
+
+// ParseLevel maps "debug", "info", "warn" and "error" to slog levels.
+func ParseLevel(name string) (slog.Level, bool) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, true
+	case "info":
+		return slog.LevelInfo, true
+	case "warn", "warning":
+		return slog.LevelWarn, true
+	case "error":
+		return slog.LevelError, true
+	}
+	return slog.LevelInfo, false
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+	"todo-ball/apperr"
+	"todo-ball/models"
+)
+
+const (
+	archiveInterval    = time.Hour
+	defaultArchivePage = 20
+	maxArchivePageSize = 200
+	archiveDateLayout  = "2006-01-02"
+)
+
+// GetArchive searches completed todos, archived or still in their list.
+// from and to are dates ("2006-01-02", both inclusive) or RFC 3339 times;
+// empty leaves that end open. query matches title and tags. page is 1-based.
+func (a *App) GetArchive(from string, to string, query string, page int, pageSize int) (models.ArchivePage, error) {
+	start, _, err := parseArchiveTime("from", from)
+	if err != nil {
+		return models.ArchivePage{}, err
+	}
+	end, dateOnly, err := parseArchiveTime("to", to)
+	if err != nil {
+		return models.ArchivePage{}, err
+	}
+	if dateOnly {
+		// Include the whole last day
+		end = end.AddDate(0, 0, 1)
+	}
+
+	entries, err := a.Store.SearchArchive(start, end, query)
+	if err != nil {
+		return models.ArchivePage{}, storeError("file.read_failed", err)
+	}
+
+	if pageSize <= 0 {
+		pageSize = defaultArchivePage
+	}
+	pageSize = min(pageSize, maxArchivePageSize)
+	page = max(page, 1)
+	result := models.ArchivePage{Entries: []models.ArchiveEntry{}, Total: len(entries), Page: page, PageSize: pageSize}
+	if offset := (page - 1) * pageSize; offset < len(entries) {
+		result.Entries = entries[offset:min(offset+pageSize, len(entries))]
+	}
+	return result, nil
+}
+
+// parseArchiveTime reports dateOnly for "2006-01-02" input, read in local time.
+func parseArchiveTime(field, s string) (t time.Time, dateOnly bool, err error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false, nil
+	}
+	if t, err := time.ParseInLocation(archiveDateLayout, s, time.Local); err == nil {
+		return t, true, nil
+	}
+	t, err = time.Parse(time.RFC3339, s)
+	if err != nil {
+		e := apperr.Invalid(field, "archive.invalid_date", s)
+		e.Err = err
+		return time.Time{}, false, e
+	}
+	return t, false, nil
+}
+
+// startArchiver moves old completed todos to the archive now and then
+// every hour. Only the main process archives.
+func (a *App) startArchiver() {
+	a.archiveCompleted()
+	go func() {
+		ticker := time.NewTicker(archiveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-a.ctx.Done():
+				return
+			}
+			a.archiveCompleted()
+		}
+	}()
+}
+
+func (a *App) archiveCompleted() {
+	days := a.Store.Config().ArchiveAfterDays
+	if days <= 0 || a.Store.IsLocked() {
+		return
+	}
+	moved, err := a.Store.ArchiveCompleted(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		slog.Error("archive completed todos failed", "err", err)
+	}
+	if moved > 0 {
+		slog.Info("archived completed todos", "count", moved, "after_days", days)
+		a.notifyUpdate()
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"todo-ball/apperr"
+	"todo-ball/icons"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// SelectFile lets the user pick the custom ball icon and imports it into
+// the assets directory. It returns the asset name to store in
+// custom_icon_path, or "" if the dialog was cancelled.
+func (a *App) SelectFile() (string, error) {
+	selection, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "选择图标",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "图片", Pattern: "*.png;*.jpg;*.jpeg;*.gif;*.ico"},
+		},
+	})
+	if err != nil {
+		return "", apperr.Wrap(apperr.IO, "dialog.failed", err)
+	}
+	if selection == "" {
+		return "", nil
+	}
+	name, err := a.assets.ImportFile(selection, ballIconPixels)
+	if err != nil {
+		slog.Warn("import icon failed", "path", selection, "err", err)
+		return "", assetError(err)
+	}
+	slog.Info("icon imported", "path", selection, "asset", name)
+	return name, nil
+}
+
+// GetImageBase64 returns an imported image by its asset name. Other
+// names, including file paths, are refused.
+func (a *App) GetImageBase64(name string) (icons.Asset, error) {
+	asset, err := a.assets.Get(name)
+	if err != nil {
+		return icons.Asset{}, assetError(err)
+	}
+	return asset, nil
+}
+
+func assetError(err error) error {
+	switch {
+	case errors.Is(err, icons.ErrUnsupported):
+		return apperr.Wrap(apperr.Validation, "asset.unsupported", err)
+	case errors.Is(err, icons.ErrTooLarge):
+		return apperr.Wrap(apperr.Validation, "asset.too_large", err, icons.MaxImportSize>>20)
+	case errors.Is(err, icons.ErrTooManyPixels):
+		return apperr.Wrap(apperr.Validation, "asset.too_large_pixels", err, icons.MaxPixels)
+	case errors.Is(err, icons.ErrNotFound), errors.Is(err, os.ErrNotExist):
+		return apperr.Wrap(apperr.NotFound, "asset.not_found", err)
+	}
+	return apperr.Wrap(apperr.IO, "asset.save_failed", err)
+}
+
+// migrateCustomIcon imports a custom icon that config still names by
+// file path. If the file is gone or unreadable the icon is dropped.
+func (a *App) migrateCustomIcon() {
+	cfg := a.Store.Config()
+	path := cfg.CustomIconPath
+	if path == "" || icons.IsName(path) {
+		return
+	}
+	name, err := a.assets.ImportFile(path, ballIconPixels)
+	if err != nil {
+		slog.Warn("cannot import old custom icon, dropping it", "path", path, "err", err)
+	}
+	cfg.CustomIconPath = name
+	if err := a.Store.UpdateConfig(cfg); err != nil {
+		slog.Error("save migrated custom icon failed", "err", err)
+		return
+	}
+	if name != "" {
+		slog.Info("custom icon moved to assets", "path", path, "asset", name)
+	}
+	a.notifyUpdate()
+}
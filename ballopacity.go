@@ -0,0 +1,86 @@
+package main
+
+import (
+	"log/slog"
+	"math"
+	"time"
+	"todo-ball/platform"
+)
+
+// How often the ball checks the cursor, the foreground app and the config
+const ballLookInterval = 250 * time.Millisecond
+
+// ballLook is the native opacity and click-through of the ball window.
+type ballLook struct {
+	alpha        byte
+	clickThrough bool
+}
+
+// startBallLook keeps the ball window's opacity and click-through in step
+// with the config, the cursor and the foreground app. Ball process only.
+func (a *App) startBallLook(hwnd uintptr) {
+	ticker := time.NewTicker(ballLookInterval)
+	go func() {
+		defer ticker.Stop()
+		lastHover := time.Now()
+		var applied *ballLook
+		for {
+			now := time.Now()
+			if a.ballMenuOpen.Load() || cursorOn(hwnd) {
+				lastHover = now
+			}
+			look := a.ballLookAt(now.Sub(lastHover))
+			if applied == nil || look != *applied {
+				if err := platform.SetWindowAlpha(hwnd, look.alpha); err != nil {
+					slog.Warn("SetLayeredWindowAttributes failed", "alpha", look.alpha, "err", err)
+				}
+				platform.SetClickThrough(hwnd, look.clickThrough)
+				if applied == nil || look.clickThrough != applied.clickThrough {
+					slog.Info("ball click-through changed", "on", look.clickThrough)
+				}
+				applied = &look
+			}
+
+			select {
+			case <-ticker.C:
+			case <-a.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// ballLookAt is the look for a cursor that left the ball idle ago.
+func (a *App) ballLookAt(idle time.Duration) ballLook {
+	cfg := a.Store.Config()
+	opacity := cfg.FloatingOpacity
+	if cfg.FadeAfterSeconds > 0 && idle >= time.Duration(cfg.FadeAfterSeconds)*time.Second {
+		// Fading never makes the ball more opaque
+		opacity = min(opacity, cfg.FadeOpacity)
+	}
+	return ballLook{
+		alpha:        byte(math.Round(opacity * 255)),
+		clickThrough: cfg.ClickThrough || (cfg.ClickThroughFullscreen && platform.ForegroundIsFullscreen()),
+	}
+}
+
+// cursorOn reports whether the mouse cursor is over the window.
+func cursorOn(hwnd uintptr) bool {
+	rect := platform.GetWindowRect(hwnd)
+	if rect == nil {
+		return false
+	}
+	x, y := platform.GetCursorPos()
+	return x >= int(rect.Left) && x < int(rect.Right) && y >= int(rect.Top) && y < int(rect.Bottom)
+}
+
+// toggleClickThrough turns click-through of the ball on or off. The ball
+// picks the change up from the config. Main process only.
+func (a *App) toggleClickThrough() {
+	on := !a.Store.Config().ClickThrough
+	if err := a.Store.SetClickThrough(on); err != nil {
+		slog.Error("save click-through failed", "err", err)
+		return
+	}
+	a.notifyUpdate()
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+	"todo-ball/models"
+	"todo-ball/platform"
+	"todo-ball/remind"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// How often the ball checks whether the user is busy and reminders are due
+const busyCheckInterval = time.Second
+
+// userBusy reports whether the user shouldn't be interrupted, and why.
+func userBusy(cfg models.AppConfig) (bool, string) {
+	if !cfg.BusyFullscreen && !cfg.BusyPresentation {
+		return false, ""
+	}
+	// On error the state reads as accepting notifications
+	state, _ := platform.UserNotificationState()
+	if cfg.BusyPresentation && state == platform.QUNS_PRESENTATION_MODE {
+		return true, "presentation"
+	}
+	if cfg.BusyFullscreen {
+		// The shell misses borderless fullscreen windows, so look as well
+		if state == platform.QUNS_BUSY || state == platform.QUNS_RUNNING_D3D_FULL_SCREEN || platform.ForegroundIsFullscreen() {
+			return true, "fullscreen"
+		}
+	}
+	return false, ""
+}
+
+// startBusyWatcher moves the ball out of the way while the user is busy
+// and fires reminders, holding them back until the user is free if so
+// configured, and dropping them while quiet. Ball process only.
+func (a *App) startBusyWatcher(hwnd uintptr) {
+	ticker := time.NewTicker(busyCheckInterval)
+	go func() {
+		defer ticker.Stop()
+		busy := false
+		// What the watcher did to the window, undone once the user is free
+		hidden, lowered := false, false
+		for {
+			select {
+			case <-ticker.C:
+			case <-a.ctx.Done():
+				return
+			}
+
+			// One copy per check, taken under the store's lock
+			cfg := a.Store.Config()
+			now, reason := userBusy(cfg)
+			if now != busy {
+				slog.Info("user busy changed", "busy", now, "reason", reason, "queued", a.reminders.Queued())
+				busy = now
+			}
+			action := cfg.BusyBallAction
+			if !busy {
+				action = models.BusyActionNone
+			}
+
+			switch {
+			case action == models.BusyActionHide && !hidden && platform.IsWindowVisible(hwnd):
+				platform.HideWindow(hwnd)
+				hidden = true
+			case action != models.BusyActionHide && hidden:
+				platform.ShowNoActivate(hwnd)
+				hidden = false
+			}
+			switch {
+			case action == models.BusyActionLower && !lowered:
+				platform.SetNotTopMost(hwnd)
+				lowered = true
+			case action != models.BusyActionLower && lowered:
+				platform.SetTopMost(hwnd)
+				lowered = false
+			}
+
+			mode := remind.Deliver
+			switch {
+			case a.quietStatusFor(cfg, time.Now()).Active:
+				mode = remind.Suppress
+			case busy && cfg.QueueReminders:
+				mode = remind.Hold
+			}
+			a.checkReminders(mode)
+		}
+	}()
+}
+
+// checkReminders sends reminders that fired to the ball, or holds them
+// back as mode says.
+func (a *App) checkReminders(mode remind.Mode) {
+	now := time.Now()
+	// Deferred todos remind once they start
+	available, err := a.Store.Available(now)
+	if err != nil {
+		// Locked: nothing to remind of until the data is unlocked
+		return
+	}
+	if fired := a.reminders.Check(available, now, mode); len(fired) > 0 {
+		slog.Info("reminders due", "count", len(fired))
+		runtime.EventsEmit(a.ctx, "reminders", fired)
+	}
+}
@@ -0,0 +1,187 @@
+// Command todo-store maintains the todo data directory while the app is
+// closed.
+//
+//	todo-store migrate -to bolt   move todos into the embedded database
+//	todo-store migrate -to json   move them back to JSON files
+//	todo-store check              run the backend conformance checks
+//	todo-store stats -range month print productivity figures as JSON
+//	todo-store report -week 2024-W09 -format html
+//	                              print a weekly report
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"todo-ball/stats"
+	"todo-ball/storage"
+	"todo-ball/storage/storetest"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: todo-store migrate [-data-dir dir] -to json|bolt")
+	fmt.Fprintln(os.Stderr, "       todo-store check")
+	fmt.Fprintln(os.Stderr, "       todo-store stats [-data-dir dir] [-range week|last_week|month|last_month|year|all|<n>d]")
+	fmt.Fprintln(os.Stderr, "       todo-store report [-data-dir dir] [-week YYYY-Www] [-format markdown|html]")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	var err error
+	switch os.Args[1] {
+	case "migrate":
+		err = migrate(os.Args[2:])
+	case "check":
+		err = check()
+	case "stats":
+		err = printStats(os.Args[2:])
+	case "report":
+		err = printReport(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "todo-store:", err)
+		os.Exit(1)
+	}
+}
+
+func migrate(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dataDir := fs.String("data-dir", "", "Data directory (default: same as the app)")
+	to := fs.String("to", "", "Target backend: json or bolt")
+	fs.Parse(args)
+	if *to == "" {
+		usage()
+	}
+
+	s, dir, err := openStorage(*dataDir)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	from := s.Backend()
+	if err := s.MigrateTo(*to); err != nil {
+		return err
+	}
+	fmt.Printf("migrated %d lists in %s from %s to %s\n", len(s.GetLists()), dir, from, *to)
+	return nil
+}
+
+// openStorage opens the data directory, asking for the passphrase on
+// stdin if it is encrypted.
+func openStorage(dataDir string) (*storage.Storage, string, error) {
+	dir, _, err := storage.ResolveDataDir(dataDir)
+	if err != nil {
+		return nil, "", err
+	}
+	s, err := storage.NewStorage(dir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if s.IsLocked() {
+		fmt.Fprint(os.Stderr, "Passphrase: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err == nil {
+			err = s.Unlock(strings.TrimRight(line, "\r\n"))
+		}
+		if err != nil {
+			s.Close()
+			return nil, "", err
+		}
+	}
+	return s, dir, nil
+}
+
+func printStats(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	dataDir := fs.String("data-dir", "", "Data directory (default: same as the app)")
+	rangeName := fs.String("range", stats.RangeWeek, "week, last_week, month, last_month, year, all or <n>d")
+	fs.Parse(args)
+
+	now := time.Now()
+	r, err := stats.ParseRange(*rangeName, now)
+	if err != nil {
+		return err
+	}
+	s, _, err := openStorage(*dataDir)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	todos, err := s.AllTodos()
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(stats.Compute(todos, r, now))
+}
+
+func printReport(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	dataDir := fs.String("data-dir", "", "Data directory (default: same as the app)")
+	week := fs.String("week", "", "ISO week, e.g. 2024-W09 (default: this week)")
+	format := fs.String("format", stats.FormatMarkdown, "markdown or html")
+	fs.Parse(args)
+	if !stats.ValidFormat(*format) {
+		usage()
+	}
+
+	now := time.Now()
+	start := stats.WeekStart(now)
+	if *week != "" {
+		var err error
+		if start, err = stats.ParseWeek(*week, time.Local); err != nil {
+			return err
+		}
+	}
+	s, _, err := openStorage(*dataDir)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	todos, err := s.AllTodos()
+	if err != nil {
+		return err
+	}
+	return stats.Render(os.Stdout, stats.Weekly(todos, start, now), *format)
+}
+
+// check runs storetest against both built-in backends in temp dirs.
+func check() error {
+	var dirs []string
+	defer func() {
+		for _, dir := range dirs {
+			os.RemoveAll(dir)
+		}
+	}()
+
+	backends := []string{storage.BackendJSON, storage.BackendBolt}
+	for _, kind := range backends {
+		err := storetest.TestStore(func(codec storage.Codec) (storage.Store, error) {
+			dir, err := os.MkdirTemp("", "todo-store-check-")
+			if err != nil {
+				return nil, err
+			}
+			dirs = append(dirs, dir)
+			return storage.OpenStore(kind, dir, codec)
+		})
+		if err != nil {
+			return fmt.Errorf("%s backend: %w", kind, err)
+		}
+		fmt.Printf("%s backend: ok\n", kind)
+	}
+	return nil
+}
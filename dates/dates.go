@@ -0,0 +1,85 @@
+// Package dates does calendar arithmetic on due dates. Days are counted
+// on the wall clock of a time zone, so "tomorrow at 09:00" stays 09:00
+// across a daylight saving change, where adding 24 hours would not.
+package dates
+
+import (
+	"sync"
+	"time"
+	_ "time/tzdata" // Windows has no zoneinfo database of its own
+)
+
+var (
+	zonesMu sync.Mutex
+	zones   = map[string]*time.Location{}
+)
+
+// Zone returns the IANA time zone called name. "" and unknown names give
+// the computer's zone.
+func Zone(name string) *time.Location {
+	if name == "" {
+		return time.Local
+	}
+	zonesMu.Lock()
+	defer zonesMu.Unlock()
+	if loc, ok := zones[name]; ok {
+		return loc
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		loc = time.Local
+	}
+	zones[name] = loc
+	return loc
+}
+
+// ValidZone reports whether name is "" or a known IANA time zone.
+func ValidZone(name string) bool {
+	if name == "" {
+		return true
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// At returns hour:minute on the calendar day of t in loc.
+func At(t time.Time, hour, minute int, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, hour, minute, 0, 0, loc)
+}
+
+// StartOfDay returns midnight at the start of t's day in loc.
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	return At(t, 0, 0, loc)
+}
+
+// EndOfDay returns the midnight that ends t's day in loc, the due time
+// of an all-day todo. The day may be 23 or 25 hours long.
+func EndOfDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+}
+
+// AddDays moves t by n calendar days in loc, keeping its wall-clock time.
+func AddDays(t time.Time, n int, loc *time.Location) time.Time {
+	t = t.In(loc)
+	y, m, d := t.Date()
+	return time.Date(y, m, d+n, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// Add moves t by d. Whole days are calendar days in loc, see AddDays;
+// anything else is elapsed time.
+func Add(t time.Time, d time.Duration, loc *time.Location) time.Time {
+	const day = 24 * time.Hour
+	if d != 0 && d%day == 0 {
+		return AddDays(t, int(d/day), loc)
+	}
+	return t.Add(d)
+}
+
+// SameDay reports whether a and b fall on the same calendar day in loc.
+func SameDay(a, b time.Time, loc *time.Location) bool {
+	ay, am, ad := a.In(loc).Date()
+	by, bm, bd := b.In(loc).Date()
+	return ay == by && am == bm && ad == bd
+}
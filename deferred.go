@@ -0,0 +1,84 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+	"todo-ball/apperr"
+	"todo-ball/models"
+)
+
+// How often the main process looks for deferred todos that have started
+const deferCheckInterval = time.Minute
+
+// QueryTodos returns the todos of the active list matching filter:
+// "all", "available" (pending and started) or "scheduled" (pending with a
+// start date still to come, soonest first).
+func (a *App) QueryTodos(filter string) ([]models.TodoItem, error) {
+	if !models.ValidFilter(filter) {
+		return nil, apperr.Invalid("filter", "validation.one_of", models.FilterAll+"/"+models.FilterAvailable+"/"+models.FilterScheduled)
+	}
+	if a.Store.IsLocked() {
+		return nil, apperr.New(apperr.Locked, "store.locked")
+	}
+	todos, err := a.Store.Query(filter, time.Now())
+	if err != nil {
+		return nil, storeError("file.read_failed", err)
+	}
+	return todos, nil
+}
+
+// DeferTodo hides a todo until the start of day ("2006-01-02") in the
+// todo's zone. An empty day makes it available now.
+func (a *App) DeferTodo(id string, day string) error {
+	item, err := a.findTodo(id)
+	if err != nil {
+		return err
+	}
+
+	item.DeferUntil = nil
+	if day != "" {
+		d, err := time.Parse(time.DateOnly, day)
+		if err != nil {
+			e := apperr.Invalid("defer_until", "validation.format", time.DateOnly)
+			e.Err = err
+			return e
+		}
+		start := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, item.Location())
+		item.DeferUntil = &start
+	}
+	if err := a.Store.UpdateTodo(item); err != nil {
+		return storeError("todo.save_failed", err)
+	}
+	slog.Info("todo deferred", "id", id, "until", item.DeferUntil)
+	a.notifyUpdate()
+	return nil
+}
+
+// startDeferWatcher tells the ball and the main window when a deferred
+// todo starts, so it shows up without waiting for their next poll. Main
+// process only.
+func (a *App) startDeferWatcher() {
+	go func() {
+		ticker := time.NewTicker(deferCheckInterval)
+		defer ticker.Stop()
+		var next time.Time
+		for {
+			now := time.Now()
+			if !next.IsZero() && !now.Before(next) {
+				slog.Info("deferred todos started", "at", next)
+				a.notifyUpdate()
+			}
+			next = time.Time{}
+			// Locked: nothing starts until the data is unlocked
+			if todos, err := a.Store.AllTodos(); err == nil {
+				next, _ = models.NextStart(todos, now)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-a.ctx.Done():
+				return
+			}
+		}
+	}()
+}
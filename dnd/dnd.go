@@ -0,0 +1,71 @@
+// Package dnd keeps the manual do-not-disturb switch. It lives in
+// dnd.json rather than the config so the tray and the ball menu (separate
+// processes) can both flip it without overwriting other settings. The
+// switch turns itself off when it expires.
+package dnd
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+	"todo-ball/statefile"
+)
+
+const FileName = "dnd.json"
+
+type state struct {
+	Until time.Time `json:"until"` // Zero while off
+}
+
+// Manager owns dnd.json, re-reading it when the other process changed it.
+type Manager struct {
+	// Now replaces time.Now, for tests.
+	Now func() time.Time
+
+	mu    sync.Mutex
+	file  *statefile.File[state]
+	state state
+}
+
+// NewManager keeps its state in dir/dnd.json.
+func NewManager(dir string) *Manager {
+	return &Manager{file: statefile.New[state](filepath.Join(dir, FileName))}
+}
+
+func (m *Manager) now() time.Time {
+	if m.Now != nil {
+		return m.Now()
+	}
+	return time.Now()
+}
+
+// Until returns when do-not-disturb ends, or the zero time if it is off
+// or has expired.
+func (m *Manager) Until() (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.loadLocked(); err != nil {
+		return time.Time{}, err
+	}
+	if !m.state.Until.After(m.now()) {
+		return time.Time{}, nil
+	}
+	return m.state.Until, nil
+}
+
+// Set turns do-not-disturb on until the given time, or off for the zero
+// time.
+func (m *Manager) Set(until time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state = state{Until: until}
+	return m.saveLocked()
+}
+
+func (m *Manager) loadLocked() error {
+	return m.file.Load(&m.state)
+}
+
+func (m *Manager) saveLocked() error {
+	return m.file.Save(m.state)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"time"
+	"todo-ball/apperr"
+	"todo-ball/models"
+	"todo-ball/platform"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// RestartBallEventName asks the ball to exit so main can relaunch it
+// with the current key.
+const RestartBallEventName = "Local\\TodoBallRestartBallEvent"
+
+// GetEncryptionStatus reports whether data is encrypted and still locked
+func (a *App) GetEncryptionStatus() models.EncryptionStatus {
+	return models.EncryptionStatus{
+		Enabled: a.Store.IsEncrypted(),
+		Locked:  a.Store.IsLocked(),
+	}
+}
+
+// Unlock loads the encrypted todos with passphrase
+func (a *App) Unlock(passphrase string) error {
+	if err := a.Store.Unlock(passphrase); err != nil {
+		return storeError("encryption.failed", err)
+	}
+	slog.Info("store unlocked")
+	a.restartBall()
+	a.notifyUpdate()
+	// Archiving was skipped while locked
+	go a.archiveCompleted()
+	// Time zones are pinned by the main window, which knows the zone
+	go a.migrateReminderDays()
+	return nil
+}
+
+// EnableEncryption encrypts all lists with passphrase
+func (a *App) EnableEncryption(passphrase string) error {
+	if err := a.withBallStopped(func() error { return a.Store.EnableEncryption(passphrase) }); err != nil {
+		return err
+	}
+	slog.Info("encryption enabled")
+	return nil
+}
+
+// ChangePassphrase re-encrypts all lists under a new passphrase
+func (a *App) ChangePassphrase(oldPassphrase string, newPassphrase string) error {
+	if err := a.withBallStopped(func() error { return a.Store.ChangePassphrase(oldPassphrase, newPassphrase) }); err != nil {
+		return err
+	}
+	slog.Info("passphrase changed")
+	return nil
+}
+
+// DisableEncryption stores all lists as plain JSON again
+func (a *App) DisableEncryption(passphrase string) error {
+	if err := a.withBallStopped(func() error { return a.Store.DisableEncryption(passphrase) }); err != nil {
+		return err
+	}
+	slog.Info("encryption disabled")
+	a.notifyUpdate()
+	return nil
+}
+
+// keyStdin returns the hex key the ball reads from stdin, or nil while locked.
+// The key never goes on the command line where other processes could read it.
+func (a *App) keyStdin() *strings.Reader {
+	key := a.Store.Key()
+	if key == nil {
+		return nil
+	}
+	return strings.NewReader(hex.EncodeToString(key))
+}
+
+// restartBall makes a running ball exit and starts a new one that
+// receives the current key.
+func (a *App) restartBall() {
+	if a.Mode != "main" {
+		return
+	}
+	a.stopBall()
+	a.launchBall()
+}
+
+// withBallStopped runs fn, which re-encodes the data files, while no ball
+// is running: a ball still holding the old key could otherwise read or
+// finish moving records halfway through. A ball with the new key is
+// started afterwards. The change is refused if the ball doesn't exit;
+// errors from fn are reported as encryption.failed.
+func (a *App) withBallStopped(fn func() error) error {
+	if a.Mode != "main" {
+		if err := fn(); err != nil {
+			return storeError("encryption.failed", err)
+		}
+		return nil
+	}
+	// Keep the toggle-ball hotkey from launching one in the meantime
+	a.rekeying.Store(true)
+	if !a.stopBall() {
+		a.rekeying.Store(false)
+		return apperr.New(apperr.Conflict, "encryption.ball_running")
+	}
+	err := fn()
+	a.rekeying.Store(false)
+	a.launchBall()
+	if err != nil {
+		return storeError("encryption.failed", err)
+	}
+	return nil
+}
+
+// stopBall asks a running ball to exit and waits for its window to go.
+// It reports whether no ball is left.
+func (a *App) stopBall() bool {
+	if platform.FindWindow("悬浮球") == 0 {
+		return true
+	}
+	hEvent, err := platform.OpenEvent(RestartBallEventName)
+	if err != nil || hEvent == 0 {
+		slog.Warn("OpenEvent failed, ball not stopped", "event", RestartBallEventName, "err", err)
+		return false
+	}
+	if err := platform.SetEvent(hEvent); err != nil {
+		slog.Warn("SetEvent failed", "event", RestartBallEventName, "err", err)
+	}
+	platform.CloseHandle(hEvent)
+
+	// Wait for the old ball to release its window and mutex
+	for i := 0; i < 30 && platform.FindWindow("悬浮球") != 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	return platform.FindWindow("悬浮球") == 0
+}
+
+// listenRestartBall runs in the ball process and quits when main asks for a restart
+func (a *App) listenRestartBall() {
+	hEvent, err := platform.CreateEvent(RestartBallEventName)
+	if err != nil || hEvent == 0 {
+		slog.Error("CreateEvent failed, ball can't be restarted after unlock", "event", RestartBallEventName, "err", err)
+		return
+	}
+	defer platform.CloseHandle(hEvent)
+	status, err := platform.WaitForSingleObject(hEvent, platform.INFINITE)
+	if err != nil {
+		slog.Error("WaitForSingleObject failed", "event", RestartBallEventName, "err", err)
+		return
+	}
+	if status == platform.WAIT_OBJECT_0 {
+		slog.Info("restart event received")
+		a.shouldQuit = true
+		runtime.Quit(a.ctx)
+	}
+}
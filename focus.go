@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+	"todo-ball/apperr"
+	"todo-ball/focus"
+	"todo-ball/storage"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// focusTickInterval is how often both windows get the countdown
+const focusTickInterval = time.Second
+
+// StartFocus starts a focus timer on a todo of the active list, with the
+// work and break lengths from the config.
+func (a *App) StartFocus(todoID string) (focus.Status, error) {
+	found := false
+	for _, t := range a.Store.GetTodos() {
+		if t.ID == todoID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return focus.Status{}, storeError("todo.not_found", storage.ErrNotFound)
+	}
+	cfg := a.Store.Config()
+	status, err := a.focus.Start(todoID, cfg.ActiveList,
+		time.Duration(cfg.FocusWorkMinutes)*time.Minute,
+		time.Duration(cfg.FocusBreakMinutes)*time.Minute)
+	return a.focusChanged(status, err)
+}
+
+// PauseFocus freezes the countdown of the running timer.
+func (a *App) PauseFocus() (focus.Status, error) {
+	return a.focusChanged(a.focus.Pause())
+}
+
+// ResumeFocus continues a paused timer.
+func (a *App) ResumeFocus() (focus.Status, error) {
+	return a.focusChanged(a.focus.Resume())
+}
+
+// StopFocus ends the timer, keeping the time worked so far.
+func (a *App) StopFocus() (focus.Status, error) {
+	return a.focusChanged(a.focus.Stop())
+}
+
+// GetFocusStatus returns the running timer, if any.
+func (a *App) GetFocusStatus() (focus.Status, error) {
+	status, err := a.focus.Status()
+	if err != nil {
+		return focus.Status{}, focusError(err)
+	}
+	return status, nil
+}
+
+// GetFocusSessions returns the focus sessions recorded for a todo,
+// or all of them if todoID is empty.
+func (a *App) GetFocusSessions(todoID string) ([]focus.Session, error) {
+	sessions, err := a.focus.Sessions(todoID)
+	if err != nil {
+		return nil, focusError(err)
+	}
+	return sessions, nil
+}
+
+// focusChanged pushes the new state to this window right away; the
+// other process notices the file change on its next tick.
+func (a *App) focusChanged(status focus.Status, err error) (focus.Status, error) {
+	if err != nil {
+		return focus.Status{}, focusError(err)
+	}
+	runtime.EventsEmit(a.ctx, "focus_tick", status)
+	a.updateIcons()
+	return status, nil
+}
+
+func focusError(err error) error {
+	switch {
+	case errors.Is(err, focus.ErrRunning):
+		return apperr.Wrap(apperr.Conflict, "focus.running", err)
+	case errors.Is(err, focus.ErrNotRunning):
+		return apperr.Wrap(apperr.Conflict, "focus.not_running", err)
+	case errors.Is(err, focus.ErrPaused):
+		return apperr.Wrap(apperr.Conflict, "focus.paused", err)
+	case errors.Is(err, focus.ErrNotPaused):
+		return apperr.Wrap(apperr.Conflict, "focus.not_paused", err)
+	}
+	slog.Error("focus timer failed", "err", err)
+	return apperr.Wrap(apperr.IO, "focus.save_failed", err)
+}
+
+// startFocusTicker emits "focus_tick" with the timer status every second
+// while a timer exists, and once more when it ends, so the ball can show
+// the countdown. It runs in both processes.
+func (a *App) startFocusTicker() {
+	go func() {
+		ticker := time.NewTicker(focusTickInterval)
+		defer ticker.Stop()
+		wasActive := false
+		for {
+			select {
+			case <-ticker.C:
+			case <-a.ctx.Done():
+				return
+			}
+			status, err := a.focus.Status()
+			if err != nil {
+				slog.Warn("read focus timer failed", "err", err)
+				continue
+			}
+			if status.Active || wasActive {
+				runtime.EventsEmit(a.ctx, "focus_tick", status)
+			}
+			if wasActive && !status.Active {
+				slog.Info("focus timer finished")
+			}
+			wasActive = status.Active
+		}
+	}()
+}
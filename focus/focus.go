@@ -0,0 +1,304 @@
+// Package focus runs pomodoro-style focus timers attached to a todo.
+//
+// The timer is stored in focus.json as absolute times rather than a
+// running countdown, so the main window and the ball (separate
+// processes) read the same remaining time, and a restart of either one
+// picks it up where it was. Finished work phases are kept as Sessions.
+package focus
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+	"todo-ball/statefile"
+)
+
+const FileName = "focus.json"
+
+// Phases of a running timer.
+const (
+	PhaseWork  = "work"
+	PhaseBreak = "break"
+)
+
+var (
+	ErrRunning    = errors.New("a focus timer is already running")
+	ErrNotRunning = errors.New("no focus timer is running")
+	ErrPaused     = errors.New("focus timer is paused")
+	ErrNotPaused  = errors.New("focus timer is not paused")
+)
+
+// Timer is the persisted state of the current timer. Time spent in the
+// current phase is Elapsed plus, while running, now - ResumedAt.
+type Timer struct {
+	ID     string        `json:"id"`
+	TodoID string        `json:"todo_id"`
+	ListID string        `json:"list_id"`
+	Work   time.Duration `json:"work"`
+	Break  time.Duration `json:"break"`
+
+	Phase     string        `json:"phase"`
+	Started   time.Time     `json:"started"`    // Start of the work phase
+	Elapsed   time.Duration `json:"elapsed"`    // Before the last resume
+	ResumedAt time.Time     `json:"resumed_at"` // Zero while paused
+}
+
+// Session is one work phase, finished or stopped early.
+type Session struct {
+	ID        string    `json:"id"`
+	TodoID    string    `json:"todo_id"`
+	ListID    string    `json:"list_id"`
+	Started   time.Time `json:"started"`
+	Ended     time.Time `json:"ended"`
+	Seconds   int       `json:"seconds"` // Time actually worked, pauses excluded
+	Completed bool      `json:"completed"`
+}
+
+// Status is what the UIs show. Inactive when no timer exists.
+type Status struct {
+	Active           bool   `json:"active"`
+	TodoID           string `json:"todo_id"`
+	ListID           string `json:"list_id"`
+	Phase            string `json:"phase"`
+	Paused           bool   `json:"paused"`
+	RemainingSeconds int    `json:"remaining_seconds"`
+	TotalSeconds     int    `json:"total_seconds"`
+}
+
+type state struct {
+	Active   *Timer    `json:"active,omitempty"`
+	Sessions []Session `json:"sessions"`
+}
+
+// Manager owns focus.json. Every call re-reads the file if the other
+// process changed it, so no call is needed to keep in sync.
+type Manager struct {
+	// Now replaces time.Now, for tests.
+	Now func() time.Time
+
+	mu    sync.Mutex
+	file  *statefile.File[state]
+	state state
+}
+
+// NewManager keeps its state in dir/focus.json.
+func NewManager(dir string) *Manager {
+	return &Manager{file: statefile.New[state](filepath.Join(dir, FileName))}
+}
+
+func (m *Manager) now() time.Time {
+	if m.Now != nil {
+		return m.Now()
+	}
+	return time.Now()
+}
+
+func (m *Manager) loadLocked() error {
+	return m.file.Load(&m.state)
+}
+
+func (m *Manager) saveLocked() error {
+	return m.file.Save(m.state)
+}
+
+// update loads the state, brings the timer up to now and saves if fn or
+// the catch-up changed anything.
+func (m *Manager) update(fn func(now time.Time) (bool, error)) (Status, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.loadLocked(); err != nil {
+		return Status{}, err
+	}
+	now := m.now()
+	changed := m.advanceLocked(now)
+	if fn != nil {
+		fnChanged, err := fn(now)
+		if err != nil {
+			return Status{}, err
+		}
+		changed = changed || fnChanged
+	}
+	if changed {
+		if err := m.saveLocked(); err != nil {
+			return Status{}, err
+		}
+	}
+	return m.statusLocked(now), nil
+}
+
+func (t *Timer) length() time.Duration {
+	if t.Phase == PhaseBreak {
+		return t.Break
+	}
+	return t.Work
+}
+
+func (t *Timer) elapsed(now time.Time) time.Duration {
+	if t.ResumedAt.IsZero() {
+		return t.Elapsed
+	}
+	return t.Elapsed + now.Sub(t.ResumedAt)
+}
+
+// advanceLocked moves a running timer through the phases that ended
+// while nobody was looking: a finished work phase is recorded and the
+// break starts at the moment it ended; a finished break ends the timer.
+func (m *Manager) advanceLocked(now time.Time) bool {
+	t := m.state.Active
+	changed := false
+	for t != nil && !t.ResumedAt.IsZero() {
+		over := t.elapsed(now) - t.length()
+		if over < 0 {
+			break
+		}
+		ended := now.Add(-over)
+		changed = true
+		if t.Phase == PhaseWork {
+			m.recordLocked(t, ended, t.Work, true)
+			if t.Break > 0 {
+				t.Phase = PhaseBreak
+				t.Elapsed = 0
+				t.ResumedAt = ended
+				continue
+			}
+		}
+		m.state.Active = nil
+		t = nil
+	}
+	return changed
+}
+
+// recordLocked adds the work phase of t as a session. The ID is derived
+// from the timer so both processes catching up at once can't add it twice.
+func (m *Manager) recordLocked(t *Timer, ended time.Time, worked time.Duration, completed bool) {
+	for _, s := range m.state.Sessions {
+		if s.ID == t.ID {
+			return
+		}
+	}
+	m.state.Sessions = append(m.state.Sessions, Session{
+		ID:        t.ID,
+		TodoID:    t.TodoID,
+		ListID:    t.ListID,
+		Started:   t.Started,
+		Ended:     ended,
+		Seconds:   int(worked.Round(time.Second) / time.Second),
+		Completed: completed,
+	})
+}
+
+func (m *Manager) statusLocked(now time.Time) Status {
+	t := m.state.Active
+	if t == nil {
+		return Status{}
+	}
+	remaining := t.length() - t.elapsed(now)
+	return Status{
+		Active:           true,
+		TodoID:           t.TodoID,
+		ListID:           t.ListID,
+		Phase:            t.Phase,
+		Paused:           t.ResumedAt.IsZero(),
+		RemainingSeconds: int((max(remaining, 0) + time.Second - 1) / time.Second),
+		TotalSeconds:     int(t.length() / time.Second),
+	}
+}
+
+// Status returns the current timer, recording phases that have ended.
+func (m *Manager) Status() (Status, error) {
+	return m.update(nil)
+}
+
+// Start begins a work phase of length work for the todo, followed by a
+// break of length brk (none if 0).
+func (m *Manager) Start(todoID, listID string, work, brk time.Duration) (Status, error) {
+	if work <= 0 || brk < 0 {
+		return Status{}, fmt.Errorf("invalid focus lengths %v/%v", work, brk)
+	}
+	return m.update(func(now time.Time) (bool, error) {
+		if m.state.Active != nil {
+			return false, ErrRunning
+		}
+		m.state.Active = &Timer{
+			ID:        fmt.Sprintf("%d", now.UnixNano()),
+			TodoID:    todoID,
+			ListID:    listID,
+			Work:      work,
+			Break:     brk,
+			Phase:     PhaseWork,
+			Started:   now,
+			ResumedAt: now,
+		}
+		return true, nil
+	})
+}
+
+// Pause freezes the countdown.
+func (m *Manager) Pause() (Status, error) {
+	return m.update(func(now time.Time) (bool, error) {
+		t := m.state.Active
+		if t == nil {
+			return false, ErrNotRunning
+		}
+		if t.ResumedAt.IsZero() {
+			return false, ErrPaused
+		}
+		t.Elapsed = t.elapsed(now)
+		t.ResumedAt = time.Time{}
+		return true, nil
+	})
+}
+
+// Resume continues a paused countdown.
+func (m *Manager) Resume() (Status, error) {
+	return m.update(func(now time.Time) (bool, error) {
+		t := m.state.Active
+		if t == nil {
+			return false, ErrNotRunning
+		}
+		if !t.ResumedAt.IsZero() {
+			return false, ErrNotPaused
+		}
+		t.ResumedAt = now
+		return true, nil
+	})
+}
+
+// Stop ends the timer. Time worked in an unfinished work phase is kept
+// as an incomplete session; skipping a break records nothing.
+func (m *Manager) Stop() (Status, error) {
+	return m.update(func(now time.Time) (bool, error) {
+		t := m.state.Active
+		if t == nil {
+			return false, ErrNotRunning
+		}
+		if t.Phase == PhaseWork {
+			if worked := t.elapsed(now); worked >= time.Second {
+				m.recordLocked(t, now, worked, false)
+			}
+		}
+		m.state.Active = nil
+		return true, nil
+	})
+}
+
+// Sessions returns the recorded sessions of a todo, or all of them if
+// todoID is empty, oldest first.
+func (m *Manager) Sessions(todoID string) ([]Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.loadLocked(); err != nil {
+		return nil, err
+	}
+	sessions := []Session{}
+	for _, s := range m.state.Sessions {
+		if todoID == "" || s.TodoID == todoID {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions, nil
+}
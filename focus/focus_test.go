@@ -0,0 +1,187 @@
+package focus
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+	"todo-ball/statefile"
+)
+
+// clock is a fake time source for Manager.Now.
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time          { return c.t }
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newManager(t *testing.T, c *clock) *Manager {
+	t.Helper()
+	m := NewManager(t.TempDir())
+	m.Now = c.now
+	return m
+}
+
+func wantStatus(t *testing.T, what string, got Status, err error, want Status) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: %v", what, err)
+	}
+	if got != want {
+		t.Errorf("%s = %+v, want %+v", what, got, want)
+	}
+}
+
+func TestStartPauseResumeStop(t *testing.T) {
+	c := &clock{time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)}
+	m := newManager(t, c)
+	work := Status{Active: true, TodoID: "t1", ListID: "l1", Phase: PhaseWork, TotalSeconds: 1500}
+
+	st, err := m.Start("t1", "l1", 25*time.Minute, 5*time.Minute)
+	work.RemainingSeconds = 1500
+	wantStatus(t, "Start", st, err, work)
+	if _, err := m.Start("t2", "l1", time.Minute, 0); !errors.Is(err, ErrRunning) {
+		t.Errorf("second Start: got %v, want ErrRunning", err)
+	}
+
+	c.advance(10 * time.Minute)
+	st, err = m.Pause()
+	work.RemainingSeconds, work.Paused = 900, true
+	wantStatus(t, "Pause", st, err, work)
+	if _, err := m.Pause(); !errors.Is(err, ErrPaused) {
+		t.Errorf("second Pause: got %v, want ErrPaused", err)
+	}
+
+	// Time while paused doesn't count
+	c.advance(time.Hour)
+	st, err = m.Status()
+	wantStatus(t, "Status while paused", st, err, work)
+	st, err = m.Resume()
+	work.Paused = false
+	wantStatus(t, "Resume", st, err, work)
+	if _, err := m.Resume(); !errors.Is(err, ErrNotPaused) {
+		t.Errorf("second Resume: got %v, want ErrNotPaused", err)
+	}
+
+	c.advance(5*time.Minute + 500*time.Millisecond)
+	st, err = m.Status()
+	work.RemainingSeconds = 600 // Partial seconds round up
+	wantStatus(t, "Status", st, err, work)
+
+	// Stopping early keeps the time worked as an incomplete session
+	st, err = m.Stop()
+	wantStatus(t, "Stop", st, err, Status{})
+	if _, err := m.Stop(); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("second Stop: got %v, want ErrNotRunning", err)
+	}
+	sessions, err := m.Sessions("t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].Completed || sessions[0].Seconds != 15*60+1 || !sessions[0].Ended.Equal(c.t) {
+		t.Errorf("sessions = %+v, want one incomplete of 901s", sessions)
+	}
+}
+
+func TestStartInvalid(t *testing.T) {
+	m := newManager(t, &clock{time.Now()})
+	for _, lengths := range [][2]time.Duration{{0, time.Minute}, {time.Minute, -time.Second}} {
+		if _, err := m.Start("t1", "l1", lengths[0], lengths[1]); err == nil {
+			t.Errorf("Start(%v, %v) succeeded", lengths[0], lengths[1])
+		}
+	}
+}
+
+func TestAdvance(t *testing.T) {
+	start := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	c := &clock{start}
+	m := newManager(t, c)
+	if _, err := m.Start("t1", "l1", 25*time.Minute, 5*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	// The break starts when the work phase ended, not when it was noticed
+	c.advance(27 * time.Minute)
+	st, err := m.Status()
+	wantStatus(t, "Status in break", st, err, Status{Active: true, TodoID: "t1", ListID: "l1", Phase: PhaseBreak, RemainingSeconds: 180, TotalSeconds: 300})
+
+	// Skipping the break records nothing more
+	if _, err := m.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	sessions, _ := m.Sessions("")
+	want := Session{ID: sessions[0].ID, TodoID: "t1", ListID: "l1", Started: start, Ended: start.Add(25 * time.Minute), Seconds: 1500, Completed: true}
+	if len(sessions) != 1 || sessions[0] != want {
+		t.Errorf("sessions = %+v, want %+v", sessions, want)
+	}
+
+	// Both phases ending while nobody looked
+	if _, err := m.Start("t2", "l1", 25*time.Minute, 5*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	c.advance(time.Hour)
+	st, err = m.Status()
+	wantStatus(t, "Status after both phases", st, err, Status{})
+
+	// No break: the timer ends with the work phase
+	if _, err := m.Start("t3", "l1", time.Minute, 0); err != nil {
+		t.Fatal(err)
+	}
+	c.advance(time.Minute)
+	st, err = m.Status()
+	wantStatus(t, "Status without break", st, err, Status{})
+
+	sessions, _ = m.Sessions("")
+	if len(sessions) != 3 || sessions[1].TodoID != "t2" || !sessions[1].Completed || sessions[2].Seconds != 60 {
+		t.Errorf("sessions = %+v, want t1, t2 and t3 completed", sessions)
+	}
+	if s, _ := m.Sessions("t2"); len(s) != 1 {
+		t.Errorf("Sessions(t2) = %+v, want one", s)
+	}
+}
+
+func TestSharedFile(t *testing.T) {
+	c := &clock{time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)}
+	dir := t.TempDir()
+	main, ball := NewManager(dir), NewManager(dir)
+	main.Now, ball.Now = c.now, c.now
+
+	if _, err := main.Start("t1", "l1", 25*time.Minute, 0); err != nil {
+		t.Fatal(err)
+	}
+	c.advance(5 * time.Minute)
+	if _, err := ball.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	st, err := main.Status()
+	wantStatus(t, "Status seen by the other process", st, err, Status{Active: true, TodoID: "t1", ListID: "l1", Phase: PhaseWork, Paused: true, RemainingSeconds: 1200, TotalSeconds: 1500})
+}
+
+func TestSessionDedupe(t *testing.T) {
+	c := &clock{time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)}
+	dir := t.TempDir()
+	m := NewManager(dir)
+	m.Now = c.now
+	if _, err := m.Start("t1", "l1", 25*time.Minute, 5*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	c.advance(26 * time.Minute)
+
+	// The other process caught up first and saved the session, but this
+	// one still sees the work phase running
+	var st state
+	file := statefile.New[state](filepath.Join(dir, FileName))
+	if err := file.Load(&st); err != nil {
+		t.Fatal(err)
+	}
+	st.Sessions = append(st.Sessions, Session{ID: st.Active.ID, TodoID: "t1", Seconds: 1500, Completed: true})
+	if err := file.Save(st); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Status(); err != nil {
+		t.Fatal(err)
+	}
+	if sessions, _ := m.Sessions(""); len(sessions) != 1 {
+		t.Errorf("sessions = %+v, want the one already recorded", sessions)
+	}
+}
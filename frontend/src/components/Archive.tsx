@@ -0,0 +1,94 @@
+import { useEffect, useState } from 'react';
+import { GetArchive } from '../../wailsjs/go/main/App';
+import { errorMessage } from '../errors';
+
+const PAGE_SIZE = 20;
+
+// History of completed todos, archived or still in their list
+export default function Archive() {
+    const [from, setFrom] = useState('');
+    const [to, setTo] = useState('');
+    const [query, setQuery] = useState('');
+    const [page, setPage] = useState(1);
+    const [result, setResult] = useState<any>({ entries: [], total: 0 });
+    const [error, setError] = useState('');
+    const [expanded, setExpanded] = useState('');
+
+    const search = async (p: number) => {
+        setError('');
+        try {
+            const res = await GetArchive(from, to, query, p, PAGE_SIZE);
+            setResult(res);
+            setPage(res.page);
+        } catch (e) {
+            setError(errorMessage(e));
+        }
+    };
+
+    // "上个月" shortcut for the most common question
+    const lastMonth = () => {
+        const now = new Date();
+        const first = new Date(now.getFullYear(), now.getMonth() - 1, 1);
+        const last = new Date(now.getFullYear(), now.getMonth(), 0);
+        const fmt = (d: Date) => `${d.getFullYear()}-${String(d.getMonth() + 1).padStart(2, '0')}-${String(d.getDate()).padStart(2, '0')}`;
+        setFrom(fmt(first));
+        setTo(fmt(last));
+    };
+
+    useEffect(() => {
+        search(1);
+    }, [from, to]);
+
+    const pages = Math.max(1, Math.ceil(result.total / PAGE_SIZE));
+    const inputStyle = { padding: '8px', borderRadius: '4px', border: '1px solid var(--border)', color: 'var(--text)', background: 'var(--panel)' };
+    const buttonStyle = { padding: '8px 15px', background: 'var(--button)', border: '1px solid var(--border)', borderRadius: '4px', cursor: 'pointer', color: 'var(--text)' };
+
+    return (
+        <div style={{ background: 'var(--panel)', color: 'var(--text)', padding: '20px', borderRadius: '8px', boxShadow: '0 2px 5px rgba(0,0,0,0.05)', textAlign: 'left' }}>
+            <h2 style={{ marginBottom: '20px', borderBottom: '1px solid var(--border)', paddingBottom: '10px', color: 'var(--text)' }}>历史记录</h2>
+            <div style={{ display: 'flex', gap: '10px', alignItems: 'center', flexWrap: 'wrap', marginBottom: '15px' }}>
+                <input type="date" value={from} onChange={e => setFrom(e.target.value)} style={inputStyle} />
+                <span>至</span>
+                <input type="date" value={to} onChange={e => setTo(e.target.value)} style={inputStyle} />
+                <input
+                    value={query}
+                    onChange={e => setQuery(e.target.value)}
+                    onKeyDown={e => { if (e.key === 'Enter') search(1); }}
+                    placeholder="搜索标题或标签..."
+                    style={{ ...inputStyle, flex: 1, minWidth: '150px' }}
+                />
+                <button onClick={() => search(1)} style={buttonStyle}>搜索</button>
+                <button onClick={lastMonth} style={buttonStyle}>上个月</button>
+            </div>
+            {error && <div style={{ color: '#e74c3c', marginBottom: '10px' }}>{error}</div>}
+            <div style={{ color: 'var(--muted)', fontSize: '12px', marginBottom: '10px' }}>共 {result.total} 项</div>
+
+            {(result.entries || []).map((e: any) => (
+                <div key={e.list_id + '/' + e.todo.id} style={{ borderBottom: '1px solid var(--divider)', padding: '10px 0' }}>
+                    <div style={{ display: 'flex', justifyContent: 'space-between', cursor: 'pointer' }}
+                        onClick={() => setExpanded(expanded === e.todo.id ? '' : e.todo.id)}>
+                        <span>{e.todo.title}</span>
+                        <span style={{ fontSize: '12px', color: '#95a5a6' }}>
+                            {e.list_name} · {e.todo.completed_at ? new Date(e.todo.completed_at).toLocaleString() : ''}{e.archived ? ' · 已归档' : ''}
+                        </span>
+                    </div>
+                    {expanded === e.todo.id && (
+                        <ul style={{ margin: '6px 0 0 0', fontSize: '12px', color: 'var(--muted)' }}>
+                            <li>创建于 {new Date(e.todo.created_at).toLocaleString()}</li>
+                            {(e.todo.history || []).map((h: any, i: number) => (
+                                <li key={i}>{h.type === 'completed' ? '完成于' : '取消完成于'} {new Date(h.at).toLocaleString()}</li>
+                            ))}
+                        </ul>
+                    )}
+                </div>
+            ))}
+            {result.total === 0 && <div style={{ textAlign: 'center', color: '#95a5a6', marginTop: '30px' }}>没有记录</div>}
+
+            <div style={{ display: 'flex', justifyContent: 'center', gap: '10px', alignItems: 'center', marginTop: '15px' }}>
+                <button disabled={page <= 1} onClick={() => search(page - 1)} style={buttonStyle}>上一页</button>
+                <span>{page} / {pages}</span>
+                <button disabled={page >= pages} onClick={() => search(page + 1)} style={buttonStyle}>下一页</button>
+            </div>
+        </div>
+    );
+}
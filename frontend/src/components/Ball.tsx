@@ -1,19 +1,36 @@
 import { useEffect, useState, useRef } from 'react';
-import { GetTodos, OpenMain, GetConfig, CheckDocking, Dock, Undock, GetImageBase64, SetBallMenuState, FullQuit } from '../../wailsjs/go/main/App';
+import { GetBallTodos, ListLists, OpenMain, GetConfig, CheckDocking, Dock, Undock, GetImageBase64, GetBallIcon, GetBallGeometry, GetQuietStatus, SetDND, SetBallMenuState, FullQuit } from '../../wailsjs/go/main/App';
 import { EventsOn } from '../../wailsjs/runtime/runtime';
+import { formatCountdown, formatDueIn } from '../format';
+import { reminderFired } from '../reminders';
 
 export default function Ball() {
     const [style, setStyle] = useState({
         background: '#2ecc71',
         boxShadow: '0 0 5px rgba(0,0,0,0.5)',
         image: '',
-        opacity: 1,
         border: '2px solid rgba(255,255,255,0.2)'
     });
     const [count, setCount] = useState(0);
+    // Window layout and look from the config, see BallGeometry in app.go
+    const [geometry, setGeometry] = useState({ width: 80, height: 80 });
+    const [shape, setShape] = useState('circle');
+    const [content, setContent] = useState('count');
+    // Seconds to the next due todo, null if none
+    const [nextDue, setNextDue] = useState<number | null>(null);
+    const [progress, setProgress] = useState(0);
+    // Data is encrypted and main hasn't unlocked it yet
+    const [locked, setLocked] = useState(false);
+    // Running focus timer, pushed every second by the backend
+    const [focus, setFocus] = useState<any>({ active: false });
     const [docked, setDocked] = useState<'none'|'left'|'right'>('none');
     const [showMenu, setShowMenu] = useState(false);
     const [menuPos, setMenuPos] = useState({ x: 0, y: 0 });
+    // Quiet hours or do-not-disturb: no reminder colour
+    const [quiet, setQuiet] = useState<any>({ active: false });
+    // Reminders just delivered by the backend; the ball pulses while set
+    const [reminders, setReminders] = useState<any[]>([]);
+    const reminderTimer = useRef<number>();
     
     // Cache for base64 image to avoid re-fetching constantly
     const imageCache = useRef<{[key: string]: string}>({});
@@ -26,8 +43,20 @@ export default function Ball() {
              }
         });
 
+        const cleanupFocus = EventsOn("focus_tick", (status: any) => setFocus(status));
+
+        // Includes reminders held back while the user was busy
+        const cleanupReminders = EventsOn("reminders", (list: any[]) => {
+            setReminders(list || []);
+            window.clearTimeout(reminderTimer.current);
+            reminderTimer.current = window.setTimeout(() => setReminders([]), 10000);
+        });
+
         return () => {
             if (cleanupDockEvent) cleanupDockEvent();
+            if (cleanupFocus) cleanupFocus();
+            if (cleanupReminders) cleanupReminders();
+            window.clearTimeout(reminderTimer.current);
         };
     }, []);
 
@@ -37,13 +66,24 @@ export default function Ball() {
     useEffect(() => {
         const check = async () => {
             try {
-                const todos = await GetTodos();
+                let todos;
+                try {
+                    todos = await GetBallTodos();
+                    setLocked(false);
+                } catch (e: any) {
+                    if (e?.code !== 'locked') throw e;
+                    setLocked(true);
+                    todos = [];
+                }
                 const config = await GetConfig();
+                const lists = await ListLists();
+                const activeList = (lists || []).find(l => l.id === config.active_list);
                 
                 const now = new Date();
                 let isExpired = false;
                 let isUpcoming = false;
                 let pendingCount = 0;
+                let soonest: number | null = null;
                 
                 for (const t of todos) {
                     if (t.completed) continue;
@@ -54,42 +94,43 @@ export default function Ball() {
                     if (isNaN(due.getTime())) continue;
 
                     const diff = due.getTime() - now.getTime();
+                    if (diff >= 0 && (soonest === null || diff < soonest)) soonest = diff;
                     const days = diff / (1000 * 3600 * 24);
                     
                     if (days < 0) {
                         isExpired = true;
-                    } else if (days <= t.reminder_days) {
+                    } else if (reminderFired(t, now.getTime())) {
                         isUpcoming = true;
                     }
                 }
                 
                 setCount(pendingCount);
+                setNextDue(soonest === null ? null : soonest / 1000);
+                setProgress(todos.length ? (todos.length - pendingCount) / todos.length : 0);
+                setGeometry(await GetBallGeometry());
+                setShape(config.ball_shape || 'circle');
+                setContent(config.ball_content || 'count');
+                const quietStatus = await GetQuietStatus();
+                setQuiet(quietStatus);
                 
-                const baseColor = config.edge_light_color || '#2ecc71';
+                const baseColor = activeList?.color || config.edge_light_color || '#2ecc71';
                 const urgentColor = config.reminder_color || '#e74c3c'; 
                 
-                const opacity = config.floating_opacity || 1;
-                
                 let customIconUrl = '';
-                const customIconPath = config.custom_icon_path || '';
+                // icon_config.json may set a picture for the current state
+                const stateIcon = await GetBallIcon().catch(() => '');
+                const customIcon = stateIcon || config.custom_icon_path || '';
                 
-                if (customIconPath) {
-                     if (imageCache.current[customIconPath]) {
-                         customIconUrl = imageCache.current[customIconPath];
+                if (customIcon) {
+                     // Asset names are content hashes (asset.key), so a cached image never goes stale
+                     if (imageCache.current[customIcon]) {
+                         customIconUrl = imageCache.current[customIcon];
                      } else {
-                         // Fetch base64
                          try {
-                             const b64 = await GetImageBase64(customIconPath);
-                             if (b64) {
-                                 // Guess mime type.
-                                 const ext = customIconPath.split('.').pop()?.toLowerCase();
-                                 let mime = 'image/png';
-                                 if (ext === 'jpg' || ext === 'jpeg') mime = 'image/jpeg';
-                                 
-                                 const dataUrl = `data:${mime};base64,${b64}`;
-                                 imageCache.current[customIconPath] = dataUrl;
-                                 customIconUrl = dataUrl;
-                             }
+                             const asset = await GetImageBase64(customIcon);
+                             const dataUrl = `data:${asset.mime};base64,${asset.data}`;
+                             imageCache.current[customIcon] = dataUrl;
+                             customIconUrl = dataUrl;
                          } catch (e) {
                              console.error("Failed to load image", e);
                          }
@@ -100,7 +141,7 @@ export default function Ball() {
                 let targetBorderColor = baseColor;
                 let targetShadow = `0 0 5px ${baseColor}`;
 
-                if (isExpired || isUpcoming) {
+                if ((isExpired || isUpcoming) && !quietStatus.active) {
                     targetColor = urgentColor;
                     targetBorderColor = urgentColor;
                     targetShadow = `0 0 15px ${urgentColor}`;
@@ -110,7 +151,6 @@ export default function Ball() {
                     background: targetColor,
                     boxShadow: targetShadow,
                     image: customIconUrl,
-                    opacity: opacity,
                     border: `2px solid ${targetBorderColor}`
                 });
 
@@ -169,13 +209,8 @@ export default function Ball() {
         
         // Expand window first
         SetBallMenuState(true).then(() => {
-             // Position menu below the ball (which is centered)
-             // Window is now 300x300. Ball is 80x80 in center.
-             // Center is 150, 150.
-             // Ball bottom is 150 + 40 = 190.
-             // Let's place menu at center-x, and y=195.
-             
-             setMenuPos({x: 0, y: 0}); // Not using mouse pos anymore, centering relative to ball
+             // The menu is placed below the ball, not at the mouse
+             setMenuPos({x: 0, y: 0});
              setShowMenu(true);
         });
     };
@@ -207,6 +242,28 @@ export default function Ball() {
         );
     }
 
+    const scale = Math.min(geometry.width, geometry.height) / 80;
+    const pill = shape === 'pill';
+    const radius = shape === 'circle' ? '50%' : `${Math.round(pill ? geometry.height / 2 : geometry.height / 4)}px`;
+    // Icon mode shows the picture alone, count mode puts the count on it;
+    // the other modes and a running focus timer draw over the plain colour
+    const image = !focus.active && (content === 'icon' || content === 'count') ? style.image : '';
+    // The ring fills with the focus timer while one runs
+    const ring = focus.active && focus.total_seconds > 0 ? 1 - focus.remaining_seconds / focus.total_seconds : progress;
+
+    const label = (main: string, sub: string, dim = false) => (
+        <div style={{ position: 'relative', display: 'flex', flexDirection: pill ? 'row' : 'column', alignItems: 'baseline', justifyContent: 'center', gap: pill ? '4px' : 0, opacity: dim ? 0.6 : 1 }}>
+            <span style={{ color: 'white', fontWeight: 'bold', fontSize: `${Math.round(18 * scale)}px` }}>{main}</span>
+            <span style={{ color: 'white', fontSize: `${Math.round(12 * scale)}px` }}>{sub}</span>
+        </div>
+    );
+    let text = label(locked ? '🔒' : String(count), '待办');
+    if (focus.active) {
+        text = label(formatCountdown(focus.remaining_seconds), focus.phase === 'work' ? '专注' : '休息', focus.paused);
+    } else if (content === 'countdown' && !locked && nextDue !== null) {
+        text = label(formatDueIn(nextDue), '后到期');
+    }
+
     return (
         <div 
             style={{
@@ -223,12 +280,11 @@ export default function Ball() {
                     top: '0px',
                     left: '50%',
                     transform: 'translateX(-50%)',
-                    width: '80px',
-                    height: '80px',
-                    borderRadius: '50%',
-                    background: style.image ? `url("${style.image}") center/cover no-repeat` : style.background,
+                    width: `${geometry.width}px`,
+                    height: `${geometry.height}px`,
+                    borderRadius: radius,
+                    background: image ? `url("${image}") center/cover no-repeat` : style.background,
                     boxShadow: style.boxShadow,
-                    opacity: style.opacity,
                     display: 'flex',
                     justifyContent: 'center',
                     alignItems: 'center',
@@ -237,19 +293,32 @@ export default function Ball() {
                     // Wails drag property
                     ['--wails-draggable' as any]: 'drag',
                     border: style.border,
-                    transition: 'none'
+                    transition: 'none',
+                    animation: reminders.length ? 'ball-remind 1s ease-in-out 5' : 'none'
                 }}
+                title={reminders.map(r => (r.kind === 'overdue' ? '已过期: ' : '即将到期: ') + r.title).join('\n')}
                 onDoubleClick={handleClick}
                 onClick={handleClick}
             >
-                {/* 360 style: Show count of tasks if no custom image, or overlay if desired */}
-                {!style.image && (
-                    <div style={{ display: 'flex', flexDirection: 'column', alignItems: 'center' }}>
-                        <span style={{ color: 'white', fontWeight: 'bold', fontSize: '18px' }}>{count}</span>
-                        <span style={{ color: 'white', fontSize: '12px' }}>待办</span>
-                    </div>
+                {content === 'progress' && (
+                    <>
+                        <div style={{
+                            position: 'absolute',
+                            inset: 0,
+                            borderRadius: radius,
+                            background: `conic-gradient(rgba(255,255,255,0.9) ${Math.round(ring * 360)}deg, rgba(255,255,255,0.2) 0)`
+                        }} />
+                        {/* Covers all but the outer few pixels, leaving a ring */}
+                        <div style={{
+                            position: 'absolute',
+                            inset: `${Math.max(3, Math.round(5 * scale))}px`,
+                            borderRadius: radius,
+                            background: style.background
+                        }} />
+                    </>
                 )}
-                {style.image && count > 0 && (
+                {!image && text}
+                {image && content === 'count' && count > 0 && (
                     <div style={{ 
                         position: 'absolute', 
                         bottom: '0', 
@@ -257,12 +326,12 @@ export default function Ball() {
                         background: 'red', 
                         color: 'white', 
                         borderRadius: '50%', 
-                        width: '24px', 
-                        height: '24px', 
+                        width: `${Math.round(24 * scale)}px`, 
+                        height: `${Math.round(24 * scale)}px`, 
                         display: 'flex', 
                         justifyContent: 'center', 
                         alignItems: 'center',
-                        fontSize: '12px',
+                        fontSize: `${Math.round(12 * scale)}px`,
                         fontWeight: 'bold'
                     }}>
                         {count}
@@ -274,12 +343,8 @@ export default function Ball() {
                         onClick={(e) => e.stopPropagation()} // Prevent closing when clicking inside menu
                         style={{
                         position: 'absolute',
-                        // When menu is open, ball is at 0,0. Center X is 40. Bottom Y is 80.
-                        // Menu top should be ~80px.
-                        // Window height is 140px.
-                        // Ball is 80x80.
-                        // Menu is 70px wide.
-                        top: '82px', // Slight gap from ball
+                        // When menu is open the window grows below the ball, see BallGeometry
+                        top: `${geometry.height + 2}px`, // Slight gap from ball
                         left: '50%', 
                         transform: 'translateX(-50%)', 
                         width: '70px', // Smaller than ball diameter (80px)
@@ -305,6 +370,29 @@ export default function Ball() {
                         >
                             显示主界面
                         </div>
+                        <div 
+                            onClick={async () => {
+                                try {
+                                    setQuiet(await SetDND(quiet.dnd_until ? 0 : 60));
+                                } catch (e) {
+                                    console.error(e);
+                                }
+                                setShowMenu(false);
+                                SetBallMenuState(false);
+                            }}
+                            style={{
+                                padding: '5px 10px',
+                                cursor: 'pointer',
+                                color: '#333',
+                                fontSize: '10px',
+                                textAlign: 'center',
+                                borderBottom: '1px solid #eee'
+                            }}
+                            onMouseEnter={e => e.currentTarget.style.background = '#f5f5f5'}
+                            onMouseLeave={e => e.currentTarget.style.background = 'white'}
+                        >
+                            {quiet.dnd_until ? '关闭免打扰' : '免打扰 1 小时'}
+                        </div>
                         <div 
                             onClick={() => FullQuit()}
                             style={{
@@ -1,21 +1,104 @@
-import { useEffect, useState } from 'react';
-import { GetTodos, AddTodo, ToggleTodo, DeleteTodo, GetConfig, UpdateConfig, SelectFile } from '../../wailsjs/go/main/App';
+import { useEffect, useState, useRef } from 'react';
+import { GetTodos, QueryTodos, AddTodo, UpdateTodo, QuickAdd, PinTimeZones, DeferTodo, ToggleTodo, DeleteTodo, GetConfig, UpdateConfig, SelectFile, GetImageBase64, ListLists, CreateList, SwitchList, DeleteList, GetEncryptionStatus, Unlock, EnableEncryption, ChangePassphrase, DisableEncryption, StartFocus, PauseFocus, ResumeFocus, StopFocus, GetFocusStatus, StartTracking, StopTracking, GetTrackingStatus, ListThemes, ApplyTheme, SaveTheme, DeleteTheme, ExportTheme, ImportTheme } from '../../wailsjs/go/main/App';
+import { EventsOn } from '../../wailsjs/runtime/runtime';
+import { errorMessage } from '../errors';
+import { dueInput, formatCountdown, formatDue, formatDuration, formatStart, isDeferred, localTimeZone } from '../format';
+import { Reminder, describeReminder, reminderFired, reminderList } from '../reminders';
+import { applyTheme } from '../theme';
+import Archive from './Archive';
+import Reminders from './Reminders';
+import Stats from './Stats';
 
 export default function Main() {
     const [todos, setTodos] = useState<any[]>([]);
     const [filter, setFilter] = useState('all');
+    // Read by refresh, which the event listeners captured on mount
+    const filterRef = useRef(filter);
+    const [deferring, setDeferring] = useState(''); // Todo whose start date is being picked
     const [newContent, setNewContent] = useState('');
     const [newDate, setNewDate] = useState('');
-    const [newReminder, setNewReminder] = useState(1);
+    const [newAllDay, setNewAllDay] = useState(false);
+    const [newReminders, setNewReminders] = useState<Reminder[]>([{ before: 1, unit: 'days' }]);
+    // Todo whose reminders are being edited, and the edited list
+    const [editingReminders, setEditingReminders] = useState('');
+    const [reminderDraft, setReminderDraft] = useState<Reminder[]>([]);
+    const [addError, setAddError] = useState('');
     
     // View state
     const [view, setView] = useState('tasks'); // 'tasks' or 'settings'
     const [config, setConfig] = useState<any>({});
     const [saveMsg, setSaveMsg] = useState('');
+    const [iconPreview, setIconPreview] = useState('');
+    const [iconError, setIconError] = useState('');
+
+    // Theme presets
+    const [themes, setThemes] = useState<any[]>([]);
+    const [themeName, setThemeName] = useState('');
+    const [themeMsg, setThemeMsg] = useState('');
+    const addInputRef = useRef<HTMLInputElement>(null);
+
+    // Todo lists
+    const [lists, setLists] = useState<any[]>([]);
+    const [activeList, setActiveList] = useState('default');
+    const [newListName, setNewListName] = useState('');
+
+    // Encryption at rest
+    const [encStatus, setEncStatus] = useState({ enabled: false, locked: false });
+    const [passphrase, setPassphrase] = useState('');
+    const [newPassphrase, setNewPassphrase] = useState('');
+    const [encMsg, setEncMsg] = useState('');
+
+    // Focus timer, ticking in the backend
+    const [focus, setFocus] = useState<any>({ active: false });
+
+    // Time tracking; elapsed is counted here from status.since
+    const [tracking, setTracking] = useState<any>({ active: false });
+    const [now, setNow] = useState(Date.now());
+
+    // Todo opened from the tray menu, highlighted for a moment
+    const [highlight, setHighlight] = useState('');
+
+    const loadEncStatus = async () => {
+        try {
+            setEncStatus(await GetEncryptionStatus());
+        } catch (e) {
+            console.error(e);
+        }
+    };
+
+    const handleUnlock = async () => {
+        setEncMsg('');
+        try {
+            await Unlock(passphrase);
+            setPassphrase('');
+            await loadEncStatus();
+            // Skipped while locked
+            await PinTimeZones(localTimeZone()).catch(console.error);
+            refresh();
+        } catch (e) {
+            setEncMsg(errorMessage(e));
+        }
+    };
+
+    // action runs one of the encryption bindings and reports the result in the settings page
+    const handleEncryption = async (action: () => Promise<void>, done: string) => {
+        setEncMsg('处理中...');
+        try {
+            await action();
+            setPassphrase('');
+            setNewPassphrase('');
+            setEncMsg(done);
+            await loadEncStatus();
+        } catch (e) {
+            setEncMsg('失败: ' + errorMessage(e));
+        }
+    };
 
     const refresh = async () => {
         try {
-            const items = await GetTodos();
+            // Start dates are judged by the backend
+            const f = filterRef.current;
+            const items = f === 'available' || f === 'scheduled' ? await QueryTodos(f) : await GetTodos();
             setTodos(items || []);
         } catch (e) {
             console.error(e);
@@ -26,26 +109,150 @@ export default function Main() {
         try {
             const cfg = await GetConfig();
             setConfig(cfg);
+            setActiveList(cfg.active_list || 'default');
         } catch (e) {
             console.error(e);
         }
     }
 
+    const loadLists = async () => {
+        try {
+            setLists((await ListLists()) || []);
+        } catch (e) {
+            console.error(e);
+        }
+    };
+
+    const handleSwitchList = async (id: string) => {
+        try {
+            await SwitchList(id);
+            setActiveList(id);
+            refresh();
+        } catch (e) {
+            setAddError(errorMessage(e));
+        }
+    };
+
+    const handleCreateList = async () => {
+        if (!newListName.trim()) return;
+        try {
+            const list = await CreateList(newListName.trim(), '');
+            setNewListName('');
+            await loadLists();
+            await handleSwitchList(list.id);
+        } catch (e) {
+            setAddError(errorMessage(e));
+        }
+    };
+
+    const handleDeleteList = async (id: string) => {
+        try {
+            await DeleteList(id);
+            await loadLists();
+            await loadConfig();
+            refresh();
+        } catch (e) {
+            setAddError(errorMessage(e));
+        }
+    };
+
+    // action runs one of the focus bindings; errors go to the task view
+    const handleFocus = async (action: () => Promise<any>) => {
+        try {
+            setFocus(await action());
+        } catch (e) {
+            setAddError(errorMessage(e));
+        }
+    };
+
+    const handleTracking = async (action: () => Promise<any>) => {
+        try {
+            setTracking(await action());
+        } catch (e) {
+            setAddError(errorMessage(e));
+        }
+    };
+
     useEffect(() => {
-        refresh();
+        // Todos saved before time zones were kept belong to this computer's zone
+        PinTimeZones(localTimeZone()).catch(console.error).finally(refresh);
         loadConfig();
+        loadLists();
+        loadEncStatus();
+        loadThemes();
+        GetFocusStatus().then(setFocus).catch(console.error);
+        GetTrackingStatus().then(setTracking).catch(console.error);
+        const clock = setInterval(() => setNow(Date.now()), 1000);
         const interval = setInterval(refresh, 2000);
-        return () => clearInterval(interval);
+
+        // Global quick-add hotkey: jump to the task view and focus the input
+        const cleanupQuickAdd = EventsOn("quick_add", () => {
+            setView('tasks');
+            setTimeout(() => addInputRef.current?.focus(), 0);
+        });
+        const cleanupUpdate = EventsOn("todos_updated", () => refresh());
+        const cleanupFocus = EventsOn("focus_tick", (status: any) => setFocus(status));
+        const cleanupTracking = EventsOn("tracking_updated", (status: any) => setTracking(status));
+        const cleanupShowTodo = EventsOn("show_todo", (id: string) => {
+            setView('tasks');
+            setFilter('all');
+            setHighlight(id);
+            setTimeout(() => document.getElementById('todo-' + id)?.scrollIntoView({ block: 'center' }), 0);
+            setTimeout(() => setHighlight(''), 3000);
+        });
+
+        return () => {
+            clearInterval(interval);
+            if (cleanupQuickAdd) cleanupQuickAdd();
+            if (cleanupUpdate) cleanupUpdate();
+            if (cleanupFocus) cleanupFocus();
+            if (cleanupTracking) cleanupTracking();
+            if (cleanupShowTodo) cleanupShowTodo();
+            clearInterval(clock);
+        };
     }, []);
 
+    useEffect(() => {
+        filterRef.current = filter;
+        refresh();
+    }, [filter]);
+
+    const handleDefer = async (id: string, day: string) => {
+        setDeferring('');
+        try {
+            await DeferTodo(id, day);
+        } catch (e) {
+            setAddError(errorMessage(e));
+        }
+        refresh();
+    };
+
+    const handleSaveReminders = async (t: any) => {
+        try {
+            await UpdateTodo(t.id, t.title, dueInput(t), t.time_zone || '', !!t.all_day, reminderDraft);
+            setEditingReminders('');
+        } catch (e) {
+            setAddError(errorMessage(e));
+        }
+        refresh();
+    };
+
     const handleAdd = async () => {
         if (!newContent) return;
-        // Default to tomorrow if no date? Or today?
-        // User requested dropdown date picker.
-        const dateStr = newDate ? new Date(newDate).toISOString() : new Date().toISOString();
-        await AddTodo(newContent, dateStr, Number(newReminder));
-        setNewContent('');
-        refresh();
+        setAddError('');
+        try {
+            if (newDate) {
+                // Wall-clock time, the backend pins it to this computer's zone
+                await AddTodo(newContent, newDate, localTimeZone(), newAllDay, newReminders);
+            } else {
+                // No date picked: let the backend parse "明天下午3点 #工作 !high" style input
+                await QuickAdd(newContent, localTimeZone());
+            }
+            setNewContent('');
+            refresh();
+        } catch (e) {
+            setAddError('添加失败: ' + errorMessage(e));
+        }
     };
     
     const handleSaveConfig = async () => {
@@ -57,23 +264,62 @@ export default function Main() {
             setTimeout(() => setSaveMsg(''), 2000);
         } catch(e) {
             console.error("Save config error:", e);
-            setSaveMsg('保存失败: ' + e);
+            setSaveMsg('保存失败: ' + errorMessage(e));
         }
     };
     
+    const loadThemes = async () => {
+        try {
+            setThemes((await ListThemes()) || []);
+        } catch (e) {
+            console.error(e);
+        }
+    };
+
+    const handleTheme = async (action: () => Promise<any>, done?: string) => {
+        setThemeMsg('');
+        try {
+            await action();
+            await loadThemes();
+            if (done) setThemeMsg(done);
+        } catch (e) {
+            setThemeMsg('失败: ' + errorMessage(e));
+        }
+    };
+
+    // Appearance edits by hand no longer match the applied theme
+    const setAppearance = (change: any) => setConfig({...config, ...change, theme_name: ''});
+
+    useEffect(() => {
+        applyTheme(config);
+    }, [config.color_mode, config.theme_color]);
+
     const handleSelectImage = async () => {
+        setIconError('');
         try {
-            const path = await SelectFile();
-            if (path) {
-                setConfig({...config, custom_icon_path: path});
+            // The backend imports the picture and hands back its asset name
+            const name = await SelectFile();
+            if (name) {
+                setConfig({...config, custom_icon_path: name});
             }
         } catch(e) {
-            console.error(e);
+            setIconError(errorMessage(e));
         }
     };
 
+    useEffect(() => {
+        const name = config.custom_icon_path;
+        if (!name) {
+            setIconPreview('');
+            return;
+        }
+        GetImageBase64(name)
+            .then(asset => setIconPreview(`data:${asset.mime};base64,${asset.data}`))
+            .catch(() => setIconPreview(''));
+    }, [config.custom_icon_path]);
+
     const filteredTodos = todos.filter(t => {
-        if (filter === 'all') return true;
+        if (filter === 'all' || filter === 'available' || filter === 'scheduled') return true;
         const now = new Date();
         const due = new Date(t.due_date);
         const diff = due.getTime() - now.getTime();
@@ -81,16 +327,16 @@ export default function Main() {
         if (filter === 'completed') return t.completed;
         
         if (t.completed) return false; // Other filters exclude completed? Usually.
-        
-        const days = diff / (1000 * 3600 * 24);
+        if (isDeferred(t)) return false; // Not urgent before it starts
 
         if (filter === 'expired') return diff < 0;
-        if (filter === 'upcoming') return diff > 0 && days <= t.reminder_days;
+        if (filter === 'upcoming') return diff > 0 && reminderFired(t, now.getTime());
         return true;
     });
 
-    // Sort: Upcoming (Future) -> Expired (Past) -> Completed
-    filteredTodos.sort((a, b) => {
+    // Sort: Upcoming (Future) -> Expired (Past) -> Completed.
+    // Scheduled todos keep the backend's order, soonest start first
+    if (filter !== 'scheduled') filteredTodos.sort((a, b) => {
         if (a.completed && !b.completed) return 1;
         if (!a.completed && b.completed) return -1;
         
@@ -132,15 +378,66 @@ export default function Main() {
         marginTop: '20px'
     });
 
+    const viewStyle = (name: string) => ({
+        padding: '10px 15px',
+        cursor: 'pointer',
+        background: view === name ? 'rgba(255,255,255,0.1)' : 'transparent',
+        borderRadius: '4px'
+    });
+
+    if (encStatus.locked) {
+        return (
+            <div style={{ display: 'flex', flexDirection: 'column', alignItems: 'center', justifyContent: 'center', gap: '15px', height: '100vh', width: '100vw', background: 'var(--sidebar)', color: 'white', fontFamily: 'Microsoft YaHei, SimHei, sans-serif' }}>
+                <h2>🔒 数据已加密</h2>
+                <input
+                    type="password"
+                    autoFocus
+                    value={passphrase}
+                    onChange={e => setPassphrase(e.target.value)}
+                    onKeyDown={e => { if (e.key === 'Enter') handleUnlock(); }}
+                    placeholder="请输入密码"
+                    style={{ width: '260px', padding: '10px', borderRadius: '4px', border: 'none' }}
+                />
+                <button onClick={handleUnlock} style={{ padding: '10px 30px', background: 'var(--accent)', color: 'white', border: 'none', borderRadius: '4px', cursor: 'pointer' }}>解锁</button>
+                {encMsg && <span style={{ color: '#e74c3c' }}>{encMsg}</span>}
+            </div>
+        );
+    }
+
     return (
-        <div style={{ display: 'flex', height: '100vh', width: '100vw', background: '#f5f5f5', fontFamily: 'Microsoft YaHei, SimHei, sans-serif' }}>
+        <div style={{ display: 'flex', height: '100vh', width: '100vw', background: 'var(--bg)', fontFamily: 'Microsoft YaHei, SimHei, sans-serif' }}>
             {/* Sidebar */}
-            <div style={{ width: '200px', background: '#2c3e50', color: 'white', padding: '20px', display: 'flex', flexDirection: 'column', gap: '5px' }}>
+            <div style={{ width: '200px', background: 'var(--sidebar)', color: 'white', padding: '20px', display: 'flex', flexDirection: 'column', gap: '5px' }}>
                 <h2 style={{ marginBottom: '20px', textAlign: 'center' }}>待办事项</h2>
+                <div style={{ marginBottom: '15px', display: 'flex', flexDirection: 'column', gap: '5px' }}>
+                    {lists.map(l => (
+                        <div key={l.id} style={{ display: 'flex', alignItems: 'center', justifyContent: 'space-between', padding: '6px 10px', borderRadius: '4px', cursor: 'pointer', background: activeList === l.id ? 'rgba(255,255,255,0.2)' : 'transparent' }}
+                            onClick={() => handleSwitchList(l.id)}>
+                            <span style={{ borderLeft: `3px solid ${l.color || 'transparent'}`, paddingLeft: '6px' }}>{l.name}</span>
+                            {l.id !== 'default' && (
+                                <span onClick={e => { e.stopPropagation(); handleDeleteList(l.id); }} style={{ color: '#e74c3c', fontSize: '12px' }}>✕</span>
+                            )}
+                        </div>
+                    ))}
+                    <div style={{ display: 'flex', gap: '4px' }}>
+                        <input
+                            value={newListName}
+                            onChange={e => setNewListName(e.target.value)}
+                            onKeyDown={e => { if (e.key === 'Enter') handleCreateList(); }}
+                            placeholder="新建清单..."
+                            style={{ flex: 1, minWidth: 0, padding: '4px 6px', borderRadius: '4px', border: 'none' }}
+                        />
+                        <button onClick={handleCreateList} style={{ padding: '4px 8px', border: 'none', borderRadius: '4px', cursor: 'pointer' }}>+</button>
+                    </div>
+                </div>
                 <div style={menuStyle('all')} onClick={() => { setFilter('all'); setView('tasks'); }}>全部任务</div>
+                <div style={menuStyle('available')} onClick={() => { setFilter('available'); setView('tasks'); }}>可开始</div>
+                <div style={menuStyle('scheduled')} onClick={() => { setFilter('scheduled'); setView('tasks'); }}>已计划</div>
                 <div style={menuStyle('upcoming')} onClick={() => { setFilter('upcoming'); setView('tasks'); }}>即将到期</div>
                 <div style={menuStyle('expired')} onClick={() => { setFilter('expired'); setView('tasks'); }}>已过期</div>
                 <div style={menuStyle('completed')} onClick={() => { setFilter('completed'); setView('tasks'); }}>已完成</div>
+                <div style={viewStyle('archive')} onClick={() => setView('archive')}>历史记录</div>
+                <div style={viewStyle('stats')} onClick={() => setView('stats')}>统计</div>
                 
                 <div style={settingsStyle()} onClick={() => setView('settings')}>⚙ 设置</div>
             </div>
@@ -149,35 +446,65 @@ export default function Main() {
             <div style={{ flex: 1, padding: '20px', overflowY: 'auto', display: 'flex', flexDirection: 'column' }}>
                 {view === 'tasks' ? (
                     <>
+                        {tracking.active && (
+                            <div style={{ marginBottom: '20px', background: tracking.idle ? '#95a5a6' : '#8e44ad', color: 'white', padding: '12px 15px', borderRadius: '8px', display: 'flex', gap: '10px', alignItems: 'center' }}>
+                                <span style={{ fontSize: '20px', fontWeight: 'bold' }}>{tracking.idle ? '--:--' : formatDuration((now - new Date(tracking.since).getTime()) / 1000)}</span>
+                                <span style={{ flex: 1 }}>
+                                    {tracking.idle ? '计时已暂停（空闲）' : '计时中'}: {todos.find(t => t.id === tracking.todo_id)?.title || ''}
+                                </span>
+                                <button onClick={() => handleTracking(StopTracking)} style={{ padding: '6px 12px', border: 'none', borderRadius: '4px', cursor: 'pointer' }}>停止</button>
+                            </div>
+                        )}
+                        {focus.active && (
+                            <div style={{ marginBottom: '20px', background: focus.phase === 'work' ? '#e74c3c' : '#27ae60', color: 'white', padding: '12px 15px', borderRadius: '8px', display: 'flex', gap: '10px', alignItems: 'center' }}>
+                                <span style={{ fontSize: '20px', fontWeight: 'bold' }}>{formatCountdown(focus.remaining_seconds)}</span>
+                                <span style={{ flex: 1 }}>
+                                    {focus.phase === 'work' ? '专注中' : '休息中'}{focus.paused ? '（已暂停）' : ''}: {todos.find(t => t.id === focus.todo_id)?.title || ''}
+                                </span>
+                                {focus.paused
+                                    ? <button onClick={() => handleFocus(ResumeFocus)} style={{ padding: '6px 12px', border: 'none', borderRadius: '4px', cursor: 'pointer' }}>继续</button>
+                                    : <button onClick={() => handleFocus(PauseFocus)} style={{ padding: '6px 12px', border: 'none', borderRadius: '4px', cursor: 'pointer' }}>暂停</button>}
+                                <button onClick={() => handleFocus(StopFocus)} style={{ padding: '6px 12px', border: 'none', borderRadius: '4px', cursor: 'pointer' }}>结束</button>
+                            </div>
+                        )}
                         {/* Input Area */}
-                        <div style={{ marginBottom: '20px', background: 'white', padding: '15px', borderRadius: '8px', boxShadow: '0 2px 5px rgba(0,0,0,0.05)', display: 'flex', gap: '10px', alignItems: 'center', flexWrap: 'wrap' }}>
+                        <div style={{ marginBottom: '20px', background: 'var(--panel)', padding: '15px', borderRadius: '8px', boxShadow: '0 2px 5px rgba(0,0,0,0.05)', display: 'flex', gap: '10px', alignItems: 'center', flexWrap: 'wrap' }}>
                     <input 
+                        ref={addInputRef}
                         value={newContent} 
                         onChange={e => setNewContent(e.target.value)} 
-                        placeholder="添加新任务..." 
-                        style={{ flex: 1, padding: '10px', borderRadius: '4px', border: '1px solid #ddd', minWidth: '200px' }}
+                        onKeyDown={e => { if (e.key === 'Enter') handleAdd(); }}
+                        placeholder="添加新任务... (例如: 明天下午3点开会 #工作 !high)" 
+                        style={{ flex: 1, padding: '10px', borderRadius: '4px', border: '1px solid var(--border)', minWidth: '200px' }}
                     />
                     <input 
-                        type="datetime-local" 
+                        type={newAllDay ? 'date' : 'datetime-local'} 
                         value={newDate} 
                         onChange={e => setNewDate(e.target.value)}
-                        style={{ padding: '10px', borderRadius: '4px', border: '1px solid #ddd' }}
+                        style={{ padding: '10px', borderRadius: '4px', border: '1px solid var(--border)' }}
                     />
-                    <select 
-                        value={newReminder} 
-                        onChange={e => setNewReminder(Number(e.target.value))}
-                        style={{ padding: '10px', borderRadius: '4px', border: '1px solid #ddd' }}
-                    >
-                        <option value={0}>不提醒</option>
-                        <option value={1}>1天前提醒</option>
-                        <option value={3}>3天前提醒</option>
-                        <option value={7}>7天前提醒</option>
-                        <option value={14}>14天前提醒</option>
-                        <option value={30}>30天前提醒</option>
-                    </select>
-                    <button onClick={handleAdd} style={{ padding: '10px 20px', background: '#3498db', color: 'white', border: 'none', borderRadius: '4px', cursor: 'pointer' }}>
+                    <label style={{ display: 'flex', alignItems: 'center', gap: '4px', fontSize: '14px' }}>
+                        <input
+                            type="checkbox"
+                            checked={newAllDay}
+                            onChange={e => {
+                                setNewAllDay(e.target.checked);
+                                // Keep the day when switching to a date, the time can't be guessed back
+                                setNewDate(d => e.target.checked ? d.slice(0, 10) : '');
+                            }}
+                        />
+                        全天
+                    </label>
+                    <button onClick={handleAdd} style={{ padding: '10px 20px', background: 'var(--accent)', color: 'white', border: 'none', borderRadius: '4px', cursor: 'pointer' }}>
                         添加
                     </button>
+                    {/* Quick add without a date takes "remind 2h" from the text instead */}
+                    {newDate && (
+                        <div style={{ width: '100%' }}>
+                            <Reminders value={newReminders} onChange={setNewReminders} />
+                        </div>
+                    )}
+                    {addError && <div style={{ width: '100%', color: '#e74c3c', fontSize: '12px', textAlign: 'left' }}>{addError}</div>}
                 </div>
 
                 {/* List */}
@@ -185,20 +512,20 @@ export default function Main() {
                     {filteredTodos.map(t => {
                         const now = new Date();
                         const due = new Date(t.due_date);
-                        const isExpired = due < now && !t.completed;
+                        const deferred = !t.completed && isDeferred(t);
+                        const isExpired = due < now && !t.completed && !deferred;
                         
-                        const diff = due.getTime() - now.getTime();
-                        const days = diff / (1000 * 3600 * 24);
-                        const isUpcoming = !isExpired && !t.completed && days <= t.reminder_days && days > 0;
+                        const isUpcoming = !isExpired && reminderFired(t, now.getTime());
                         
                         let borderColor = '#3498db'; // Default blue
                         if (t.completed) borderColor = '#2ecc71'; // Green
+                        else if (deferred) borderColor = '#95a5a6'; // Grey until it starts
                         else if (isExpired) borderColor = '#e74c3c'; // Red
                         else if (isUpcoming) borderColor = '#f39c12'; // Orange for upcoming
 
                         return (
-                            <div key={t.id} style={{ 
-                                background: 'white', 
+                            <div key={t.id} id={'todo-' + t.id} style={{ 
+                                background: highlight === t.id ? 'var(--highlight)' : 'var(--panel)',
                                 padding: '15px', 
                                 marginBottom: '10px', 
                                 borderRadius: '8px',
@@ -212,41 +539,98 @@ export default function Main() {
                                     <input 
                                         type="checkbox" 
                                         checked={t.completed} 
-                                        onChange={async () => { await ToggleTodo(t.id); refresh(); }} 
+                                        onChange={async () => {
+                                            try { await ToggleTodo(t.id); } catch (e) { setAddError(errorMessage(e)); }
+                                            refresh();
+                                        }} 
                                         style={{ transform: 'scale(1.5)', cursor: 'pointer' }}
                                     />
                                     <div style={{ display: 'flex', flexDirection: 'column', alignItems: 'flex-start', textAlign: 'left' }}>
                                         <span style={{ 
                                             textDecoration: t.completed ? 'line-through' : 'none', 
-                                            color: t.completed ? '#95a5a6' : '#2c3e50',
+                                            color: t.completed || deferred ? '#95a5a6' : '#2c3e50',
                                             fontSize: '16px',
                                             fontWeight: isExpired || isUpcoming ? 'bold' : 'normal',
                                             textAlign: 'left'
                                         }}>
                                             {t.title}
                                         </span>
-                                        <span style={{ fontSize: '12px', color: isExpired ? '#e74c3c' : (isUpcoming ? '#e67e22' : '#7f8c8d'), marginTop: '4px', textAlign: 'left' }}>
-                                            截止: {due.toLocaleString()}
+                                        <span style={{ fontSize: '12px', color: isExpired ? '#e74c3c' : (isUpcoming ? '#e67e22' : 'var(--muted)'), marginTop: '4px', textAlign: 'left' }}>
+                                            截止: {formatDue(t)}
                                             {isExpired && " (已过期)"}
                                             {isUpcoming && " (即将到期)"}
                                         </span>
+                                        {deferred && (
+                                            <span style={{ fontSize: '12px', color: 'var(--muted)', marginTop: '2px', textAlign: 'left' }}>
+                                                开始: {formatStart(t)}
+                                                <button onClick={() => handleDefer(t.id, '')} title="现在开始" style={{ background: 'none', border: 'none', cursor: 'pointer', color: 'var(--muted)', padding: '0 4px' }}>✕</button>
+                                            </span>
+                                        )}
+                                        {editingReminders === t.id ? (
+                                            <div style={{ display: 'flex', flexWrap: 'wrap', alignItems: 'center', gap: '6px', marginTop: '6px' }}>
+                                                <Reminders value={reminderDraft} onChange={setReminderDraft} />
+                                                <button onClick={() => handleSaveReminders(t)} style={{ padding: '4px 10px', background: 'var(--accent)', color: 'white', border: 'none', borderRadius: '4px', cursor: 'pointer' }}>保存</button>
+                                                <button onClick={() => setEditingReminders('')} style={{ padding: '4px 10px', border: 'none', borderRadius: '4px', cursor: 'pointer' }}>取消</button>
+                                            </div>
+                                        ) : !t.completed && reminderList(t).length > 0 && (
+                                            <span style={{ fontSize: '12px', color: 'var(--muted)', marginTop: '2px', textAlign: 'left' }}>
+                                                提醒: {reminderList(t).map(describeReminder).join('、')}
+                                            </span>
+                                        )}
                                     </div>
                                 </div>
-                                <button onClick={async () => { await DeleteTodo(t.id); refresh(); }} style={{ color: '#e74c3c', background: 'none', border: 'none', cursor: 'pointer', padding: '5px' }}>
+                                <div style={{ display: 'flex', gap: '5px' }}>
+                                {!t.completed && tracking.todo_id !== t.id && (
+                                    <button onClick={() => handleTracking(() => StartTracking(t.id))} title="开始计时" style={{ background: 'none', border: 'none', cursor: 'pointer', padding: '5px' }}>
+                                        ⏱
+                                    </button>
+                                )}
+                                {!t.completed && !focus.active && (
+                                    <button onClick={() => handleFocus(() => StartFocus(t.id))} title="开始专注" style={{ background: 'none', border: 'none', cursor: 'pointer', padding: '5px' }}>
+                                        🍅
+                                    </button>
+                                )}
+                                {!t.completed && (deferring === t.id ? (
+                                    <input
+                                        type="date"
+                                        autoFocus
+                                        onChange={e => e.target.value && handleDefer(t.id, e.target.value)}
+                                        onKeyDown={e => { if (e.key === 'Escape') setDeferring(''); }}
+                                        style={{ padding: '2px', borderRadius: '4px', border: '1px solid var(--border)' }}
+                                    />
+                                ) : (
+                                    <button onClick={() => setDeferring(t.id)} title="推迟到某天开始" style={{ background: 'none', border: 'none', cursor: 'pointer', padding: '5px' }}>
+                                        📅
+                                    </button>
+                                ))}
+                                {!t.completed && (
+                                    <button onClick={() => { setEditingReminders(t.id); setReminderDraft(reminderList(t)); }} title="设置提醒" style={{ background: 'none', border: 'none', cursor: 'pointer', padding: '5px' }}>
+                                        🔔
+                                    </button>
+                                )}
+                                <button onClick={async () => {
+                                    try { await DeleteTodo(t.id); } catch (e) { setAddError(errorMessage(e)); }
+                                    refresh();
+                                }} style={{ color: '#e74c3c', background: 'none', border: 'none', cursor: 'pointer', padding: '5px' }}>
                                     删除
                                 </button>
+                                </div>
                             </div>
                         );
                     })}
                     {filteredTodos.length === 0 && <div style={{ textAlign: 'center', color: '#95a5a6', marginTop: '50px' }}>暂无任务</div>}
                 </div>
                 </>
+            ) : view === 'archive' ? (
+                <Archive />
+            ) : view === 'stats' ? (
+                <Stats />
             ) : (
-                <div style={{ background: 'white', color: '#333', padding: '20px', borderRadius: '8px', boxShadow: '0 2px 5px rgba(0,0,0,0.05)', overflowY: 'auto', textAlign: 'left' }}>
-                    <h2 style={{ marginBottom: '20px', borderBottom: '1px solid #eee', paddingBottom: '10px', color: '#333', textAlign: 'left' }}>设置</h2>
+                <div style={{ background: 'var(--panel)', color: 'var(--text)', padding: '20px', borderRadius: '8px', boxShadow: '0 2px 5px rgba(0,0,0,0.05)', overflowY: 'auto', textAlign: 'left' }}>
+                    <h2 style={{ marginBottom: '20px', borderBottom: '1px solid var(--border)', paddingBottom: '10px', color: 'var(--text)', textAlign: 'left' }}>设置</h2>
                     
                     <div style={{ marginBottom: '20px', textAlign: 'left' }}>
-                        <label style={{ display: 'flex', alignItems: 'center', gap: '10px', cursor: 'pointer', color: '#333' }}>
+                        <label style={{ display: 'flex', alignItems: 'center', gap: '10px', cursor: 'pointer', color: 'var(--text)' }}>
                             <input 
                                 type="checkbox"
                                 checked={config.start_on_boot || false}
@@ -258,14 +642,91 @@ export default function Main() {
                     </div>
 
                     <div style={{ marginBottom: '20px' }}>
-                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: '#333' }}>主界面尺寸</label>
+                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: 'var(--text)' }}>主题</label>
+                        <div style={{ display: 'flex', gap: '10px', alignItems: 'center', flexWrap: 'wrap' }}>
+                            <select
+                                value={config.theme_name || ''}
+                                onChange={e => e.target.value && handleTheme(async () => setConfig(await ApplyTheme(e.target.value)))}
+                                style={{ padding: '8px', borderRadius: '4px', border: '1px solid var(--border)', width: '200px', color: 'var(--text)', background: 'var(--panel)' }}
+                            >
+                                <option value="">自定义</option>
+                                {themes.map(th => <option key={th.name} value={th.name}>{th.name}{th.builtin ? '' : ' (自定义)'}</option>)}
+                            </select>
+                            <button onClick={() => handleTheme(() => ExportTheme(config.theme_name))} disabled={!config.theme_name} style={{ padding: '8px 15px', background: 'var(--button)', border: '1px solid var(--border)', borderRadius: '4px', cursor: 'pointer', color: 'var(--text)' }}>
+                                导出
+                            </button>
+                            <button onClick={() => handleTheme(async () => {
+                                const th = await ImportTheme();
+                                if (th.name) setConfig(await ApplyTheme(th.name));
+                            })} style={{ padding: '8px 15px', background: 'var(--button)', border: '1px solid var(--border)', borderRadius: '4px', cursor: 'pointer', color: 'var(--text)' }}>
+                                导入...
+                            </button>
+                            {config.theme_name && !themes.find(th => th.name === config.theme_name)?.builtin && (
+                                <button onClick={() => handleTheme(async () => {
+                                    await DeleteTheme(config.theme_name);
+                                    setConfig({...config, theme_name: ''});
+                                }, '主题已删除')} style={{ padding: '8px 15px', background: '#e74c3c', color: 'white', border: 'none', borderRadius: '4px', cursor: 'pointer' }}>
+                                    删除
+                                </button>
+                            )}
+                        </div>
+                        <div style={{ display: 'flex', gap: '10px', alignItems: 'center', marginTop: '10px' }}>
+                            <input
+                                value={themeName}
+                                onChange={e => setThemeName(e.target.value)}
+                                placeholder="主题名称"
+                                style={{ width: '200px', padding: '6px', borderRadius: '4px', border: '1px solid var(--border)', color: 'var(--text)', background: 'var(--panel)' }}
+                            />
+                            <button onClick={() => handleTheme(async () => {
+                                const name = themeName.trim();
+                                await SaveTheme({
+                                    name,
+                                    color_mode: config.color_mode,
+                                    theme_color: config.theme_color,
+                                    edge_light_color: config.edge_light_color,
+                                    reminder_color: config.reminder_color,
+                                    floating_opacity: config.floating_opacity,
+                                });
+                                setConfig({...config, theme_name: name});
+                                setThemeName('');
+                            }, '主题已保存')} disabled={!themeName.trim()} style={{ padding: '6px 15px', background: 'var(--button)', border: '1px solid var(--border)', borderRadius: '4px', cursor: 'pointer', color: 'var(--text)' }}>
+                                将当前外观存为主题
+                            </button>
+                        </div>
+                        {themeMsg && <div style={{ color: themeMsg.includes('失败') ? '#e74c3c' : '#2ecc71', fontSize: '12px', marginTop: '5px' }}>{themeMsg}</div>}
+                    </div>
+
+                    <div style={{ marginBottom: '20px' }}>
+                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: 'var(--text)' }}>外观</label>
+                        <div style={{ display: 'flex', gap: '10px', alignItems: 'center' }}>
+                            <select
+                                value={config.color_mode || 'light'}
+                                onChange={e => setAppearance({color_mode: e.target.value})}
+                                style={{ padding: '8px', borderRadius: '4px', border: '1px solid var(--border)', width: '200px', color: 'var(--text)', background: 'var(--panel)' }}
+                            >
+                                <option value="light">浅色</option>
+                                <option value="dark">深色</option>
+                                <option value="system">跟随系统</option>
+                            </select>
+                            <input
+                                type="color"
+                                value={config.theme_color || '#3498db'}
+                                onChange={e => setAppearance({theme_color: e.target.value})}
+                                style={{ width: '50px', height: '30px', padding: 0, border: 'none', background: 'none' }}
+                            />
+                            <span style={{ fontSize: '12px', color: 'var(--muted)' }}>主题颜色（按钮）</span>
+                        </div>
+                    </div>
+
+                    <div style={{ marginBottom: '20px' }}>
+                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: 'var(--text)' }}>主界面尺寸</label>
                         <select
                             value={`${config.window_width || 1080}x${config.window_height || 720}`}
                             onChange={e => {
                                 const [w, h] = e.target.value.split('x').map(Number);
                                 setConfig({...config, window_width: w, window_height: h});
                             }}
-                            style={{ padding: '8px', borderRadius: '4px', border: '1px solid #ddd', width: '200px', color: '#333', background: 'white' }}
+                            style={{ padding: '8px', borderRadius: '4px', border: '1px solid var(--border)', width: '200px', color: 'var(--text)', background: 'var(--panel)' }}
                         >
                             <option value="800x600">800 x 600</option>
                             <option value="1024x768">1024 x 768</option>
@@ -279,7 +740,7 @@ export default function Main() {
                     </div>
 
                     <div style={{ marginBottom: '20px' }}>
-                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: '#333' }}>
+                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: 'var(--text)' }}>
                             悬浮球透明度 ({Math.round((config.floating_opacity || 0.8) * 100)}%)
                         </label>
                         <input 
@@ -288,22 +749,201 @@ export default function Main() {
                             max="1.0"
                             step="0.05"
                             value={config.floating_opacity || 0.8}
-                            onChange={e => setConfig({...config, floating_opacity: parseFloat(e.target.value)})}
+                            onChange={e => setAppearance({floating_opacity: parseFloat(e.target.value)})}
                             style={{ width: '100%' }}
                         />
                     </div>
-                    
+
                     <div style={{ marginBottom: '20px' }}>
-                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: '#333' }}>悬浮球自定义图片</label>
+                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: 'var(--text)' }}>
+                            悬浮球大小 ({config.ball_size || 80}px)
+                        </label>
+                        <input
+                            type="range"
+                            min="40"
+                            max="160"
+                            step="4"
+                            value={config.ball_size || 80}
+                            onChange={e => setConfig({...config, ball_size: parseInt(e.target.value)})}
+                            style={{ width: '100%' }}
+                        />
+                    </div>
+
+                    <div style={{ marginBottom: '20px', display: 'flex', gap: '20px' }}>
+                        <div style={{ flex: 1 }}>
+                            <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: 'var(--text)' }}>悬浮球形状</label>
+                            <select
+                                value={config.ball_shape || 'circle'}
+                                onChange={e => setConfig({...config, ball_shape: e.target.value})}
+                                style={{ width: '100%', padding: '8px', borderRadius: '4px', border: '1px solid var(--border)', background: 'var(--panel)', color: 'var(--text)' }}
+                            >
+                                <option value="circle">圆形</option>
+                                <option value="rounded">圆角方形</option>
+                                <option value="pill">胶囊</option>
+                            </select>
+                        </div>
+                        <div style={{ flex: 1 }}>
+                            <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: 'var(--text)' }}>悬浮球显示内容</label>
+                            <select
+                                value={config.ball_content || 'count'}
+                                onChange={e => setConfig({...config, ball_content: e.target.value})}
+                                style={{ width: '100%', padding: '8px', borderRadius: '4px', border: '1px solid var(--border)', background: 'var(--panel)', color: 'var(--text)' }}
+                            >
+                                <option value="count">待办数量</option>
+                                <option value="countdown">最近截止倒计时</option>
+                                <option value="progress">今日完成进度环</option>
+                                <option value="icon">自定义图片</option>
+                            </select>
+                        </div>
+                    </div>
+
+                    <div style={{ marginBottom: '20px' }}>
+                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: 'var(--text)' }}>悬浮球自动淡出</label>
                         <div style={{ display: 'flex', gap: '10px', alignItems: 'center' }}>
-                            <input 
-                                type="text"
-                                value={config.custom_icon_path || ''}
-                                readOnly
-                                placeholder="未选择图片 (默认显示文字)"
-                                style={{ flex: 1, padding: '8px', borderRadius: '4px', border: '1px solid #ddd', background: '#f9f9f9', color: '#333' }}
+                            <input
+                                type="number"
+                                min="0"
+                                max="3600"
+                                value={config.fade_after_seconds ?? 0}
+                                onChange={e => setConfig({...config, fade_after_seconds: Number(e.target.value)})}
+                                style={{ width: '80px', padding: '6px', borderRadius: '4px', border: '1px solid var(--border)', color: 'var(--text)', background: 'var(--panel)' }}
+                            />
+                            <span style={{ fontSize: '12px', color: 'var(--muted)' }}>秒未移到悬浮球上后淡出至</span>
+                            <input
+                                type="range"
+                                min="0.1"
+                                max="1.0"
+                                step="0.05"
+                                value={config.fade_opacity || 0.3}
+                                onChange={e => setConfig({...config, fade_opacity: parseFloat(e.target.value)})}
+                                style={{ width: '120px' }}
                             />
-                            <button onClick={handleSelectImage} style={{ padding: '8px 15px', background: '#ecf0f1', border: '1px solid #bdc3c7', borderRadius: '4px', cursor: 'pointer', color: '#333' }}>
+                            <span style={{ fontSize: '12px', color: 'var(--muted)' }}>{Math.round((config.fade_opacity || 0.3) * 100)}%（0 秒为不淡出）</span>
+                        </div>
+                    </div>
+
+                    <div style={{ marginBottom: '20px' }}>
+                        <label style={{ display: 'flex', alignItems: 'center', gap: '10px', cursor: 'pointer', color: 'var(--text)' }}>
+                            <input
+                                type="checkbox"
+                                checked={config.click_through_fullscreen || false}
+                                onChange={e => setConfig({...config, click_through_fullscreen: e.target.checked})}
+                                style={{ transform: 'scale(1.2)' }}
+                            />
+                            <span style={{ fontWeight: 'bold' }}>全屏应用在前台时鼠标穿透悬浮球</span>
+                        </label>
+                        <div style={{ fontSize: '12px', color: 'var(--muted)', marginTop: '5px' }}>
+                            鼠标穿透时点击会落到悬浮球下方的窗口，可通过托盘菜单或快捷键随时开关
+                        </div>
+                    </div>
+
+                    <div style={{ marginBottom: '20px' }}>
+                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: 'var(--text)' }}>免打扰时段</label>
+                        <div style={{ fontSize: '12px', color: 'var(--muted)', marginBottom: '6px' }}>
+                            时段内不提醒，悬浮球保持正常颜色。结束时间早于开始时间表示跨过午夜，两者相同表示全天。托盘和悬浮球菜单中可临时开启免打扰
+                        </div>
+                        {(config.quiet_hours || []).map((period: any, i: number) => {
+                            const update = (changes: any) => {
+                                const periods = [...(config.quiet_hours || [])];
+                                periods[i] = {...period, ...changes};
+                                setConfig({...config, quiet_hours: periods});
+                            };
+                            const days: number[] = period.days || [];
+                            return (
+                                <div key={i} style={{ display: 'flex', gap: '6px', alignItems: 'center', marginBottom: '6px' }}>
+                                    {['日', '一', '二', '三', '四', '五', '六'].map((name, day) => (
+                                        <button
+                                            key={day}
+                                            onClick={() => update({days: days.includes(day) ? days.filter(d => d !== day) : [...days, day].sort()})}
+                                            title="都不选表示每天"
+                                            style={{ width: '28px', padding: '4px 0', borderRadius: '4px', cursor: 'pointer', border: '1px solid var(--border)', background: days.includes(day) ? 'var(--accent)' : 'var(--button)', color: days.includes(day) ? 'white' : 'var(--text)' }}
+                                        >
+                                            {name}
+                                        </button>
+                                    ))}
+                                    <input type="time" value={period.start} onChange={e => update({start: e.target.value})}
+                                        style={{ padding: '4px', borderRadius: '4px', border: '1px solid var(--border)', color: 'var(--text)', background: 'var(--panel)' }} />
+                                    <span style={{ color: 'var(--muted)' }}>至</span>
+                                    <input type="time" value={period.end} onChange={e => update({end: e.target.value})}
+                                        style={{ padding: '4px', borderRadius: '4px', border: '1px solid var(--border)', color: 'var(--text)', background: 'var(--panel)' }} />
+                                    <button
+                                        onClick={() => setConfig({...config, quiet_hours: (config.quiet_hours || []).filter((_: any, j: number) => j !== i)})}
+                                        style={{ padding: '4px 10px', background: '#e74c3c', color: 'white', border: 'none', borderRadius: '4px', cursor: 'pointer' }}
+                                    >
+                                        删除
+                                    </button>
+                                </div>
+                            );
+                        })}
+                        <div style={{ display: 'flex', gap: '10px' }}>
+                            {[
+                                ['添加夜间时段', {days: [], start: '22:00', end: '08:00'}],
+                                ['添加周末全天', {days: [0, 6], start: '00:00', end: '00:00'}],
+                            ].map(([label, period]: any) => (
+                                <button
+                                    key={label}
+                                    onClick={() => setConfig({...config, quiet_hours: [...(config.quiet_hours || []), period]})}
+                                    style={{ padding: '6px 12px', background: 'var(--button)', border: '1px solid var(--border)', borderRadius: '4px', cursor: 'pointer', color: 'var(--text)' }}
+                                >
+                                    {label}
+                                </button>
+                            ))}
+                        </div>
+                    </div>
+
+                    <div style={{ marginBottom: '20px' }}>
+                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: 'var(--text)' }}>忙碌时免打扰</label>
+                        <label style={{ display: 'flex', alignItems: 'center', gap: '10px', cursor: 'pointer', color: 'var(--text)', marginBottom: '6px' }}>
+                            <input
+                                type="checkbox"
+                                checked={config.busy_fullscreen || false}
+                                onChange={e => setConfig({...config, busy_fullscreen: e.target.checked})}
+                                style={{ transform: 'scale(1.2)' }}
+                            />
+                            <span>全屏应用（视频、游戏、放映幻灯片）在前台时</span>
+                        </label>
+                        <label style={{ display: 'flex', alignItems: 'center', gap: '10px', cursor: 'pointer', color: 'var(--text)', marginBottom: '6px' }}>
+                            <input
+                                type="checkbox"
+                                checked={config.busy_presentation || false}
+                                onChange={e => setConfig({...config, busy_presentation: e.target.checked})}
+                                style={{ transform: 'scale(1.2)' }}
+                            />
+                            <span>Windows 演示模式开启时</span>
+                        </label>
+                        <div style={{ display: 'flex', gap: '10px', alignItems: 'center', margin: '6px 0' }}>
+                            <span style={{ fontSize: '14px', color: 'var(--text)' }}>悬浮球</span>
+                            <select
+                                value={config.busy_ball_action || 'hide'}
+                                onChange={e => setConfig({...config, busy_ball_action: e.target.value})}
+                                style={{ padding: '6px', borderRadius: '4px', border: '1px solid var(--border)', width: '200px', color: 'var(--text)', background: 'var(--panel)' }}
+                            >
+                                <option value="hide">隐藏</option>
+                                <option value="lower">取消置顶</option>
+                                <option value="none">保持不变</option>
+                            </select>
+                        </div>
+                        <label style={{ display: 'flex', alignItems: 'center', gap: '10px', cursor: 'pointer', color: 'var(--text)', marginBottom: '6px' }}>
+                            <input
+                                type="checkbox"
+                                checked={config.queue_reminders || false}
+                                onChange={e => setConfig({...config, queue_reminders: e.target.checked})}
+                                style={{ transform: 'scale(1.2)' }}
+                            />
+                            <span>暂存期间的提醒，空闲后再提醒</span>
+                        </label>
+                    </div>
+
+                    <div style={{ marginBottom: '20px' }}>
+                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: 'var(--text)' }}>悬浮球自定义图片</label>
+                        <div style={{ display: 'flex', gap: '10px', alignItems: 'center' }}>
+                            {iconPreview ? (
+                                <img src={iconPreview} style={{ width: '40px', height: '40px', borderRadius: '50%', objectFit: 'cover', border: '1px solid var(--border)' }} />
+                            ) : null}
+                            <span style={{ flex: 1, color: config.custom_icon_path ? 'var(--text)' : 'var(--muted)' }}>
+                                {config.custom_icon_path ? '已选择图片' : '未选择图片 (默认显示文字)'}
+                            </span>
+                            <button onClick={handleSelectImage} style={{ padding: '8px 15px', background: 'var(--button)', border: '1px solid var(--border)', borderRadius: '4px', cursor: 'pointer', color: 'var(--text)' }}>
                                 选择...
                             </button>
                             {config.custom_icon_path && (
@@ -312,35 +952,176 @@ export default function Main() {
                                 </button>
                             )}
                         </div>
+                        {iconError && <div style={{ color: '#e74c3c', fontSize: '12px', marginTop: '5px' }}>{iconError}</div>}
                     </div>
 
                     <div style={{ marginBottom: '20px' }}>
-                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: '#333' }}>悬浮球颜色 (正常状态)</label>
+                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: 'var(--text)' }}>悬浮球颜色 (正常状态)</label>
                          <div style={{ display: 'flex', gap: '10px', alignItems: 'center' }}>
                             <input 
                                 type="color"
                                 value={config.edge_light_color || '#3498db'}
-                                onChange={e => setConfig({...config, edge_light_color: e.target.value})}
+                                onChange={e => setAppearance({edge_light_color: e.target.value})}
                                 style={{ width: '50px', height: '30px', padding: 0, border: 'none', background: 'none' }}
                             />
-                            <span style={{ fontSize: '12px', color: '#7f8c8d' }}>设置悬浮球在正常状态下的背景颜色（自定义图片模式下为边框颜色）</span>
+                            <span style={{ fontSize: '12px', color: 'var(--muted)' }}>设置悬浮球在正常状态下的背景颜色（自定义图片模式下为边框颜色）</span>
                         </div>
                     </div>
 
                     <div style={{ marginBottom: '20px' }}>
-                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: '#333' }}>悬浮球边缘背光颜色 (提醒状态)</label>
+                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: 'var(--text)' }}>悬浮球边缘背光颜色 (提醒状态)</label>
                          <div style={{ display: 'flex', gap: '10px', alignItems: 'center' }}>
                             <input 
                                 type="color"
                                 value={config.reminder_color || '#e74c3c'}
-                                onChange={e => setConfig({...config, reminder_color: e.target.value})}
+                                onChange={e => setAppearance({reminder_color: e.target.value})}
                                 style={{ width: '50px', height: '30px', padding: 0, border: 'none', background: 'none' }}
                             />
                         </div>
                     </div>
                     
+                    <div style={{ marginBottom: '20px' }}>
+                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: 'var(--text)' }}>全局快捷键</label>
+                        {[
+                            ['quick_add', '快速添加'],
+                            ['toggle_main', '显示/隐藏主界面'],
+                            ['toggle_ball', '显示/隐藏悬浮球'],
+                            ['complete_urgent', '完成最紧急任务'],
+                            ['click_through', '悬浮球鼠标穿透'],
+                        ].map(([key, label]) => (
+                            <div key={key} style={{ display: 'flex', gap: '10px', alignItems: 'center', marginBottom: '6px' }}>
+                                <span style={{ width: '130px', fontSize: '14px', color: 'var(--text)' }}>{label}</span>
+                                <input
+                                    type="text"
+                                    value={(config.hotkeys || {})[key] || ''}
+                                    onChange={e => setConfig({...config, hotkeys: {...(config.hotkeys || {}), [key]: e.target.value}})}
+                                    placeholder="例如 Ctrl+Alt+N，留空禁用"
+                                    style={{ width: '200px', padding: '6px', borderRadius: '4px', border: '1px solid var(--border)', color: 'var(--text)', background: 'var(--panel)' }}
+                                />
+                            </div>
+                        ))}
+                    </div>
+
+                    <div style={{ marginBottom: '20px' }}>
+                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: 'var(--text)' }}>悬浮球计数</label>
+                        <select
+                            value={config.ball_count_mode || 'active'}
+                            onChange={e => setConfig({...config, ball_count_mode: e.target.value})}
+                            style={{ padding: '8px', borderRadius: '4px', border: '1px solid var(--border)', width: '200px', color: 'var(--text)', background: 'var(--panel)' }}
+                        >
+                            <option value="active">当前清单</option>
+                            <option value="combined">所有清单合计</option>
+                        </select>
+                    </div>
+
+                    <div style={{ marginBottom: '20px' }}>
+                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: 'var(--text)' }}>专注计时</label>
+                        <div style={{ display: 'flex', gap: '10px', alignItems: 'center' }}>
+                            <input
+                                type="number"
+                                min="1"
+                                max="180"
+                                value={config.focus_work_minutes ?? 25}
+                                onChange={e => setConfig({...config, focus_work_minutes: Number(e.target.value)})}
+                                style={{ width: '80px', padding: '6px', borderRadius: '4px', border: '1px solid var(--border)', color: 'var(--text)', background: 'var(--panel)' }}
+                            />
+                            <span style={{ fontSize: '12px', color: 'var(--muted)' }}>分钟专注，然后休息</span>
+                            <input
+                                type="number"
+                                min="0"
+                                max="60"
+                                value={config.focus_break_minutes ?? 5}
+                                onChange={e => setConfig({...config, focus_break_minutes: Number(e.target.value)})}
+                                style={{ width: '80px', padding: '6px', borderRadius: '4px', border: '1px solid var(--border)', color: 'var(--text)', background: 'var(--panel)' }}
+                            />
+                            <span style={{ fontSize: '12px', color: 'var(--muted)' }}>分钟</span>
+                        </div>
+                    </div>
+
+                    <div style={{ marginBottom: '20px' }}>
+                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: 'var(--text)' }}>工时记录</label>
+                        <div style={{ display: 'flex', gap: '10px', alignItems: 'center' }}>
+                            <input
+                                type="number"
+                                min="0"
+                                max="240"
+                                value={config.idle_minutes ?? 5}
+                                onChange={e => setConfig({...config, idle_minutes: Number(e.target.value)})}
+                                style={{ width: '80px', padding: '6px', borderRadius: '4px', border: '1px solid var(--border)', color: 'var(--text)', background: 'var(--panel)' }}
+                            />
+                            <span style={{ fontSize: '12px', color: 'var(--muted)' }}>分钟无操作后暂停计时（0 表示不暂停）</span>
+                        </div>
+                    </div>
+
+                    <div style={{ marginBottom: '20px' }}>
+                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: 'var(--text)' }}>自动归档</label>
+                        <div style={{ display: 'flex', gap: '10px', alignItems: 'center' }}>
+                            <input
+                                type="number"
+                                min="0"
+                                max="3650"
+                                value={config.archive_after_days ?? 30}
+                                onChange={e => setConfig({...config, archive_after_days: Number(e.target.value)})}
+                                style={{ width: '80px', padding: '6px', borderRadius: '4px', border: '1px solid var(--border)', color: 'var(--text)', background: 'var(--panel)' }}
+                            />
+                            <span style={{ fontSize: '12px', color: 'var(--muted)' }}>天后将已完成任务移入历史记录（0 表示不归档）</span>
+                        </div>
+                    </div>
+
+                    <div style={{ marginBottom: '20px' }}>
+                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: 'var(--text)' }}>日志级别</label>
+                        <select
+                            value={config.log_level || 'info'}
+                            onChange={e => setConfig({...config, log_level: e.target.value})}
+                            style={{ padding: '8px', borderRadius: '4px', border: '1px solid var(--border)', width: '200px', color: 'var(--text)', background: 'var(--panel)' }}
+                        >
+                            <option value="debug">调试 (debug)</option>
+                            <option value="info">信息 (info)</option>
+                            <option value="warn">警告 (warn)</option>
+                            <option value="error">错误 (error)</option>
+                        </select>
+                    </div>
+
+                    <div style={{ marginBottom: '20px' }}>
+                        <label style={{ display: 'block', marginBottom: '8px', fontWeight: 'bold', color: 'var(--text)' }}>
+                            数据加密 ({encStatus.enabled ? '已启用' : '未启用'})
+                        </label>
+                        <div style={{ display: 'flex', gap: '10px', alignItems: 'center', flexWrap: 'wrap' }}>
+                            <input
+                                type="password"
+                                value={passphrase}
+                                onChange={e => setPassphrase(e.target.value)}
+                                placeholder={encStatus.enabled ? '当前密码' : '新密码 (至少 8 个字符)'}
+                                style={{ width: '180px', padding: '6px', borderRadius: '4px', border: '1px solid var(--border)', color: 'var(--text)', background: 'var(--panel)' }}
+                            />
+                            {encStatus.enabled ? (
+                                <>
+                                    <input
+                                        type="password"
+                                        value={newPassphrase}
+                                        onChange={e => setNewPassphrase(e.target.value)}
+                                        placeholder="新密码"
+                                        style={{ width: '180px', padding: '6px', borderRadius: '4px', border: '1px solid var(--border)', color: 'var(--text)', background: 'var(--panel)' }}
+                                    />
+                                    <button onClick={() => handleEncryption(() => ChangePassphrase(passphrase, newPassphrase), '密码已修改')} style={{ padding: '6px 15px', background: 'var(--button)', border: '1px solid var(--border)', borderRadius: '4px', cursor: 'pointer', color: 'var(--text)' }}>
+                                        修改密码
+                                    </button>
+                                    <button onClick={() => handleEncryption(() => DisableEncryption(passphrase), '已关闭加密')} style={{ padding: '6px 15px', background: '#e74c3c', color: 'white', border: 'none', borderRadius: '4px', cursor: 'pointer' }}>
+                                        关闭加密
+                                    </button>
+                                </>
+                            ) : (
+                                <button onClick={() => handleEncryption(() => EnableEncryption(passphrase), '已启用加密')} style={{ padding: '6px 15px', background: 'var(--button)', border: '1px solid var(--border)', borderRadius: '4px', cursor: 'pointer', color: 'var(--text)' }}>
+                                    启用加密
+                                </button>
+                            )}
+                        </div>
+                        <span style={{ fontSize: '12px', color: 'var(--muted)' }}>加密后每次启动需输入密码，忘记密码将无法恢复数据</span>
+                        {encMsg && <div style={{ marginTop: '6px', color: encMsg.includes('失败') ? '#e74c3c' : '#2ecc71' }}>{encMsg}</div>}
+                    </div>
+
                     <div style={{ display: 'flex', gap: '10px', marginTop: '30px', alignItems: 'center' }}>
-                        <button onClick={handleSaveConfig} style={{ padding: '10px 30px', background: '#3498db', color: 'white', border: 'none', borderRadius: '4px', cursor: 'pointer', fontSize: '16px' }}>
+                        <button onClick={handleSaveConfig} style={{ padding: '10px 30px', background: 'var(--accent)', color: 'white', border: 'none', borderRadius: '4px', cursor: 'pointer', fontSize: '16px' }}>
                             保存所有设置
                         </button>
                         {saveMsg && (
@@ -0,0 +1,34 @@
+import { useState } from 'react';
+import { Reminder, describeReminder, reminderUnits } from '../reminders';
+
+const inputStyle = { padding: '4px', borderRadius: '4px', border: '1px solid var(--border)' };
+const chipStyle = { display: 'inline-flex', alignItems: 'center', gap: '2px', padding: '2px 6px', borderRadius: '10px', background: 'var(--highlight)', fontSize: '12px' };
+
+// Editor for a todo's reminder list: relative ones ("30 分钟前") and ones
+// at a fixed time
+export default function Reminders({ value, onChange }: { value: Reminder[]; onChange: (v: Reminder[]) => void }) {
+    const [before, setBefore] = useState(1);
+    const [unit, setUnit] = useState('days');
+    const [at, setAt] = useState('');
+
+    const add = (r: Reminder) => onChange([...value, r]);
+
+    return (
+        <div style={{ display: 'flex', flexWrap: 'wrap', alignItems: 'center', gap: '6px', fontSize: '14px' }}>
+            {value.length === 0 && <span style={{ color: 'var(--muted)', fontSize: '12px' }}>不提醒</span>}
+            {value.map((r, i) => (
+                <span key={i} style={chipStyle}>
+                    🔔 {describeReminder(r)}
+                    <button onClick={() => onChange(value.filter((_, j) => j !== i))} title="删除提醒" style={{ background: 'none', border: 'none', cursor: 'pointer', padding: '0 2px' }}>✕</button>
+                </span>
+            ))}
+            <input type="number" min={0} value={before} onChange={e => setBefore(Math.max(0, Number(e.target.value)))} style={{ ...inputStyle, width: '50px' }} />
+            <select value={unit} onChange={e => setUnit(e.target.value)} style={inputStyle}>
+                {reminderUnits.map(([u, label]) => <option key={u} value={u}>{label}前</option>)}
+            </select>
+            <button onClick={() => add({ before, unit })} style={{ ...inputStyle, cursor: 'pointer' }}>+</button>
+            <input type="datetime-local" value={at} onChange={e => setAt(e.target.value)} title="在指定时间提醒" style={inputStyle} />
+            <button onClick={() => { if (at) { add({ at: new Date(at).toISOString() }); setAt(''); } }} style={{ ...inputStyle, cursor: 'pointer' }}>+</button>
+        </div>
+    );
+}
@@ -0,0 +1,157 @@
+import { useEffect, useState } from 'react';
+import { ExportTimesheet, ExportWeeklyReport, GetStats, GetTimeTotals, GetWeeklyReport } from '../../wailsjs/go/main/App';
+import { errorMessage } from '../errors';
+import { formatDuration } from '../format';
+
+const RANGES = [
+    { value: 'week', label: '本周' },
+    { value: 'last_week', label: '上周' },
+    { value: 'month', label: '本月' },
+    { value: 'last_month', label: '上月' },
+    { value: '30d', label: '最近 30 天' },
+    { value: 'year', label: '今年' },
+    { value: 'all', label: '全部' },
+];
+
+// Productivity figures and the weekly report
+export default function Stats() {
+    const [range, setRange] = useState('week');
+    const [summary, setSummary] = useState<any>(null);
+    const [week, setWeek] = useState('');
+    const [report, setReport] = useState('');
+    const [error, setError] = useState('');
+    const [message, setMessage] = useState('');
+
+    // Timesheet
+    const [timeFrom, setTimeFrom] = useState('');
+    const [timeTo, setTimeTo] = useState('');
+    const [totals, setTotals] = useState<any>(null);
+
+    useEffect(() => {
+        setError('');
+        GetStats(range).then(setSummary).catch(e => setError(errorMessage(e)));
+    }, [range]);
+
+    useEffect(() => {
+        GetTimeTotals(timeFrom, timeTo).then(setTotals).catch(e => setError(errorMessage(e)));
+    }, [timeFrom, timeTo]);
+
+    const exportTimesheet = async () => {
+        setError('');
+        setMessage('');
+        try {
+            const path = await ExportTimesheet(timeFrom, timeTo);
+            if (path) setMessage('已导出到 ' + path);
+        } catch (e) {
+            setError(errorMessage(e));
+        }
+    };
+
+    const preview = async () => {
+        setError('');
+        try {
+            setReport(await GetWeeklyReport(week, 'markdown'));
+        } catch (e) {
+            setError(errorMessage(e));
+        }
+    };
+
+    const exportReport = async (format: string) => {
+        setError('');
+        setMessage('');
+        try {
+            const path = await ExportWeeklyReport(week, format);
+            if (path) setMessage('已导出到 ' + path);
+        } catch (e) {
+            setError(errorMessage(e));
+        }
+    };
+
+    const inputStyle = { padding: '8px', borderRadius: '4px', border: '1px solid var(--border)', color: 'var(--text)', background: 'var(--panel)' };
+    const buttonStyle = { padding: '8px 15px', background: 'var(--button)', border: '1px solid var(--border)', borderRadius: '4px', cursor: 'pointer', color: 'var(--text)' };
+    const cardStyle = { flex: '1 1 120px', background: 'var(--panel-alt)', borderRadius: '6px', padding: '12px', textAlign: 'center' as const };
+    const figure = (label: string, value: any) => (
+        <div style={cardStyle}>
+            <div style={{ fontSize: '22px', fontWeight: 'bold' }}>{value}</div>
+            <div style={{ fontSize: '12px', color: 'var(--muted)' }}>{label}</div>
+        </div>
+    );
+    const maxDay = Math.max(1, ...(summary?.per_day || []).map((d: any) => d.completed));
+
+    return (
+        <div style={{ background: 'var(--panel)', color: 'var(--text)', padding: '20px', borderRadius: '8px', boxShadow: '0 2px 5px rgba(0,0,0,0.05)', textAlign: 'left' }}>
+            <h2 style={{ marginBottom: '20px', borderBottom: '1px solid var(--border)', paddingBottom: '10px', color: 'var(--text)' }}>统计</h2>
+            <div style={{ display: 'flex', gap: '10px', alignItems: 'center', marginBottom: '15px' }}>
+                <select value={range} onChange={e => setRange(e.target.value)} style={inputStyle}>
+                    {RANGES.map(r => <option key={r.value} value={r.value}>{r.label}</option>)}
+                </select>
+                {summary && <span style={{ color: 'var(--muted)', fontSize: '12px' }}>{summary.from} ~ {summary.to}</span>}
+            </div>
+            {error && <div style={{ color: '#e74c3c', marginBottom: '10px' }}>{error}</div>}
+
+            {summary && (
+                <>
+                    <div style={{ display: 'flex', gap: '10px', flexWrap: 'wrap', marginBottom: '20px' }}>
+                        {figure('完成', summary.completed)}
+                        {figure('新建', summary.created)}
+                        {figure('按时完成率', Math.round(summary.on_time_rate * 100) + '%')}
+                        {figure('平均完成用时', summary.avg_lead_time_hours.toFixed(1) + ' 小时')}
+                        {figure('连续完成天数', `${summary.current_streak} / ${summary.longest_streak}`)}
+                        {figure('逾期未完成', summary.overdue_backlog)}
+                    </div>
+                    <div style={{ maxHeight: '200px', overflowY: 'auto', marginBottom: '20px' }}>
+                        {(summary.per_day || []).map((d: any) => (
+                            <div key={d.date} style={{ display: 'flex', alignItems: 'center', gap: '8px', fontSize: '12px', marginBottom: '2px' }}>
+                                <span style={{ width: '80px', color: 'var(--muted)' }}>{d.date}</span>
+                                <div style={{ height: '10px', width: `${d.completed / maxDay * 60}%`, background: '#3498db', borderRadius: '2px' }} />
+                                <span>{d.completed}</span>
+                            </div>
+                        ))}
+                    </div>
+                </>
+            )}
+
+            <h3 style={{ marginBottom: '10px' }}>周报</h3>
+            <div style={{ display: 'flex', gap: '10px', alignItems: 'center', flexWrap: 'wrap', marginBottom: '10px' }}>
+                <input type="week" value={week} onChange={e => setWeek(e.target.value)} style={inputStyle} />
+                <button onClick={preview} style={buttonStyle}>预览</button>
+                <button onClick={() => exportReport('markdown')} style={buttonStyle}>导出 Markdown</button>
+                <button onClick={() => exportReport('html')} style={buttonStyle}>导出 HTML</button>
+                <span style={{ fontSize: '12px', color: 'var(--muted)' }}>不选则为本周</span>
+            </div>
+            {report && (
+                <pre style={{ background: 'var(--panel-alt)', padding: '12px', borderRadius: '6px', whiteSpace: 'pre-wrap', fontSize: '12px' }}>{report}</pre>
+            )}
+
+            <h3 style={{ margin: '20px 0 10px' }}>工时</h3>
+            <div style={{ display: 'flex', gap: '10px', alignItems: 'center', flexWrap: 'wrap', marginBottom: '10px' }}>
+                <input type="date" value={timeFrom} onChange={e => setTimeFrom(e.target.value)} style={inputStyle} />
+                <span>至</span>
+                <input type="date" value={timeTo} onChange={e => setTimeTo(e.target.value)} style={inputStyle} />
+                <button onClick={exportTimesheet} style={buttonStyle}>导出 CSV</button>
+                {totals && <span>合计 {formatDuration(totals.seconds)}</span>}
+            </div>
+            {totals && (
+                <div style={{ display: 'flex', gap: '20px', flexWrap: 'wrap', fontSize: '12px' }}>
+                    {[
+                        { title: '按任务', rows: (totals.by_todo || []).map((t: any) => [t.title || '(已删除)', t.seconds]) },
+                        { title: '按标签', rows: (totals.by_tag || []).map((t: any) => [t.tag ? '#' + t.tag : '(无标签)', t.seconds]) },
+                        { title: '按日期', rows: (totals.by_day || []).map((d: any) => [d.date, d.seconds]) },
+                    ].map(group => (
+                        <div key={group.title} style={{ flex: '1 1 180px' }}>
+                            <div style={{ fontWeight: 'bold', marginBottom: '5px' }}>{group.title}</div>
+                            {group.rows.map(([label, seconds]: any[], i: number) => (
+                                <div key={i} style={{ display: 'flex', justifyContent: 'space-between', borderBottom: '1px solid var(--divider)', padding: '3px 0' }}>
+                                    <span>{label}</span>
+                                    <span>{formatDuration(seconds)}</span>
+                                </div>
+                            ))}
+                            {group.rows.length === 0 && <div style={{ color: '#95a5a6' }}>无记录</div>}
+                        </div>
+                    ))}
+                </div>
+            )}
+            {message && <div style={{ color: '#27ae60', marginTop: '10px' }}>{message}</div>}
+        </div>
+    );
+}
@@ -0,0 +1,15 @@
+// Errors rejected by Go bindings arrive as {code, message, field?, detail?}
+// (see apperr.Format). Older or non-Go errors may still be plain strings.
+export interface AppError {
+    code: 'validation' | 'not_found' | 'io' | 'conflict' | 'locked' | 'internal';
+    message: string;
+    field?: string;
+    detail?: string;
+}
+
+export function errorMessage(e: unknown): string {
+    if (e && typeof e === 'object' && 'message' in e) {
+        return String((e as AppError).message);
+    }
+    return String(e);
+}
@@ -0,0 +1,63 @@
+// formatCountdown shows the remaining seconds of a focus timer as mm:ss
+export function formatCountdown(seconds: number): string {
+    const m = Math.floor(seconds / 60);
+    const s = seconds % 60;
+    return `${String(m).padStart(2, '0')}:${String(s).padStart(2, '0')}`;
+}
+
+// formatDuration shows tracked seconds as h:mm:ss
+export function formatDuration(seconds: number): string {
+    const total = Math.max(0, Math.floor(seconds));
+    const h = Math.floor(total / 3600);
+    const m = Math.floor(total / 60) % 60;
+    const s = total % 60;
+    return `${h}:${String(m).padStart(2, '0')}:${String(s).padStart(2, '0')}`;
+}
+
+// formatDueIn shows the time left to a due date compactly: 3天, 5小时, 25分
+export function formatDueIn(seconds: number): string {
+    if (seconds >= 86400) return `${Math.floor(seconds / 86400)}天`;
+    if (seconds >= 3600) return `${Math.floor(seconds / 3600)}小时`;
+    return `${Math.max(1, Math.ceil(seconds / 60))}分`;
+}
+
+// localTimeZone is the computer's IANA zone, sent with new due dates
+export function localTimeZone(): string {
+    return Intl.DateTimeFormat().resolvedOptions().timeZone || '';
+}
+
+// formatDue shows a todo's due date on the clock of the zone it was set
+// in, naming the zone if that isn't the computer's. All-day todos are due
+// at the midnight ending their day, so show the day before it.
+export function formatDue(t: { due_date: string; time_zone?: string; all_day?: boolean }): string {
+    const zone = t.time_zone || undefined;
+    const due = new Date(t.due_date);
+    let text = t.all_day
+        ? new Date(due.getTime() - 1).toLocaleDateString(undefined, { timeZone: zone }) + ' 全天'
+        : due.toLocaleString(undefined, { timeZone: zone });
+    if (zone && zone !== localTimeZone()) text += ` (${zone})`;
+    return text;
+}
+
+// isDeferred tells whether a todo's start date is still to come
+export function isDeferred(t: { defer_until?: string }): boolean {
+    return !!t.defer_until && new Date(t.defer_until).getTime() > Date.now();
+}
+
+// formatStart shows the day a deferred todo starts, in the todo's zone
+export function formatStart(t: { defer_until?: string; time_zone?: string }): string {
+    return t.defer_until ? new Date(t.defer_until).toLocaleDateString(undefined, { timeZone: t.time_zone || undefined }) : '';
+}
+
+// dueInput turns a todo's due date back into what AddTodo and UpdateTodo
+// take: the wall-clock time in its zone, or the day if it is all-day
+export function dueInput(t: { due_date: string; time_zone?: string; all_day?: boolean }): string {
+    const due = new Date(t.due_date);
+    if (t.all_day) due.setTime(due.getTime() - 1);
+    // Swedish dates are ISO-like: 2026-03-08 09:00
+    const text = due.toLocaleString('sv-SE', {
+        timeZone: t.time_zone || undefined,
+        year: 'numeric', month: '2-digit', day: '2-digit', hour: '2-digit', minute: '2-digit', hour12: false,
+    });
+    return t.all_day ? text.slice(0, 10) : text.replace(' ', 'T');
+}
@@ -0,0 +1,61 @@
+// A todo reminder, as models.Reminder: `before` units ahead of the due
+// date, or at the instant `at`
+export interface Reminder {
+    before?: number;
+    unit?: string; // 'minutes', 'hours' or 'days'
+    at?: string;
+}
+
+export const reminderUnits: [string, string][] = [['minutes', '分钟'], ['hours', '小时'], ['days', '天']];
+
+interface Remindable {
+    due_date: string;
+    completed?: boolean;
+    defer_until?: string;
+    reminders?: Reminder[];
+    reminder_days?: number;
+}
+
+// reminderList returns a todo's reminders. Todos saved by older versions
+// still have reminder_days until the backend migrates them.
+export function reminderList(t: Remindable): Reminder[] {
+    if (!t.reminders?.length && (t.reminder_days || 0) > 0) {
+        return [{ before: t.reminder_days, unit: 'days' }];
+    }
+    return t.reminders || [];
+}
+
+// reminderTime returns when r fires, in milliseconds. Days are calendar
+// days on this computer's clock, which is the todo's zone unless it was
+// set elsewhere.
+export function reminderTime(r: Reminder, dueDate: string): number {
+    if (r.at) return new Date(r.at).getTime();
+    const due = new Date(dueDate);
+    const n = r.before || 0;
+    if (r.unit === 'days') {
+        due.setDate(due.getDate() - n);
+        return due.getTime();
+    }
+    return due.getTime() - n * (r.unit === 'hours' ? 3600e3 : 60e3);
+}
+
+// reminderFired tells whether a pending todo, due in the future, has had
+// one of its reminders fire: the ball and list show it as upcoming.
+// Mirrors TodoItem.IsUpcoming.
+export function reminderFired(t: Remindable, now: number): boolean {
+    const due = new Date(t.due_date).getTime();
+    if (t.completed || isNaN(due) || due < now) return false;
+    if (t.defer_until && new Date(t.defer_until).getTime() > now) return false;
+    return reminderList(t).some(r => reminderTime(r, t.due_date) <= now);
+}
+
+// describeReminder shows a reminder briefly: 1天前, 30分钟前 or 11-01 09:00
+export function describeReminder(r: Reminder): string {
+    if (r.at) {
+        const d = new Date(r.at);
+        return `${String(d.getMonth() + 1).padStart(2, '0')}-${String(d.getDate()).padStart(2, '0')} ${String(d.getHours()).padStart(2, '0')}:${String(d.getMinutes()).padStart(2, '0')}`;
+    }
+    if (!r.before) return '到期时';
+    const unit = reminderUnits.find(([u]) => u === r.unit)?.[1] || r.unit;
+    return `${r.before}${unit}前`;
+}
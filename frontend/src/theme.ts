@@ -0,0 +1,21 @@
+// Applies the colour mode and theme colour of the config to the page,
+// through the CSS variables in style.css.
+
+const darkQuery = window.matchMedia('(prefers-color-scheme: dark)');
+let colorMode = 'light';
+
+function update() {
+    const dark = colorMode === 'dark' || (colorMode === 'system' && darkQuery.matches);
+    document.documentElement.dataset.theme = dark ? 'dark' : 'light';
+}
+
+// "system" follows Windows while the window is open
+darkQuery.addEventListener('change', update);
+
+export function applyTheme(config: any) {
+    colorMode = config.color_mode || 'light';
+    if (config.theme_color) {
+        document.documentElement.style.setProperty('--accent', config.theme_color);
+    }
+    update();
+}
@@ -1,37 +1,124 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {focus} from '../models';
+import {icons} from '../models';
+import {main} from '../models';
 import {models} from '../models';
+import {stats} from '../models';
+import {tracking} from '../models';
 
-export function AddTodo(arg1:string,arg2:string,arg3:number):Promise<void>;
+export function AddTodo(arg1:string,arg2:string,arg3:string,arg4:boolean,arg5:Array<models.Reminder>):Promise<void>;
+
+export function ApplyTheme(arg1:string):Promise<models.AppConfig>;
+
+export function ChangePassphrase(arg1:string,arg2:string):Promise<void>;
 
 export function CheckDocking():Promise<string>;
 
+export function CreateList(arg1:string,arg2:string):Promise<models.TodoList>;
+
+export function DeferTodo(arg1:string,arg2:string):Promise<void>;
+
+export function DeleteList(arg1:string):Promise<void>;
+
+export function DeleteTheme(arg1:string):Promise<void>;
+
 export function DeleteTodo(arg1:string):Promise<void>;
 
+export function DisableEncryption(arg1:string):Promise<void>;
+
 export function Dock(arg1:string):Promise<void>;
 
+export function EnableEncryption(arg1:string):Promise<void>;
+
+export function ExportTheme(arg1:string):Promise<string>;
+
+export function ExportTimesheet(arg1:string,arg2:string):Promise<string>;
+
+export function ExportWeeklyReport(arg1:string,arg2:string):Promise<string>;
+
 export function FullQuit():Promise<void>;
 
+export function GetArchive(arg1:string,arg2:string,arg3:string,arg4:number,arg5:number):Promise<models.ArchivePage>;
+
+export function GetBallGeometry():Promise<main.BallGeometry>;
+
+export function GetBallIcon():Promise<string>;
+
+export function GetBallTodos():Promise<Array<models.TodoItem>>;
+
 export function GetConfig():Promise<models.AppConfig>;
 
-export function GetImageBase64(arg1:string):Promise<string>;
+export function GetEncryptionStatus():Promise<models.EncryptionStatus>;
+
+export function GetFocusSessions(arg1:string):Promise<Array<focus.Session>>;
+
+export function GetFocusStatus():Promise<focus.Status>;
+
+export function GetImageBase64(arg1:string):Promise<icons.Asset>;
 
 export function GetMode():Promise<string>;
 
+export function GetQuietStatus():Promise<main.QuietStatus>;
+
+export function GetStats(arg1:string):Promise<stats.Summary>;
+
+export function GetTimeEntries(arg1:string):Promise<Array<tracking.Entry>>;
+
+export function GetTimeTotals(arg1:string,arg2:string):Promise<tracking.Totals>;
+
 export function GetTodos():Promise<Array<models.TodoItem>>;
 
+export function GetTrackingStatus():Promise<tracking.Status>;
+
+export function GetWeeklyReport(arg1:string,arg2:string):Promise<string>;
+
+export function ImportTheme():Promise<models.Theme>;
+
+export function ListLists():Promise<Array<models.TodoList>>;
+
+export function ListThemes():Promise<Array<models.Theme>>;
+
 export function OpenMain():Promise<void>;
 
+export function PauseFocus():Promise<focus.Status>;
+
+export function PinTimeZones(arg1:string):Promise<void>;
+
+export function QueryTodos(arg1:string):Promise<Array<models.TodoItem>>;
+
+export function QuickAdd(arg1:string,arg2:string):Promise<models.TodoItem>;
+
+export function ResumeFocus():Promise<focus.Status>;
+
+export function SaveTheme(arg1:models.Theme):Promise<void>;
+
 export function SelectFile():Promise<string>;
 
 export function SetBallMenuState(arg1:boolean):Promise<void>;
 
+export function SetDND(arg1:number):Promise<main.QuietStatus>;
+
 export function SetWindowPosition(arg1:number,arg2:number):Promise<void>;
 
 export function SetWindowSize(arg1:number,arg2:number):Promise<void>;
 
+export function StartFocus(arg1:string):Promise<focus.Status>;
+
+export function StartTracking(arg1:string):Promise<tracking.Status>;
+
+export function StopFocus():Promise<focus.Status>;
+
+export function StopTracking():Promise<tracking.Status>;
+
+export function SwitchList(arg1:string):Promise<void>;
+
 export function ToggleTodo(arg1:string):Promise<void>;
 
 export function Undock(arg1:string):Promise<void>;
 
+export function Unlock(arg1:string):Promise<void>;
+
 export function UpdateConfig(arg1:models.AppConfig):Promise<void>;
+
+export function UpdateTodo(arg1:string,arg2:string,arg3:string,arg4:string,arg5:boolean,arg6:Array<models.Reminder>):Promise<void>;
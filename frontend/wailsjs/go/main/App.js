@@ -2,30 +2,102 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
-export function AddTodo(arg1, arg2, arg3) {
-  return window['go']['main']['App']['AddTodo'](arg1, arg2, arg3);
+export function AddTodo(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['AddTodo'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function ApplyTheme(arg1) {
+  return window['go']['main']['App']['ApplyTheme'](arg1);
+}
+
+export function ChangePassphrase(arg1, arg2) {
+  return window['go']['main']['App']['ChangePassphrase'](arg1, arg2);
 }
 
 export function CheckDocking() {
   return window['go']['main']['App']['CheckDocking']();
 }
 
+export function CreateList(arg1, arg2) {
+  return window['go']['main']['App']['CreateList'](arg1, arg2);
+}
+
+export function DeferTodo(arg1, arg2) {
+  return window['go']['main']['App']['DeferTodo'](arg1, arg2);
+}
+
+export function DeleteList(arg1) {
+  return window['go']['main']['App']['DeleteList'](arg1);
+}
+
+export function DeleteTheme(arg1) {
+  return window['go']['main']['App']['DeleteTheme'](arg1);
+}
+
 export function DeleteTodo(arg1) {
   return window['go']['main']['App']['DeleteTodo'](arg1);
 }
 
+export function DisableEncryption(arg1) {
+  return window['go']['main']['App']['DisableEncryption'](arg1);
+}
+
 export function Dock(arg1) {
   return window['go']['main']['App']['Dock'](arg1);
 }
 
+export function EnableEncryption(arg1) {
+  return window['go']['main']['App']['EnableEncryption'](arg1);
+}
+
+export function ExportTheme(arg1) {
+  return window['go']['main']['App']['ExportTheme'](arg1);
+}
+
+export function ExportTimesheet(arg1, arg2) {
+  return window['go']['main']['App']['ExportTimesheet'](arg1, arg2);
+}
+
+export function ExportWeeklyReport(arg1, arg2) {
+  return window['go']['main']['App']['ExportWeeklyReport'](arg1, arg2);
+}
+
 export function FullQuit() {
   return window['go']['main']['App']['FullQuit']();
 }
 
+export function GetArchive(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['GetArchive'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function GetBallGeometry() {
+  return window['go']['main']['App']['GetBallGeometry']();
+}
+
+export function GetBallIcon() {
+  return window['go']['main']['App']['GetBallIcon']();
+}
+
+export function GetBallTodos() {
+  return window['go']['main']['App']['GetBallTodos']();
+}
+
 export function GetConfig() {
   return window['go']['main']['App']['GetConfig']();
 }
 
+export function GetEncryptionStatus() {
+  return window['go']['main']['App']['GetEncryptionStatus']();
+}
+
+export function GetFocusSessions(arg1) {
+  return window['go']['main']['App']['GetFocusSessions'](arg1);
+}
+
+export function GetFocusStatus() {
+  return window['go']['main']['App']['GetFocusStatus']();
+}
+
 export function GetImageBase64(arg1) {
   return window['go']['main']['App']['GetImageBase64'](arg1);
 }
@@ -34,14 +106,74 @@ export function GetMode() {
   return window['go']['main']['App']['GetMode']();
 }
 
+export function GetQuietStatus() {
+  return window['go']['main']['App']['GetQuietStatus']();
+}
+
+export function GetStats(arg1) {
+  return window['go']['main']['App']['GetStats'](arg1);
+}
+
+export function GetTimeEntries(arg1) {
+  return window['go']['main']['App']['GetTimeEntries'](arg1);
+}
+
+export function GetTimeTotals(arg1, arg2) {
+  return window['go']['main']['App']['GetTimeTotals'](arg1, arg2);
+}
+
 export function GetTodos() {
   return window['go']['main']['App']['GetTodos']();
 }
 
+export function GetTrackingStatus() {
+  return window['go']['main']['App']['GetTrackingStatus']();
+}
+
+export function GetWeeklyReport(arg1, arg2) {
+  return window['go']['main']['App']['GetWeeklyReport'](arg1, arg2);
+}
+
+export function ImportTheme() {
+  return window['go']['main']['App']['ImportTheme']();
+}
+
+export function ListLists() {
+  return window['go']['main']['App']['ListLists']();
+}
+
+export function ListThemes() {
+  return window['go']['main']['App']['ListThemes']();
+}
+
 export function OpenMain() {
   return window['go']['main']['App']['OpenMain']();
 }
 
+export function PauseFocus() {
+  return window['go']['main']['App']['PauseFocus']();
+}
+
+export function PinTimeZones(arg1) {
+  return window['go']['main']['App']['PinTimeZones'](arg1);
+}
+
+export function QueryTodos(arg1) {
+  return window['go']['main']['App']['QueryTodos'](arg1);
+}
+
+export function QuickAdd(arg1, arg2) {
+  return window['go']['main']['App']['QuickAdd'](arg1, arg2);
+}
+
+export function ResumeFocus() {
+  return window['go']['main']['App']['ResumeFocus']();
+}
+
+export function SaveTheme(arg1) {
+  return window['go']['main']['App']['SaveTheme'](arg1);
+}
+
 export function SelectFile() {
   return window['go']['main']['App']['SelectFile']();
 }
@@ -50,6 +182,10 @@ export function SetBallMenuState(arg1) {
   return window['go']['main']['App']['SetBallMenuState'](arg1);
 }
 
+export function SetDND(arg1) {
+  return window['go']['main']['App']['SetDND'](arg1);
+}
+
 export function SetWindowPosition(arg1, arg2) {
   return window['go']['main']['App']['SetWindowPosition'](arg1, arg2);
 }
@@ -58,6 +194,26 @@ export function SetWindowSize(arg1, arg2) {
   return window['go']['main']['App']['SetWindowSize'](arg1, arg2);
 }
 
+export function StartFocus(arg1) {
+  return window['go']['main']['App']['StartFocus'](arg1);
+}
+
+export function StartTracking(arg1) {
+  return window['go']['main']['App']['StartTracking'](arg1);
+}
+
+export function StopFocus() {
+  return window['go']['main']['App']['StopFocus']();
+}
+
+export function StopTracking() {
+  return window['go']['main']['App']['StopTracking']();
+}
+
+export function SwitchList(arg1) {
+  return window['go']['main']['App']['SwitchList'](arg1);
+}
+
 export function ToggleTodo(arg1) {
   return window['go']['main']['App']['ToggleTodo'](arg1);
 }
@@ -66,6 +222,14 @@ export function Undock(arg1) {
   return window['go']['main']['App']['Undock'](arg1);
 }
 
+export function Unlock(arg1) {
+  return window['go']['main']['App']['Unlock'](arg1);
+}
+
 export function UpdateConfig(arg1) {
   return window['go']['main']['App']['UpdateConfig'](arg1);
 }
+
+export function UpdateTodo(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['UpdateTodo'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
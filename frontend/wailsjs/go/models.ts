@@ -1,3 +1,121 @@
+export namespace focus {
+	
+	export class Session {
+	    id: string;
+	    todo_id: string;
+	    list_id: string;
+	    started: string;
+	    ended: string;
+	    seconds: number;
+	    completed: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Session(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.todo_id = source["todo_id"];
+	        this.list_id = source["list_id"];
+	        this.started = source["started"];
+	        this.ended = source["ended"];
+	        this.seconds = source["seconds"];
+	        this.completed = source["completed"];
+	    }
+	}
+	export class Status {
+	    active: boolean;
+	    todo_id: string;
+	    list_id: string;
+	    phase: string;
+	    paused: boolean;
+	    remaining_seconds: number;
+	    total_seconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Status(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.active = source["active"];
+	        this.todo_id = source["todo_id"];
+	        this.list_id = source["list_id"];
+	        this.phase = source["phase"];
+	        this.paused = source["paused"];
+	        this.remaining_seconds = source["remaining_seconds"];
+	        this.total_seconds = source["total_seconds"];
+	    }
+	}
+
+}
+
+export namespace icons {
+	
+	export class Asset {
+	    name: string;
+	    mime: string;
+	    data: string;
+	    key: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Asset(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.mime = source["mime"];
+	        this.data = source["data"];
+	        this.key = source["key"];
+	    }
+	}
+
+}
+
+export namespace main {
+	
+	export class BallGeometry {
+	    width: number;
+	    height: number;
+	    menu_width: number;
+	    menu_height: number;
+	    docked_width: number;
+	    docked_height: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BallGeometry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.width = source["width"];
+	        this.height = source["height"];
+	        this.menu_width = source["menu_width"];
+	        this.menu_height = source["menu_height"];
+	        this.docked_width = source["docked_width"];
+	        this.docked_height = source["docked_height"];
+	    }
+	}
+	export class QuietStatus {
+	    active: boolean;
+	    reason: string;
+	    dnd_until: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new QuietStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.active = source["active"];
+	        this.reason = source["reason"];
+	        this.dnd_until = source["dnd_until"];
+	    }
+	}
+}
+
 export namespace models {
 	
 	export class AppConfig {
@@ -11,6 +129,28 @@ export namespace models {
 	    floating_ball_mode: string;
 	    window_width: number;
 	    window_height: number;
+	    hotkeys: HotkeyConfig;
+	    log_level: string;
+	    active_list: string;
+	    ball_count_mode: string;
+	    archive_after_days: number;
+	    focus_work_minutes: number;
+	    focus_break_minutes: number;
+	    idle_minutes: number;
+	    color_mode: string;
+	    theme_name: string;
+	    ball_size: number;
+	    ball_shape: string;
+	    ball_content: string;
+	    click_through: boolean;
+	    click_through_fullscreen: boolean;
+	    fade_after_seconds: number;
+	    fade_opacity: number;
+	    busy_fullscreen: boolean;
+	    busy_presentation: boolean;
+	    busy_ball_action: string;
+	    queue_reminders: boolean;
+	    quiet_hours: Array<QuietPeriod>;
 	
 	    static createFrom(source: any = {}) {
 	        return new AppConfig(source);
@@ -28,17 +168,144 @@ export namespace models {
 	        this.floating_ball_mode = source["floating_ball_mode"];
 	        this.window_width = source["window_width"];
 	        this.window_height = source["window_height"];
+	        this.hotkeys = this.convertValues(source["hotkeys"], HotkeyConfig);
+	        this.log_level = source["log_level"];
+	        this.active_list = source["active_list"];
+	        this.ball_count_mode = source["ball_count_mode"];
+	        this.archive_after_days = source["archive_after_days"];
+	        this.focus_work_minutes = source["focus_work_minutes"];
+	        this.focus_break_minutes = source["focus_break_minutes"];
+	        this.idle_minutes = source["idle_minutes"];
+	        this.color_mode = source["color_mode"];
+	        this.theme_name = source["theme_name"];
+	        this.ball_size = source["ball_size"];
+	        this.ball_shape = source["ball_shape"];
+	        this.ball_content = source["ball_content"];
+	        this.click_through = source["click_through"];
+	        this.click_through_fullscreen = source["click_through_fullscreen"];
+	        this.fade_after_seconds = source["fade_after_seconds"];
+	        this.fade_opacity = source["fade_opacity"];
+	        this.busy_fullscreen = source["busy_fullscreen"];
+	        this.busy_presentation = source["busy_presentation"];
+	        this.busy_ball_action = source["busy_ball_action"];
+	        this.queue_reminders = source["queue_reminders"];
+	        this.quiet_hours = this.convertValues(source["quiet_hours"], QuietPeriod);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class EncryptionStatus {
+	    enabled: boolean;
+	    locked: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new EncryptionStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.locked = source["locked"];
+	    }
+	}
+	export class HotkeyConfig {
+	    quick_add: string;
+	    toggle_main: string;
+	    toggle_ball: string;
+	    complete_urgent: string;
+	    click_through: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HotkeyConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.quick_add = source["quick_add"];
+	        this.toggle_main = source["toggle_main"];
+	        this.toggle_ball = source["toggle_ball"];
+	        this.complete_urgent = source["complete_urgent"];
+	        this.click_through = source["click_through"];
+	    }
+	}
+	export class TodoEvent {
+	    type: string;
+	    at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TodoEvent(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.at = source["at"];
+	    }
+	}
+	export class QuietPeriod {
+	    days: number[];
+	    start: string;
+	    end: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new QuietPeriod(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.days = source["days"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	    }
+	}
+	export class Reminder {
+	    before?: number;
+	    unit?: string;
+	    at?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Reminder(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.before = source["before"];
+	        this.unit = source["unit"];
+	        this.at = source["at"];
 	    }
 	}
 	export class TodoItem {
 	    id: string;
 	    title: string;
 	    due_date: string;
+	    time_zone?: string;
+	    all_day?: boolean;
+	    defer_until?: string;
 	    completed: boolean;
 	    deleted: boolean;
 	    created_at: string;
 	    completed_at?: string;
-	    reminder_days: number;
+	    reminders?: Reminder[];
+	    reminder_days?: number;
+	    priority?: string;
+	    tags?: string[];
+	    history?: TodoEvent[];
 	
 	    static createFrom(source: any = {}) {
 	        return new TodoItem(source);
@@ -49,12 +316,373 @@ export namespace models {
 	        this.id = source["id"];
 	        this.title = source["title"];
 	        this.due_date = source["due_date"];
+	        this.time_zone = source["time_zone"];
+	        this.all_day = source["all_day"];
+	        this.defer_until = source["defer_until"];
 	        this.completed = source["completed"];
 	        this.deleted = source["deleted"];
 	        this.created_at = source["created_at"];
 	        this.completed_at = source["completed_at"];
+	        this.reminders = this.convertValues(source["reminders"], Reminder);
 	        this.reminder_days = source["reminder_days"];
+	        this.priority = source["priority"];
+	        this.tags = source["tags"];
+	        this.history = this.convertValues(source["history"], TodoEvent);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ArchiveEntry {
+	    todo: TodoItem;
+	    list_id: string;
+	    list_name: string;
+	    archived: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ArchiveEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.todo = this.convertValues(source["todo"], TodoItem);
+	        this.list_id = source["list_id"];
+	        this.list_name = source["list_name"];
+	        this.archived = source["archived"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ArchivePage {
+	    entries: ArchiveEntry[];
+	    total: number;
+	    page: number;
+	    page_size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ArchivePage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entries = this.convertValues(source["entries"], ArchiveEntry);
+	        this.total = source["total"];
+	        this.page = source["page"];
+	        this.page_size = source["page_size"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TodoList {
+	    id: string;
+	    name: string;
+	    file: string;
+	    color?: string;
+	    created_at: string;
+	    exclude_from_ball?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new TodoList(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.file = source["file"];
+	        this.color = source["color"];
+	        this.created_at = source["created_at"];
+	        this.exclude_from_ball = source["exclude_from_ball"];
+	    }
+	}
+	export class Theme {
+	    name: string;
+	    builtin?: boolean;
+	    color_mode: string;
+	    theme_color: string;
+	    edge_light_color: string;
+	    reminder_color: string;
+	    floating_opacity: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Theme(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.builtin = source["builtin"];
+	        this.color_mode = source["color_mode"];
+	        this.theme_color = source["theme_color"];
+	        this.edge_light_color = source["edge_light_color"];
+	        this.reminder_color = source["reminder_color"];
+	        this.floating_opacity = source["floating_opacity"];
+	    }
+	}
+
+}
+
+export namespace stats {
+	
+	export class DayCount {
+	    date: string;
+	    completed: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DayCount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.completed = source["completed"];
+	    }
+	}
+	export class WeekCount {
+	    week: string;
+	    start: string;
+	    completed: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new WeekCount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.week = source["week"];
+	        this.start = source["start"];
+	        this.completed = source["completed"];
+	    }
+	}
+	export class Summary {
+	    from: string;
+	    to: string;
+	    completed: number;
+	    created: number;
+	    on_time: number;
+	    late: number;
+	    on_time_rate: number;
+	    avg_lead_time_hours: number;
+	    current_streak: number;
+	    longest_streak: number;
+	    overdue_backlog: number;
+	    per_day: DayCount[];
+	    per_week: WeekCount[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Summary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.completed = source["completed"];
+	        this.created = source["created"];
+	        this.on_time = source["on_time"];
+	        this.late = source["late"];
+	        this.on_time_rate = source["on_time_rate"];
+	        this.avg_lead_time_hours = source["avg_lead_time_hours"];
+	        this.current_streak = source["current_streak"];
+	        this.longest_streak = source["longest_streak"];
+	        this.overdue_backlog = source["overdue_backlog"];
+	        this.per_day = this.convertValues(source["per_day"], DayCount);
+	        this.per_week = this.convertValues(source["per_week"], WeekCount);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace tracking {
+	
+	export class DayTotal {
+	    date: string;
+	    seconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DayTotal(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.seconds = source["seconds"];
+	    }
+	}
+	export class Entry {
+	    id: string;
+	    todo_id: string;
+	    list_id: string;
+	    start: string;
+	    end: string;
+	    running?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Entry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.todo_id = source["todo_id"];
+	        this.list_id = source["list_id"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.running = source["running"];
+	    }
+	}
+	export class Status {
+	    active: boolean;
+	    todo_id: string;
+	    list_id: string;
+	    since: string;
+	    idle: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Status(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.active = source["active"];
+	        this.todo_id = source["todo_id"];
+	        this.list_id = source["list_id"];
+	        this.since = source["since"];
+	        this.idle = source["idle"];
+	    }
+	}
+	export class TagTotal {
+	    tag: string;
+	    seconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TagTotal(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tag = source["tag"];
+	        this.seconds = source["seconds"];
+	    }
+	}
+	export class TodoTotal {
+	    todo_id: string;
+	    list_id: string;
+	    title: string;
+	    seconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TodoTotal(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.todo_id = source["todo_id"];
+	        this.list_id = source["list_id"];
+	        this.title = source["title"];
+	        this.seconds = source["seconds"];
+	    }
+	}
+	export class Totals {
+	    seconds: number;
+	    by_todo: TodoTotal[];
+	    by_tag: TagTotal[];
+	    by_day: DayTotal[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Totals(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.seconds = source["seconds"];
+	        this.by_todo = this.convertValues(source["by_todo"], TodoTotal);
+	        this.by_tag = this.convertValues(source["by_tag"], TagTotal);
+	        this.by_day = this.convertValues(source["by_day"], DayTotal);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 
 }
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+	"todo-ball/apperr"
+	"todo-ball/models"
+	"todo-ball/platform"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Hotkey ids passed to RegisterHotKey
+const (
+	hotkeyQuickAdd = iota + 1
+	hotkeyToggleMain
+	hotkeyToggleBall
+	hotkeyCompleteUrgent
+	hotkeyClickThrough
+)
+
+type hotkeyBinding struct {
+	id   int
+	name string
+	keys string
+}
+
+func hotkeyBindings(cfg models.HotkeyConfig) []hotkeyBinding {
+	return []hotkeyBinding{
+		{hotkeyQuickAdd, "快速添加", cfg.QuickAdd},
+		{hotkeyToggleMain, "显示/隐藏主界面", cfg.ToggleMain},
+		{hotkeyToggleBall, "显示/隐藏悬浮球", cfg.ToggleBall},
+		{hotkeyCompleteUrgent, "完成最紧急任务", cfg.CompleteUrgent},
+		{hotkeyClickThrough, "悬浮球鼠标穿透", cfg.ClickThrough},
+	}
+}
+
+// hotkeyName returns the action of hotkey id, for messages.
+func hotkeyName(id int) string {
+	for _, b := range hotkeyBindings(models.HotkeyConfig{}) {
+		if b.id == id {
+			return b.name
+		}
+	}
+	return ""
+}
+
+// parseHotkeys validates every configured shortcut and checks that no
+// two actions share the same key combination.
+func parseHotkeys(cfg models.HotkeyConfig) (map[int]platform.Hotkey, error) {
+	parsed := map[int]platform.Hotkey{}
+	owners := map[platform.Hotkey]string{}
+	for _, b := range hotkeyBindings(cfg) {
+		if strings.TrimSpace(b.keys) == "" {
+			continue
+		}
+		hk, err := platform.ParseHotkey(b.keys)
+		if err != nil {
+			e := apperr.Invalid("hotkeys", "hotkey.invalid", b.name)
+			e.Err = err
+			return nil, e
+		}
+		if other, ok := owners[hk]; ok {
+			e := apperr.New(apperr.Conflict, "hotkey.duplicate", b.keys, other, b.name)
+			e.Field = "hotkeys"
+			return nil, e
+		}
+		owners[hk] = b.name
+		parsed[b.id] = hk
+	}
+	return parsed, nil
+}
+
+// startHotkeys creates the hotkey thread and registers the configured
+// shortcuts. Only the main process owns global hotkeys.
+func (a *App) startHotkeys() error {
+	m, err := platform.NewHotkeyManager(func(id int) {
+		// Don't block the hotkey message loop
+		go a.onHotkey(id)
+	})
+	if err != nil {
+		return err
+	}
+	a.hotkeyMu.Lock()
+	a.hotkeys = m
+	a.hotkeyMu.Unlock()
+	return a.applyHotkeys(a.Store.Config().Hotkeys)
+}
+
+// stopHotkeys ends the hotkey thread on shutdown, releasing the shortcuts.
+func (a *App) stopHotkeys() {
+	a.hotkeyMu.Lock()
+	defer a.hotkeyMu.Unlock()
+	if a.hotkeys != nil {
+		a.hotkeys.Close()
+		a.hotkeys = nil
+	}
+}
+
+// applyHotkeys replaces the registered shortcuts with cfg. If any
+// registration fails the previous set is restored and the error returned;
+// it names any previous shortcut that could not be registered again.
+// In ball mode (no hotkey thread) the config is only validated.
+func (a *App) applyHotkeys(cfg models.HotkeyConfig) error {
+	parsed, err := parseHotkeys(cfg)
+	if err != nil {
+		return err
+	}
+
+	a.hotkeyMu.Lock()
+	defer a.hotkeyMu.Unlock()
+	if a.hotkeys == nil {
+		return nil
+	}
+
+	previous := a.registeredHotkeys
+	for id := range previous {
+		a.hotkeys.Unregister(id)
+	}
+
+	registered := map[int]platform.Hotkey{}
+	for _, b := range hotkeyBindings(cfg) {
+		hk, ok := parsed[b.id]
+		if !ok {
+			continue
+		}
+		if err := a.hotkeys.Register(b.id, hk); err != nil {
+			slog.Warn("RegisterHotKey failed", "hotkey", b.keys, "action", b.name, "err", err)
+			for id := range registered {
+				a.hotkeys.Unregister(id)
+			}
+			restored := map[int]platform.Hotkey{}
+			var lost []string
+			var restoreErrs []error
+			for id, old := range previous {
+				if rerr := a.hotkeys.Register(id, old); rerr != nil {
+					slog.Error("restore hotkey failed", "action", hotkeyName(id), "err", rerr)
+					lost = append(lost, hotkeyName(id))
+					restoreErrs = append(restoreErrs, rerr)
+					continue
+				}
+				restored[id] = old
+			}
+			a.registeredHotkeys = restored
+			if len(lost) > 0 {
+				sort.Strings(lost)
+				return apperr.Wrap(apperr.IO, "hotkey.restore_failed", errors.Join(append([]error{err}, restoreErrs...)...), b.keys, b.name, strings.Join(lost, ", "))
+			}
+			if errors.Is(err, platform.ErrHotkeyInUse) {
+				return apperr.Wrap(apperr.Conflict, "hotkey.in_use", err, b.keys, b.name)
+			}
+			return apperr.Wrap(apperr.IO, "hotkey.register_failed", err, b.keys, b.name)
+		}
+		registered[b.id] = hk
+	}
+	a.registeredHotkeys = registered
+	return nil
+}
+
+func (a *App) onHotkey(id int) {
+	slog.Debug("hotkey pressed", "id", id)
+	switch id {
+	case hotkeyQuickAdd:
+		a.openQuickAdd()
+	case hotkeyToggleMain:
+		a.toggleMain()
+	case hotkeyToggleBall:
+		a.toggleBall()
+	case hotkeyCompleteUrgent:
+		a.completeMostUrgent()
+	case hotkeyClickThrough:
+		a.toggleClickThrough()
+	}
+}
+
+func (a *App) toggleMain() {
+	hwnd := platform.FindWindow("待办事项")
+	if hwnd != 0 && platform.IsWindowVisible(hwnd) && !runtime.WindowIsMinimised(a.ctx) {
+		runtime.WindowHide(a.ctx)
+		return
+	}
+	a.OpenMain()
+	if hwnd != 0 {
+		platform.SetForegroundWindow(hwnd)
+	}
+}
+
+func (a *App) toggleBall() {
+	hwnd := platform.FindWindow("悬浮球")
+	if hwnd == 0 {
+		a.launchBall()
+		return
+	}
+	if platform.IsWindowVisible(hwnd) {
+		platform.HideWindow(hwnd)
+	} else {
+		platform.ShowNoActivate(hwnd)
+	}
+}
+
+func (a *App) completeMostUrgent() {
+	if err := a.Store.LoadTodos(); err != nil {
+		slog.Warn("reload todos failed", "err", err)
+		return
+	}
+	available, err := a.Store.Available(time.Now())
+	if err != nil {
+		slog.Warn("read pending todos failed", "err", err)
+		return
+	}
+	item, ok := models.MostUrgent(available)
+	if !ok {
+		return
+	}
+	if err := a.ToggleTodo(item.ID); err != nil {
+		slog.Error("complete most urgent todo failed", "id", item.ID, "err", err)
+	}
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"todo-ball/icons"
+	"todo-ball/platform"
+)
+
+// Icon states, picked by iconState
+const (
+	iconNormal   = "normal"
+	iconUpcoming = "upcoming"
+	iconOverdue  = "overdue"
+	iconFocus    = "focus"
+)
+
+const (
+	iconConfigFileName = "icon_config.json"
+	// How often the main process checks icon_config.json and the state
+	iconWatchInterval = 2 * time.Second
+)
+
+// GetAppIconPath returns the path to the app icon.
+// It tries to find icon.ico in the app directory.
+// If not found, it extracts the embedded icon to a temp file.
+func GetAppIconPath() string {
+	appDir := getAppDir()
+	iconPath := filepath.Join(appDir, "icon.ico")
+	if _, err := os.Stat(iconPath); err == nil {
+		return iconPath
+	}
+
+	// Extract to temp
+	tempDir := os.TempDir()
+	tempIconPath := filepath.Join(tempDir, "todo-ball-icon.ico")
+
+	// Only write if not exists or size differs (simple check)
+	if info, err := os.Stat(tempIconPath); err != nil || info.Size() != int64(len(iconData)) {
+		os.WriteFile(tempIconPath, iconData, 0644)
+	}
+
+	return tempIconPath
+}
+
+func getAppDir() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return "."
+	}
+	return filepath.Dir(exe)
+}
+
+// IconSet names an icon per state. Empty entries fall back to Normal.
+type IconSet struct {
+	Normal   string `json:"normal,omitempty"`
+	Upcoming string `json:"upcoming,omitempty"`
+	Overdue  string `json:"overdue,omitempty"`
+	Focus    string `json:"focus,omitempty"`
+}
+
+// For returns the icon of state.
+func (s IconSet) For(state string) string {
+	var icon string
+	switch state {
+	case iconUpcoming:
+		icon = s.Upcoming
+	case iconOverdue:
+		icon = s.Overdue
+	case iconFocus:
+		icon = s.Focus
+	}
+	if icon == "" {
+		return s.Normal
+	}
+	return icon
+}
+
+func (s *IconSet) each(fn func(path string) string) {
+	s.Normal = fn(s.Normal)
+	s.Upcoming = fn(s.Upcoming)
+	s.Overdue = fn(s.Overdue)
+	s.Focus = fn(s.Focus)
+}
+
+// IconConfig is icon_config.json next to the executable. Paths are
+// relative to that directory. The file is re-read while the app runs.
+//
+//	{
+//	  "tray": {"normal": "icon.ico", "overdue": "icons/alert.ico"},
+//	  "main_window": {"focus": "icons/tomato.ico"},
+//	  "ball": {"upcoming": "icons/clock.png"}
+//	}
+type IconConfig struct {
+	// Single icons of older versions, used for the normal state
+	TrayIcon       string `json:"tray_icon"`
+	MainWindowIcon string `json:"main_window_icon"` // Title bar
+	TaskbarIcon    string `json:"taskbar_icon"`     // Taskbar and Alt+Tab
+
+	Tray       IconSet `json:"tray"`
+	MainWindow IconSet `json:"main_window"`
+	// Pictures shown in the ball instead of custom_icon_path. Loaded
+	// as asset names, see icons.Assets.
+	Ball IconSet `json:"ball"`
+}
+
+// WindowIcons returns the title bar and taskbar icons of state. The
+// taskbar keeps taskbar_icon unless state has a main window icon of
+// its own.
+func (c IconConfig) WindowIcons(state string) (small, big string) {
+	small = c.MainWindow.For(state)
+	if small != c.MainWindow.Normal {
+		return small, small
+	}
+	return small, c.TaskbarIcon
+}
+
+// loadIconConfig reads icon_config.json from appDir. Missing icons fall
+// back to the app icon; ball pictures are imported into assets, and
+// dropped if assets is nil.
+func loadIconConfig(appDir string, assets *icons.Assets) IconConfig {
+	defaultIcon := GetAppIconPath()
+
+	var config IconConfig
+	configPath := filepath.Join(appDir, iconConfigFileName)
+	if data, err := os.ReadFile(configPath); err == nil {
+		// Notepad saves UTF-8 with a byte order mark
+		data = bytes.TrimPrefix(data, []byte("\ufeff"))
+		if err := json.Unmarshal(data, &config); err != nil {
+			slog.Warn("invalid icon config, using defaults", "path", configPath, "err", err)
+			config = IconConfig{}
+		}
+	}
+
+	// Resolve relative paths, dropping files that don't exist
+	resolvePath := func(path string) string {
+		if path == "" {
+			return ""
+		}
+		fullPath := path
+		if !filepath.IsAbs(path) {
+			fullPath = filepath.Join(appDir, path)
+		}
+		if _, err := os.Stat(fullPath); err != nil {
+			slog.Warn("icon not found", "path", fullPath)
+			return ""
+		}
+		return fullPath
+	}
+	orDefault := func(path string) string {
+		if path = resolvePath(path); path == "" {
+			return defaultIcon
+		}
+		return path
+	}
+
+	config.TrayIcon = orDefault(config.TrayIcon)
+	config.MainWindowIcon = orDefault(config.MainWindowIcon)
+	config.TaskbarIcon = orDefault(config.TaskbarIcon)
+	config.Tray.each(resolvePath)
+	config.MainWindow.each(resolvePath)
+	if config.Tray.Normal == "" {
+		config.Tray.Normal = config.TrayIcon
+	}
+	if config.MainWindow.Normal == "" {
+		config.MainWindow.Normal = config.MainWindowIcon
+	}
+
+	config.Ball.each(func(path string) string {
+		if path = resolvePath(path); path == "" || assets == nil {
+			return ""
+		}
+		name, err := assets.ImportFile(path, ballIconPixels)
+		if err != nil {
+			slog.Warn("cannot import ball icon", "path", path, "err", err)
+			return ""
+		}
+		return name
+	})
+	return config
+}
+
+// iconManager keeps the icon config current and remembers what the
+// main window shows.
+type iconManager struct {
+	dir    string
+	assets *icons.Assets // Imports ball pictures, nil in the main process
+
+	mu         sync.Mutex
+	seen       os.FileInfo // icon_config.json as last read, nil if none
+	loaded     bool
+	generation int // Bumped on every reload
+	config     IconConfig
+	window     string // Icons applied to the main window, see updateIcons
+}
+
+func newIconManager(dir string, assets *icons.Assets) *iconManager {
+	return &iconManager{dir: dir, assets: assets}
+}
+
+// Config returns the icon config, re-reading the file if it changed.
+func (m *iconManager) Config() IconConfig {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reloadLocked()
+	return m.config
+}
+
+func (m *iconManager) reloadLocked() {
+	info, err := os.Stat(filepath.Join(m.dir, iconConfigFileName))
+	if err != nil {
+		info = nil
+	}
+	unchanged := (info == nil && m.seen == nil) ||
+		(info != nil && m.seen != nil && info.ModTime().Equal(m.seen.ModTime()) && info.Size() == m.seen.Size())
+	if m.loaded && unchanged {
+		return
+	}
+	if m.loaded {
+		slog.Info("icon config changed, reloading")
+	}
+	m.config = loadIconConfig(m.dir, m.assets)
+	m.seen = info
+	m.loaded = true
+	m.generation++
+}
+
+// iconState is the state the icons show. A running focus timer wins, as
+// the user started it; otherwise the most urgent pending todo decides,
+// unless reminders are quiet.
+func (a *App) iconState() string {
+	if status, err := a.focus.Status(); err == nil && status.Active {
+		return iconFocus
+	}
+	now := time.Now()
+	if a.quietStatus(now).Active {
+		return iconNormal
+	}
+	pending, err := a.Store.Available(now)
+	if err != nil {
+		return iconNormal
+	}
+	state := iconNormal
+	for _, item := range pending {
+		if item.IsOverdue(now) {
+			return iconOverdue
+		}
+		if item.IsUpcoming(now) {
+			state = iconUpcoming
+		}
+	}
+	return state
+}
+
+// updateIcons swaps the tray and main window icons for the current
+// state, picking up changes to icon_config.json. Main process only.
+func (a *App) updateIcons() {
+	if a.Mode != "main" {
+		return
+	}
+	m := a.appIcons
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reloadLocked()
+	state := a.iconState()
+	key := fmt.Sprintf("%d/%s", m.generation, state)
+
+	if t := a.tray.Load(); t != nil {
+		t.setIcon(m.config.Tray, state, key)
+	}
+	if m.window == key || a.ctx == nil {
+		return
+	}
+	hwnd := platform.FindWindow("待办事项")
+	if hwnd == 0 {
+		return
+	}
+	small, big := m.config.WindowIcons(state)
+	platform.SetWindowIcon(hwnd, small, platform.ICON_SMALL)
+	platform.SetWindowIcon(hwnd, big, platform.ICON_BIG)
+	m.window = key
+	slog.Debug("window icon updated", "state", state, "icon", small)
+}
+
+// startIconWatcher keeps the icons in step with the todos, the focus
+// timer and icon_config.json.
+func (a *App) startIconWatcher() {
+	a.updateIcons()
+	go func() {
+		ticker := time.NewTicker(iconWatchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-a.ctx.Done():
+				return
+			}
+			a.updateIcons()
+		}
+	}()
+}
+
+// GetBallIcon returns the asset name of the ball picture for the current
+// state, or "" to use custom_icon_path.
+func (a *App) GetBallIcon() (string, error) {
+	return a.appIcons.Config().Ball.For(a.iconState()), nil
+}
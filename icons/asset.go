@@ -0,0 +1,186 @@
+package icons
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// AssetDir is the directory below the data directory that holds
+// imported images.
+const AssetDir = "assets"
+
+// MaxImportSize bounds the files Import accepts.
+const MaxImportSize = 16 << 20
+
+// MaxPixels bounds the width and height of images Decode accepts. A
+// small file can declare a huge image, so this is checked on the header
+// before any pixels are decoded.
+const MaxPixels = 4096
+
+var (
+	ErrUnsupported   = errors.New("unsupported image format")
+	ErrTooLarge      = errors.New("image file too large")
+	ErrTooManyPixels = errors.New("image dimensions too large")
+	ErrNotFound      = errors.New("asset not found")
+)
+
+// Asset names are the content hash of the stored PNG, so they double as
+// cache keys and can't point outside the assets directory.
+var assetName = regexp.MustCompile(`^[0-9a-f]{32}\.png$`)
+
+// Asset is a stored image ready for an <img> data URL.
+type Asset struct {
+	Name string `json:"name"`
+	MIME string `json:"mime"`
+	Data string `json:"data"` // Base64
+	Key  string `json:"key"`  // Changes whenever the content does
+}
+
+// Assets is the managed directory of imported images. Images are
+// checked and normalised once on import; afterwards only names of
+// stored assets can be read back.
+type Assets struct {
+	dir string
+}
+
+// NewAssets keeps assets in dataDir/assets.
+func NewAssets(dataDir string) *Assets {
+	return &Assets{dir: filepath.Join(dataDir, AssetDir)}
+}
+
+// IsName reports whether name has the form of an asset name. It says
+// nothing about whether the asset exists.
+func IsName(name string) bool {
+	return assetName.MatchString(name)
+}
+
+// Sniff returns the MIME type of an image Import can decode, judged by
+// content rather than by file name.
+func Sniff(data []byte) (string, error) {
+	mime := http.DetectContentType(data)
+	switch mime {
+	case "image/png", "image/jpeg", "image/gif", "image/x-icon":
+		return mime, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupported, mime)
+}
+
+// Decode reads a PNG, JPEG, GIF or .ico image of at most MaxPixels
+// each way.
+func Decode(data []byte) (image.Image, error) {
+	mime, err := Sniff(data)
+	if err != nil {
+		return nil, err
+	}
+	if mime == "image/x-icon" {
+		return DecodeICO(data)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	if err := checkPixels(cfg.Width, cfg.Height); err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	return img, nil
+}
+
+// checkPixels refuses images larger than MaxPixels either way.
+func checkPixels(width, height int) error {
+	if width > MaxPixels || height > MaxPixels {
+		return fmt.Errorf("%w: %dx%d", ErrTooManyPixels, width, height)
+	}
+	return nil
+}
+
+// Import decodes an image file, crops it to a square of size pixels
+// and stores it as PNG. Importing the same picture again returns the
+// same name.
+func (a *Assets) Import(data []byte, size int) (string, error) {
+	if len(data) > MaxImportSize {
+		return "", ErrTooLarge
+	}
+	img, err := Decode(data)
+	if err != nil {
+		return "", err
+	}
+	icon, err := Fit(img, size)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, icon); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	name := hex.EncodeToString(sum[:16]) + ".png"
+
+	path := filepath.Join(a.dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return name, nil
+	}
+	if err := os.MkdirAll(a.dir, 0755); err != nil {
+		return "", err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// ImportFile imports the image at path, see Import.
+func (a *Assets) ImportFile(path string, size int) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > MaxImportSize {
+		return "", ErrTooLarge
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return a.Import(data, size)
+}
+
+// Get reads a stored asset. Anything but the name of one returns
+// ErrNotFound.
+func (a *Assets) Get(name string) (Asset, error) {
+	if !IsName(name) {
+		return Asset{}, ErrNotFound
+	}
+	data, err := os.ReadFile(filepath.Join(a.dir, name))
+	if os.IsNotExist(err) {
+		return Asset{}, ErrNotFound
+	}
+	if err != nil {
+		return Asset{}, err
+	}
+	return Asset{
+		Name: name,
+		MIME: http.DetectContentType(data),
+		Data: base64.StdEncoding.EncodeToString(data),
+		Key:  name[:len(name)-len(filepath.Ext(name))],
+	}, nil
+}
@@ -0,0 +1,57 @@
+package icons
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Badge draws a dot of colour c with a white ring in the bottom-right
+// corner of img, like the unread badges of other tray apps.
+func Badge(img image.Image, c color.Color) *image.NRGBA {
+	b := img.Bounds()
+	out := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(out, out.Bounds(), img, b.Min, draw.Src)
+
+	size := float64(min(b.Dx(), b.Dy()))
+	r := size * 0.22
+	ring := max(size/32, 1)
+	cx, cy := float64(b.Dx())-r-ring, float64(b.Dy())-r-ring
+
+	fill := color.NRGBAModel.Convert(c).(color.NRGBA)
+	white := color.NRGBA{255, 255, 255, 255}
+	for y := int(cy - r - ring - 1); y <= int(cy+r+ring+1); y++ {
+		for x := int(cx - r - ring - 1); x <= int(cx+r+ring+1); x++ {
+			d := math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-cy)
+			switch {
+			case d <= r:
+				blend(out, x, y, fill, r-d)
+			case d <= r+ring:
+				blend(out, x, y, white, r+ring-d)
+			}
+		}
+	}
+	return out
+}
+
+// blend paints c over the pixel at x, y, anti-aliasing the last pixel
+// of an edge: coverage is how far inside the shape the pixel centre is.
+func blend(img *image.NRGBA, x, y int, c color.NRGBA, coverage float64) {
+	if !(image.Point{x, y}.In(img.Rect)) {
+		return
+	}
+	a := min(coverage+0.5, 1)
+	if a >= 1 {
+		img.SetNRGBA(x, y, c)
+		return
+	}
+	under := img.NRGBAAt(x, y)
+	mix := func(top, bottom uint8) uint8 { return uint8(float64(top)*a + float64(bottom)*(1-a)) }
+	img.SetNRGBA(x, y, color.NRGBA{
+		R: mix(c.R, under.R),
+		G: mix(c.G, under.G),
+		B: mix(c.B, under.B),
+		A: max(under.A, uint8(255*a)),
+	})
+}
@@ -0,0 +1,163 @@
+// Package icons decodes and builds the .ico images used for the tray
+// and windows, and stores the images users import for the ball.
+package icons
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+var pngMagic = []byte("\x89PNG\r\n\x1a\n")
+
+type icoEntry struct {
+	Width, Height uint8 // 0 means 256
+	Colors        uint8
+	Reserved      uint8
+	Planes        uint16
+	BitCount      uint16
+	Size          uint32
+	Offset        uint32
+}
+
+// DecodeICO returns the largest image of an .ico file. Entries may be
+// PNG or 32/24-bit bitmaps, which covers what icon editors write today.
+func DecodeICO(data []byte) (image.Image, error) {
+	r := bytes.NewReader(data)
+	var header struct{ Reserved, Type, Count uint16 }
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil || header.Type != 1 || header.Count == 0 {
+		return nil, errors.New("not an .ico file")
+	}
+	entries := make([]icoEntry, header.Count)
+	if err := binary.Read(r, binary.LittleEndian, entries); err != nil {
+		return nil, fmt.Errorf("ico directory: %w", err)
+	}
+
+	best := entries[0]
+	for _, e := range entries[1:] {
+		if side(e.Width) > side(best.Width) || (side(e.Width) == side(best.Width) && e.BitCount > best.BitCount) {
+			best = e
+		}
+	}
+	end := uint64(best.Offset) + uint64(best.Size)
+	if end > uint64(len(data)) {
+		return nil, errors.New("ico entry out of range")
+	}
+	img := data[best.Offset:end]
+	if bytes.HasPrefix(img, pngMagic) {
+		cfg, err := png.DecodeConfig(bytes.NewReader(img))
+		if err != nil {
+			return nil, err
+		}
+		if err := checkPixels(cfg.Width, cfg.Height); err != nil {
+			return nil, err
+		}
+		return png.Decode(bytes.NewReader(img))
+	}
+	return decodeDIB(img)
+}
+
+func side(b uint8) int {
+	if b == 0 {
+		return 256
+	}
+	return int(b)
+}
+
+// decodeDIB reads the BITMAPINFOHEADER image of an .ico entry: pixels
+// bottom-up, followed by a 1-bit AND mask; the stored height is doubled.
+func decodeDIB(data []byte) (image.Image, error) {
+	var h struct {
+		Size          uint32
+		Width, Height int32
+		Planes        uint16
+		BitCount      uint16
+		Compression   uint32
+		_             [20]byte
+	}
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &h); err != nil {
+		return nil, fmt.Errorf("ico bitmap header: %w", err)
+	}
+	// The size comes from the file; the pixels follow the header
+	if h.Size < 40 || uint64(h.Size) > uint64(len(data)) {
+		return nil, fmt.Errorf("invalid ico bitmap header size %d", h.Size)
+	}
+	if h.Compression != 0 || (h.BitCount != 32 && h.BitCount != 24) {
+		return nil, fmt.Errorf("unsupported ico bitmap: %d bits, compression %d", h.BitCount, h.Compression)
+	}
+	w, ht := int(h.Width), int(h.Height/2)
+	if w <= 0 || ht <= 0 || w > 1024 || ht > 1024 {
+		return nil, errors.New("invalid ico bitmap size")
+	}
+
+	bpp := int(h.BitCount) / 8
+	stride := (w*bpp + 3) &^ 3
+	maskStride := ((w+7)/8 + 3) &^ 3
+	pixels := data[h.Size:]
+	if len(pixels) < stride*ht {
+		return nil, errors.New("truncated ico bitmap")
+	}
+	mask := pixels[stride*ht:]
+	hasMask := len(mask) >= maskStride*ht
+
+	img := image.NewNRGBA(image.Rect(0, 0, w, ht))
+	anyAlpha := false
+	for y := 0; y < ht; y++ {
+		row := pixels[(ht-1-y)*stride:]
+		for x := 0; x < w; x++ {
+			p := row[x*bpp:]
+			a := uint8(255)
+			if bpp == 4 {
+				a = p[3]
+				anyAlpha = anyAlpha || a != 0
+			}
+			img.SetNRGBA(x, y, color.NRGBA{R: p[2], G: p[1], B: p[0], A: a})
+		}
+	}
+	// Without an alpha channel (or an all-zero one) transparency is in the mask
+	if (bpp == 3 || !anyAlpha) && hasMask {
+		for y := 0; y < ht; y++ {
+			row := mask[(ht-1-y)*maskStride:]
+			for x := 0; x < w; x++ {
+				transparent := row[x/8]&(0x80>>(x%8)) != 0
+				c := img.NRGBAAt(x, y)
+				c.A = 255
+				if transparent {
+					c.A = 0
+				}
+				img.SetNRGBA(x, y, c)
+			}
+		}
+	}
+	return img, nil
+}
+
+// EncodeICO writes img as a single-entry .ico holding a PNG, which
+// Windows accepts since Vista. img must be at most 256x256.
+func EncodeICO(img image.Image) ([]byte, error) {
+	b := img.Bounds()
+	if b.Dx() > 256 || b.Dy() > 256 {
+		return nil, errors.New("icon larger than 256x256")
+	}
+	var payload bytes.Buffer
+	if err := png.Encode(&payload, img); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	binary.Write(&out, binary.LittleEndian, struct{ Reserved, Type, Count uint16 }{0, 1, 1})
+	binary.Write(&out, binary.LittleEndian, icoEntry{
+		Width:    uint8(b.Dx() % 256),
+		Height:   uint8(b.Dy() % 256),
+		Planes:   1,
+		BitCount: 32,
+		Size:     uint32(payload.Len()),
+		Offset:   6 + 16,
+	})
+	out.Write(payload.Bytes())
+	return out.Bytes(), nil
+}
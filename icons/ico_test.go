@@ -0,0 +1,116 @@
+package icons
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// icoFile wraps one entry image in an .ico directory.
+func icoFile(entry []byte, width uint8, bits uint16) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, struct{ Reserved, Type, Count uint16 }{0, 1, 1})
+	binary.Write(&buf, binary.LittleEndian, icoEntry{
+		Width: width, Height: width, Planes: 1, BitCount: bits,
+		Size: uint32(len(entry)), Offset: 6 + 16,
+	})
+	buf.Write(entry)
+	return buf.Bytes()
+}
+
+// dib builds a BITMAPINFOHEADER bitmap of w x h pixels with the given
+// header size, followed by pixel and mask bytes of the sizes given.
+func dib(size uint32, w, h int32, bits uint16, pixels, mask int) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, struct {
+		Size          uint32
+		Width, Height int32
+		Planes        uint16
+		BitCount      uint16
+		Compression   uint32
+		_             [20]byte
+	}{Size: size, Width: w, Height: 2 * h, Planes: 1, BitCount: bits})
+	buf.Write(make([]byte, pixels+mask))
+	return buf.Bytes()
+}
+
+func TestDecodeICO(t *testing.T) {
+	// 2x2, 32 bits: two rows of 8 bytes, then a mask of two 4-byte rows
+	img, err := DecodeICO(icoFile(dib(40, 2, 2, 32, 16, 8), 2, 32))
+	if err != nil {
+		t.Fatalf("32-bit bitmap: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 2 || b.Dy() != 2 {
+		t.Errorf("32-bit bitmap is %v, want 2x2", b)
+	}
+
+	src := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	src.SetNRGBA(3, 4, color.NRGBA{R: 200, A: 255})
+	data, err := EncodeICO(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err = DecodeICO(data)
+	if err != nil {
+		t.Fatalf("PNG entry: %v", err)
+	}
+	if got := color.NRGBAModel.Convert(img.At(3, 4)).(color.NRGBA); got.R != 200 || got.A != 255 {
+		t.Errorf("PNG entry pixel = %v", got)
+	}
+}
+
+func TestDecodeICOMalformed(t *testing.T) {
+	valid := dib(40, 2, 2, 32, 16, 8)
+	outOfRange := icoFile(valid, 2, 32)
+	binary.LittleEndian.PutUint32(outOfRange[6+12:], 1<<31) // Entry offset
+
+	var bigPNG bytes.Buffer
+	png.Encode(&bigPNG, image.NewGray(image.Rect(0, 0, MaxPixels+1, 1)))
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"short header", []byte{0, 0, 1}},
+		{"not an icon", []byte{0, 0, 2, 0, 1, 0}},
+		{"no entries", []byte{0, 0, 1, 0, 0, 0}},
+		{"truncated directory", []byte{0, 0, 1, 0, 1, 0, 16, 16}},
+		{"entry out of range", outOfRange},
+		{"short bitmap header", icoFile(valid[:20], 2, 32)},
+		{"header size past the end", icoFile(dib(0xFFFFFF, 2, 2, 32, 0, 0), 2, 32)},
+		{"header size too small", icoFile(dib(4, 2, 2, 32, 16, 8), 2, 32)},
+		{"truncated pixels", icoFile(dib(40, 2, 2, 32, 10, 0), 2, 32)},
+		{"8-bit bitmap", icoFile(dib(40, 2, 2, 8, 8, 8), 2, 8)},
+		{"zero width", icoFile(dib(40, 0, 2, 32, 16, 8), 2, 32)},
+		{"negative height", icoFile(dib(40, 2, -2, 32, 16, 8), 2, 32)},
+		{"huge bitmap", icoFile(dib(40, 5000, 5000, 32, 0, 0), 0, 32)},
+		{"broken PNG", icoFile(append(append([]byte(nil), pngMagic...), 1, 2, 3), 16, 32)},
+		{"huge PNG", icoFile(bigPNG.Bytes(), 0, 32)},
+	}
+	for _, tt := range tests {
+		if _, err := DecodeICO(tt.data); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+}
+
+func TestDecodeLimits(t *testing.T) {
+	var big bytes.Buffer
+	png.Encode(&big, image.NewGray(image.Rect(0, 0, 1, MaxPixels+1)))
+	if _, err := Decode(big.Bytes()); !errors.Is(err, ErrTooManyPixels) {
+		t.Errorf("tall PNG: got %v, want ErrTooManyPixels", err)
+	}
+	if _, err := Decode([]byte("not an image")); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("text: got %v, want ErrUnsupported", err)
+	}
+	// Sniffed as an icon, so it takes the .ico path
+	crafted := icoFile(dib(0xFFFFFF, 2, 2, 32, 0, 0), 2, 32)
+	if _, err := Decode(crafted); err == nil {
+		t.Error("crafted .ico: no error")
+	}
+}
@@ -0,0 +1,108 @@
+package icons
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Fit centre-crops img to a square and scales it to size x size.
+// Shrinking averages the covered source pixels; enlarging interpolates
+// between them. Colours are averaged premultiplied so transparent
+// pixels don't darken the edges. Both img and size are limited to
+// MaxPixels.
+func Fit(img image.Image, size int) (*image.NRGBA, error) {
+	b := img.Bounds()
+	if err := checkPixels(b.Dx(), b.Dy()); err != nil {
+		return nil, err
+	}
+	if err := checkPixels(size, size); err != nil {
+		return nil, err
+	}
+	side := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, side, side).Add(b.Min).Add(image.Pt((b.Dx()-side)/2, (b.Dy()-side)/2))
+	src := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(src, src.Bounds(), img, crop.Min, draw.Src)
+
+	out := image.NewNRGBA(image.Rect(0, 0, size, size))
+	if side == 0 || size <= 0 {
+		return out, nil
+	}
+	scale := float64(side) / float64(size)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			var c [4]float64
+			if scale > 1 {
+				c = boxSample(src, float64(x)*scale, float64(y)*scale, scale)
+			} else {
+				c = bilinearSample(src, (float64(x)+0.5)*scale-0.5, (float64(y)+0.5)*scale-0.5)
+			}
+			out.SetNRGBA(x, y, unpremultiply(c))
+		}
+	}
+	return out, nil
+}
+
+// boxSample averages the square of side n starting at x0, y0, weighting
+// the pixels cut by its edges by how much of them it covers.
+func boxSample(src *image.RGBA, x0, y0, n float64) [4]float64 {
+	var sum [4]float64
+	var total float64
+	x1, y1 := x0+n, y0+n
+	for y := int(y0); float64(y) < y1 && y < src.Rect.Dy(); y++ {
+		wy := min(float64(y+1), y1) - max(float64(y), y0)
+		for x := int(x0); float64(x) < x1 && x < src.Rect.Dx(); x++ {
+			w := wy * (min(float64(x+1), x1) - max(float64(x), x0))
+			p := src.RGBAAt(x, y)
+			sum[0] += w * float64(p.R)
+			sum[1] += w * float64(p.G)
+			sum[2] += w * float64(p.B)
+			sum[3] += w * float64(p.A)
+			total += w
+		}
+	}
+	if total > 0 {
+		for i := range sum {
+			sum[i] /= total
+		}
+	}
+	return sum
+}
+
+// bilinearSample interpolates the four pixels around x, y, clamping
+// at the borders.
+func bilinearSample(src *image.RGBA, x, y float64) [4]float64 {
+	maxX, maxY := src.Rect.Dx()-1, src.Rect.Dy()-1
+	x = min(max(x, 0), float64(maxX))
+	y = min(max(y, 0), float64(maxY))
+	x0, y0 := int(x), int(y)
+	x1, y1 := min(x0+1, maxX), min(y0+1, maxY)
+	fx, fy := x-float64(x0), y-float64(y0)
+
+	var c [4]float64
+	for _, s := range [4]struct {
+		x, y int
+		w    float64
+	}{
+		{x0, y0, (1 - fx) * (1 - fy)},
+		{x1, y0, fx * (1 - fy)},
+		{x0, y1, (1 - fx) * fy},
+		{x1, y1, fx * fy},
+	} {
+		p := src.RGBAAt(s.x, s.y)
+		c[0] += s.w * float64(p.R)
+		c[1] += s.w * float64(p.G)
+		c[2] += s.w * float64(p.B)
+		c[3] += s.w * float64(p.A)
+	}
+	return c
+}
+
+func unpremultiply(c [4]float64) color.NRGBA {
+	a := c[3]
+	if a < 0.5 {
+		return color.NRGBA{}
+	}
+	channel := func(v float64) uint8 { return uint8(min(v*255/a+0.5, 255)) }
+	return color.NRGBA{R: channel(c[0]), G: channel(c[1]), B: channel(c[2]), A: uint8(min(a+0.5, 255))}
+}
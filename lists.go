@@ -0,0 +1,79 @@
+package main
+
+import (
+	"time"
+	"todo-ball/apperr"
+	"todo-ball/models"
+)
+
+// ListLists returns all todo lists, the default list first
+func (a *App) ListLists() ([]models.TodoList, error) {
+	if err := a.Store.LoadLists(); err != nil {
+		return nil, apperr.Wrap(apperr.IO, "config.load_failed", err)
+	}
+	return a.Store.GetLists(), nil
+}
+
+// CreateList adds an empty list. It does not switch to it.
+func (a *App) CreateList(name string, color string) (models.TodoList, error) {
+	list, err := a.Store.CreateList(name, color)
+	if err != nil {
+		return models.TodoList{}, storeError("list.save_failed", err)
+	}
+	a.notifyUpdate()
+	return list, nil
+}
+
+// SwitchList makes id the active list for both processes
+func (a *App) SwitchList(id string) error {
+	if err := a.Store.SwitchList(id); err != nil {
+		return storeError("list.save_failed", err)
+	}
+	a.notifyUpdate()
+	return nil
+}
+
+// DeleteList removes a list and all of its todos
+func (a *App) DeleteList(id string) error {
+	if err := a.Store.DeleteList(id); err != nil {
+		return storeError("list.save_failed", err)
+	}
+	a.notifyUpdate()
+	return nil
+}
+
+// GetBallTodos returns the todos the ball counts: the active list, or
+// every list not excluded from the ball when BallCountMode is "combined".
+// Todos deferred to a later start date are left out.
+func (a *App) GetBallTodos() ([]models.TodoItem, error) {
+	if a.Store.IsLocked() {
+		return nil, apperr.New(apperr.Locked, "store.locked")
+	}
+	if a.Store.Config().BallCountMode != models.BallCountCombined {
+		return withoutDeferred(a.Store.GetTodos(), time.Now()), nil
+	}
+	var all []models.TodoItem
+	for _, l := range a.Store.GetLists() {
+		if l.ExcludeFromBall && l.ID != a.Store.Config().ActiveList {
+			continue
+		}
+		todos, err := a.Store.GetListTodos(l.ID)
+		if err != nil {
+			return nil, storeError("file.read_failed", err)
+		}
+		all = append(all, todos...)
+	}
+	return withoutDeferred(all, time.Now()), nil
+}
+
+// withoutDeferred drops pending todos whose start date is still to come.
+// Completed ones stay, the ball's progress ring counts them.
+func withoutDeferred(todos []models.TodoItem, now time.Time) []models.TodoItem {
+	out := make([]models.TodoItem, 0, len(todos))
+	for _, t := range todos {
+		if !t.IsDeferred(now) || t.Completed {
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -3,13 +3,19 @@ package main
 import (
 	"context"
 	"embed"
-	"encoding/json"
+	"encoding/hex"
 	"flag"
+	"io"
+	"log/slog"
 	"os"
+	"strings"
 
 	"path/filepath"
 
+	"todo-ball/apperr"
+	"todo-ball/applog"
 	"todo-ball/platform"
+	"todo-ball/storage"
 
 	"github.com/energye/systray"
 	"github.com/wailsapp/wails/v2"
@@ -24,85 +30,55 @@ var assets embed.FS
 //go:embed icon.ico
 var iconData []byte
 
-// GetAppIconPath returns the path to the app icon.
-// It tries to find icon.ico in the app directory.
-// If not found, it extracts the embedded icon to a temp file.
-func GetAppIconPath() string {
-	appDir := getAppDir()
-	iconPath := filepath.Join(appDir, "icon.ico")
-	if _, err := os.Stat(iconPath); err == nil {
-		return iconPath
+// unlockFromStdin reads the hex key written by the main process.
+func unlockFromStdin(app *App) {
+	data, err := io.ReadAll(io.LimitReader(os.Stdin, 256))
+	if err != nil {
+		slog.Warn("cannot read key from stdin", "err", err)
+		return
 	}
-
-	// Extract to temp
-	tempDir := os.TempDir()
-	tempIconPath := filepath.Join(tempDir, "todo-ball-icon.ico")
-
-	// Only write if not exists or size differs (simple check)
-	if info, err := os.Stat(tempIconPath); err != nil || info.Size() != int64(len(iconData)) {
-		os.WriteFile(tempIconPath, iconData, 0644)
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		slog.Warn("invalid key on stdin", "err", err)
+		return
+	}
+	if err := app.Store.UnlockWithKey(key); err != nil {
+		slog.Warn("unlock with key from main failed", "err", err)
 	}
-
-	return tempIconPath
-}
-
-type IconConfig struct {
-	TrayIcon       string `json:"tray_icon"`
-	MainWindowIcon string `json:"main_window_icon"`
-	TaskbarIcon    string `json:"taskbar_icon"`
 }
 
-func getAppDir() string {
-	exe, err := os.Executable()
-	if err != nil {
-		return "."
+func main() {
+	if err := run(); err != nil {
+		os.Exit(1)
 	}
-	return filepath.Dir(exe)
 }
 
-func loadIconConfig() IconConfig {
-	defaultIcon := GetAppIconPath()
+// run starts the process in the mode given on the command line and
+// returns once its window closes. Errors are logged before returning,
+// so the deferred log close runs before main exits.
+func run() error {
+	title := "待办事项"
+	modePtr := flag.String("mode", "main", "Application mode: 'main' or 'ball'")
+	dataDirPtr := flag.String("data-dir", "", "Directory for todos, settings and logs (default: per-user data dir, or next to the exe in portable mode)")
+	keyStdinPtr := flag.Bool("key-stdin", false, "Read the unlock key from stdin (used when main launches the ball)")
+	flag.Parse()
+	mode := *modePtr
 
-	// Default
-	config := IconConfig{
-		TrayIcon:       defaultIcon,
-		MainWindowIcon: defaultIcon,
-		TaskbarIcon:    defaultIcon,
+	dataDir, portable, dirErr := storage.ResolveDataDir(*dataDirPtr)
+	if dirErr != nil {
+		// Last resort: keep data next to the exe like older versions
+		dataDir = getAppDir()
 	}
 
-	appDir := getAppDir()
-	configPath := filepath.Join(appDir, "icon_config.json")
-	data, err := os.ReadFile(configPath)
-	if err == nil {
-		json.Unmarshal(data, &config)
+	logCloser, logErr := applog.Setup(dataDir, mode)
+	defer logCloser.Close()
+	if logErr != nil {
+		slog.Warn("cannot open log file, logging to stderr", "err", logErr)
 	}
-
-	// Resolve relative paths
-	resolvePath := func(path string) string {
-		fullPath := path
-		if path != "" && !filepath.IsAbs(path) {
-			fullPath = filepath.Join(appDir, path)
-		}
-
-		// Check if file exists, if not use default
-		if _, err := os.Stat(fullPath); err != nil {
-			return defaultIcon
-		}
-		return fullPath
+	if dirErr != nil {
+		slog.Error("cannot resolve user data dir, using exe dir", "err", dirErr)
 	}
-
-	config.TrayIcon = resolvePath(config.TrayIcon)
-	config.MainWindowIcon = resolvePath(config.MainWindowIcon)
-	config.TaskbarIcon = resolvePath(config.TaskbarIcon)
-
-	return config
-}
-
-func main() {
-	iconConfig := loadIconConfig()
-	modePtr := flag.String("mode", "main", "Application mode: 'main' or 'ball'")
-	flag.Parse()
-	mode := *modePtr
+	slog.Info("starting", "args", os.Args[1:], "data_dir", dataDir, "portable", portable)
 
 	// Single Instance Check using Mutex
 	// Using updated mutex names to avoid conflicts with ghost processes
@@ -117,78 +93,39 @@ func main() {
 			}
 			// If window not found but mutex exists, it might be a ghost process or different user.
 			// We exit anyway to strictly enforce single instance.
-			os.Exit(0)
+			return nil
 		}
 	} else if mode == "ball" {
 		_, err := platform.CreateMutex("Global\\TodoBallFloatMutex_v2")
 		if err != nil {
 			// Already running, exit
-			os.Exit(0)
+			return nil
 		}
 	}
 
-	app := NewApp(mode, iconConfig)
-
-	// Start System Tray in a goroutine (Only in Main mode)
-	if mode == "main" {
-		println("Initializing System Tray...")
-		// Note: On Windows this works in a goroutine as long as it has its own message loop (which systray.Run provides)
-		go func() {
-			systray.Run(func() {
-				println("System Tray Ready")
-
-				// Try to load from config first
-				var trayIconBytes []byte
-				if iconConfig.TrayIcon != "" {
-					trayIconBytes, _ = os.ReadFile(iconConfig.TrayIcon)
-				}
-
-				if len(trayIconBytes) > 0 {
-					systray.SetIcon(trayIconBytes)
-				} else if len(iconData) > 0 {
-					systray.SetIcon(iconData)
-				} else {
-					println("Error: No icon data available")
-				}
-
-				systray.SetTitle("待办事项")
-				systray.SetTooltip("待办事项")
-
-				mOpen := systray.AddMenuItem("显示主界面", "Show Main Window")
-				mOpen.Click(func() {
-					app.OpenMain()
-				})
-
-				mQuit := systray.AddMenuItem("退出", "Quit Application")
-				mQuit.Click(func() {
-					systray.Quit()
-					app.FullQuit()
-				})
-
-			}, func() {
-				// Cleanup
-			})
-		}()
+	app, err := NewApp(mode, dataDir)
+	if err != nil {
+		slog.Error("init failed", "err", err)
+		platform.MessageBox(title, err.Error())
+		return err
+	}
+	applog.SetLevel(app.Store.Config().LogLevel)
+	slog.Info("storage opened", "backend", app.Store.Backend(), "encrypted", app.Store.IsEncrypted())
+	if *keyStdinPtr {
+		unlockFromStdin(app)
 	}
 
 	w := 1080
 	h := 720
 	// Load size from config if available
-	if app.Store.Config.WindowWidth > 0 && app.Store.Config.WindowHeight > 0 {
-		w = app.Store.Config.WindowWidth
-		h = app.Store.Config.WindowHeight
-	}
-
-	// Override for ball mode
-	if mode == "ball" {
-		w = 100
-		h = 80
+	if app.Store.Config().WindowWidth > 0 && app.Store.Config().WindowHeight > 0 {
+		w = app.Store.Config().WindowWidth
+		h = app.Store.Config().WindowHeight
 	}
 
 	frameless := false
 	resizable := false
 	alwaysOnTop := false
-	title := "待办事项"
 
 	// Wails options
 	appOptions := &options.App{
@@ -204,9 +141,11 @@ func main() {
 		BackgroundColour: &options.RGBA{R: 255, G: 255, B: 255, A: 255},
 		OnStartup:        app.startup,
 		OnBeforeClose:    app.BeforeClose,
+		ErrorFormatter:   apperr.Format,
 		OnShutdown: func(ctx context.Context) {
 			if mode == "main" {
 				systray.Quit()
+				app.stopHotkeys()
 			}
 		},
 		Bind: []interface{}{
@@ -215,8 +154,9 @@ func main() {
 	}
 
 	if mode == "ball" {
-		appOptions.Width = 80
-		appOptions.Height = 80
+		g := ballGeometryFor(app.Store.Config())
+		appOptions.Width = g.Width
+		appOptions.Height = g.Height
 		appOptions.Frameless = true
 		appOptions.AlwaysOnTop = true
 		appOptions.BackgroundColour = &options.RGBA{R: 0, G: 0, B: 0, A: 0}
@@ -252,18 +192,20 @@ func main() {
 		})
 
 		if browserPath != "" {
-			println("Using local WebView2 runtime at:", browserPath)
+			slog.Info("using local WebView2 runtime", "path", browserPath)
 			appOptions.Windows.WebviewBrowserPath = browserPath
 		} else {
 			// Fallback to the dir itself if scan fails
-			println("Using local WebView2 runtime at:", webView2Dir)
+			slog.Info("using local WebView2 runtime", "path", webView2Dir)
 			appOptions.Windows.WebviewBrowserPath = webView2Dir
 		}
 	}
 
-	err := wails.Run(appOptions)
+	err = wails.Run(appOptions)
 
 	if err != nil {
-		println("Error:", err.Error())
+		slog.Error("wails run failed", "err", err)
 	}
+	slog.Info("exiting")
+	return err
 }
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// Todo filters by start date, see FilterTodos
+const (
+	FilterAll       = "all"       // Every todo
+	FilterAvailable = "available" // Pending and started: what the ball counts
+	FilterScheduled = "scheduled" // Pending with a start date still to come
+)
+
+// IsDeferred reports whether the item's start date is still to come.
+func (t TodoItem) IsDeferred(now time.Time) bool {
+	return t.DeferUntil != nil && now.Before(*t.DeferUntil)
+}
+
+// IsAvailable reports whether a pending item can be worked on now.
+func (t TodoItem) IsAvailable(now time.Time) bool {
+	return !t.Completed && !t.IsDeferred(now)
+}
+
+// FilterTodos returns the todos matching filter at now. Scheduled todos
+// come soonest start first; the others keep their order. Unknown filters
+// return nil.
+func FilterTodos(todos []TodoItem, filter string, now time.Time) []TodoItem {
+	var out []TodoItem
+	for _, t := range todos {
+		switch filter {
+		case "", FilterAll:
+		case FilterAvailable:
+			if !t.IsAvailable(now) {
+				continue
+			}
+		case FilterScheduled:
+			if t.Completed || !t.IsDeferred(now) {
+				continue
+			}
+		default:
+			return nil
+		}
+		out = append(out, t)
+	}
+	if filter == FilterScheduled {
+		sort.SliceStable(out, func(i, j int) bool { return out[i].DeferUntil.Before(*out[j].DeferUntil) })
+	}
+	return out
+}
+
+// ValidFilter reports whether FilterTodos knows filter.
+func ValidFilter(filter string) bool {
+	switch filter {
+	case "", FilterAll, FilterAvailable, FilterScheduled:
+		return true
+	}
+	return false
+}
+
+// NextStart returns the earliest start date after now among pending
+// todos, when the next deferred one becomes available.
+func NextStart(todos []TodoItem, now time.Time) (time.Time, bool) {
+	var next time.Time
+	for _, t := range todos {
+		if t.Completed || !t.IsDeferred(now) {
+			continue
+		}
+		if next.IsZero() || t.DeferUntil.Before(next) {
+			next = *t.DeferUntil
+		}
+	}
+	return next, !next.IsZero()
+}
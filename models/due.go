@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+	"todo-ball/dates"
+)
+
+// Location returns the zone the item's due date is kept in.
+func (t TodoItem) Location() *time.Location {
+	return dates.Zone(t.TimeZone)
+}
+
+// DueLocal returns the due date on the wall clock of the item's zone.
+func (t TodoItem) DueLocal() time.Time {
+	return t.DueDate.In(t.Location())
+}
+
+// DueDay returns midnight at the start of the day the item is due. For an
+// all-day item that is the day itself, not the midnight ending it.
+func (t TodoItem) DueDay() time.Time {
+	due := t.DueDate
+	if t.AllDay {
+		due = due.Add(-time.Nanosecond)
+	}
+	return dates.StartOfDay(due, t.Location())
+}
+
+// WithDue sets the due date from a wall-clock time in zone. For an all-day
+// item only the day of wall counts and the item falls due when it ends.
+func (t TodoItem) WithDue(wall time.Time, zone string, allDay bool) TodoItem {
+	loc := dates.Zone(zone)
+	y, m, d := wall.Date()
+	if allDay {
+		t.DueDate = time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+	} else {
+		t.DueDate = time.Date(y, m, d, wall.Hour(), wall.Minute(), wall.Second(), 0, loc)
+	}
+	t.TimeZone, t.AllDay = zone, allDay
+	return t
+}
+
+// MoveDue moves the due date by d, keeping the wall-clock time for whole
+// days, see dates.Add.
+func (t TodoItem) MoveDue(d time.Duration) TodoItem {
+	t.DueDate = dates.Add(t.DueDate, d, t.Location())
+	return t
+}
+
+// PinTimeZone gives an item saved before time zones were kept the zone
+// it was entered in, which is the computer's zone, so its wall-clock time
+// stays put when the computer's zone changes later. The instant is kept;
+// only the offset it is written with changes. Reports whether the item
+// changed.
+func (t TodoItem) PinTimeZone(zone string) (TodoItem, bool) {
+	if t.TimeZone != "" || zone == "" || !t.HasDueDate() {
+		return t, false
+	}
+	t.TimeZone = zone
+	t.DueDate = t.DueDate.In(dates.Zone(zone))
+	return t, true
+}
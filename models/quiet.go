@@ -0,0 +1,118 @@
+package models
+
+import "time"
+
+// MaxQuietPeriods bounds AppConfig.QuietHours.
+const MaxQuietPeriods = 20
+
+// QuietPeriod is a weekly span without reminders, such as 22:00–08:00 or
+// all of Saturday. Times are wall-clock times in the local zone, so a
+// period keeps its hours across daylight saving changes. A period that
+// ends before it starts runs past midnight and belongs to the day it
+// starts on.
+type QuietPeriod struct {
+	Days  []int  `json:"days"`  // time.Weekday values (0 is Sunday); empty for every day
+	Start string `json:"start"` // "HH:MM"
+	End   string `json:"end"`   // "HH:MM"; the same as Start for the whole day
+}
+
+// Contains reports whether t falls in the period, judged by t's wall
+// clock in t's location.
+func (p QuietPeriod) Contains(t time.Time) bool {
+	start, ok1 := parseClock(p.Start)
+	end, ok2 := parseClock(p.End)
+	if !ok1 || !ok2 {
+		return false
+	}
+	day := t.Weekday()
+	minute := t.Hour()*60 + t.Minute()
+	switch {
+	case start == end:
+		return p.onDay(day)
+	case start < end:
+		return p.onDay(day) && minute >= start && minute < end
+	default:
+		// Evening part today, or the morning part of a period that
+		// started yesterday. The weekday is stepped back rather than
+		// t by 24 hours, which is not always yesterday around DST.
+		yesterday := (day + 6) % 7
+		return (p.onDay(day) && minute >= start) || (p.onDay(yesterday) && minute < end)
+	}
+}
+
+func (p QuietPeriod) onDay(day time.Weekday) bool {
+	if len(p.Days) == 0 {
+		return true
+	}
+	for _, d := range p.Days {
+		if time.Weekday(d) == day {
+			return true
+		}
+	}
+	return false
+}
+
+// InQuietHours reports whether t falls in any of the periods.
+func InQuietHours(periods []QuietPeriod, t time.Time) bool {
+	for _, p := range periods {
+		if p.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock turns "HH:MM" into minutes after midnight.
+func parseClock(s string) (int, bool) {
+	if len(s) != 5 || s[2] != ':' {
+		return 0, false
+	}
+	digits := [4]int{}
+	for i, pos := range []int{0, 1, 3, 4} {
+		if s[pos] < '0' || s[pos] > '9' {
+			return 0, false
+		}
+		digits[i] = int(s[pos] - '0')
+	}
+	h, m := digits[0]*10+digits[1], digits[2]*10+digits[3]
+	if h > 23 || m > 59 {
+		return 0, false
+	}
+	return h*60 + m, true
+}
+
+func (p QuietPeriod) valid() bool {
+	if _, ok := parseClock(p.Start); !ok {
+		return false
+	}
+	if _, ok := parseClock(p.End); !ok {
+		return false
+	}
+	for _, d := range p.Days {
+		if d < 0 || d > 6 {
+			return false
+		}
+	}
+	return true
+}
+
+// checkQuietHours adds the first problem with periods to v.
+func checkQuietHours(v *validator, periods []QuietPeriod) {
+	if len(periods) > MaxQuietPeriods {
+		v.add("quiet_hours", RuleLength, MaxQuietPeriods)
+		return
+	}
+	for _, p := range periods {
+		if p.valid() {
+			continue
+		}
+		if _, ok := parseClock(p.Start); ok {
+			if _, ok := parseClock(p.End); ok {
+				v.add("quiet_hours", RuleRange, 0, 6) // A weekday is out of range
+				return
+			}
+		}
+		v.add("quiet_hours", RuleFormat, "HH:MM")
+		return
+	}
+}
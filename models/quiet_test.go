@@ -0,0 +1,110 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func mustZone(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("zone %s not available: %v", name, err)
+	}
+	return loc
+}
+
+func TestQuietPeriodContains(t *testing.T) {
+	ny := mustZone(t, "America/New_York")
+	berlin := mustZone(t, "Europe/Berlin")
+	at := func(loc *time.Location, y int, m time.Month, d, h, min int) time.Time {
+		return time.Date(y, m, d, h, min, 0, 0, loc)
+	}
+	// Wall times repeated when the clocks go back are ambiguous to
+	// time.Date, so those instants are given in UTC
+	utc := func(loc *time.Location, m time.Month, d, h, min int) time.Time {
+		return time.Date(2026, m, d, h, min, 0, 0, time.UTC).In(loc)
+	}
+
+	// Fridays 2026-03-06 (before the US change) and the weekend after
+	overnight := QuietPeriod{Days: []int{int(time.Friday)}, Start: "22:00", End: "07:00"}
+	// US clocks go forward on 2026-03-08 02:00 and back on 2026-11-01 02:00
+	nySpring := QuietPeriod{Days: []int{int(time.Sunday)}, Start: "01:30", End: "03:30"}
+	nyFall := QuietPeriod{Days: []int{int(time.Sunday)}, Start: "01:00", End: "02:00"}
+	// An overnight period whose night is the one the clocks change
+	nySpringNight := QuietPeriod{Days: []int{int(time.Saturday)}, Start: "22:00", End: "07:00"}
+	// EU clocks go forward on 2026-03-29 02:00 and back on 2026-10-25 03:00
+	berlinSpring := QuietPeriod{Days: []int{int(time.Sunday)}, Start: "01:00", End: "04:00"}
+	berlinFall := QuietPeriod{Days: []int{int(time.Sunday)}, Start: "02:00", End: "03:00"}
+	wholeSaturday := QuietPeriod{Days: []int{int(time.Saturday)}, Start: "00:00", End: "00:00"}
+	everyDay := QuietPeriod{Start: "12:00", End: "12:00"}
+
+	tests := []struct {
+		name   string
+		period QuietPeriod
+		t      time.Time
+		want   bool
+	}{
+		{"overnight before start", overnight, at(time.UTC, 2026, 3, 6, 21, 59), false},
+		{"overnight at start", overnight, at(time.UTC, 2026, 3, 6, 22, 0), true},
+		{"overnight before midnight", overnight, at(time.UTC, 2026, 3, 6, 23, 59), true},
+		{"overnight next weekday morning", overnight, at(time.UTC, 2026, 3, 7, 6, 59), true},
+		{"overnight at end", overnight, at(time.UTC, 2026, 3, 7, 7, 0), false},
+		{"overnight next weekday evening", overnight, at(time.UTC, 2026, 3, 7, 22, 30), false},
+		{"overnight morning of the start day", overnight, at(time.UTC, 2026, 3, 6, 6, 0), false},
+
+		{"NY spring before the gap", nySpring, at(ny, 2026, 3, 8, 1, 45), true},
+		{"NY spring after the gap", nySpring, at(ny, 2026, 3, 8, 3, 10), true},
+		{"NY spring at end", nySpring, at(ny, 2026, 3, 8, 3, 30), false},
+		{"NY spring before start", nySpring, at(ny, 2026, 3, 8, 1, 29), false},
+		{"NY spring night evening", nySpringNight, at(ny, 2026, 3, 7, 23, 0), true},
+		{"NY spring night last minute", nySpringNight, at(ny, 2026, 3, 8, 6, 59), true},
+		{"NY spring night at end", nySpringNight, at(ny, 2026, 3, 8, 7, 0), false},
+		{"NY fall first 01:30", nyFall, utc(ny, time.November, 1, 5, 30), true},    // EDT
+		{"NY fall repeated 01:30", nyFall, utc(ny, time.November, 1, 6, 30), true}, // EST
+		{"NY fall after repeat", nyFall, at(ny, 2026, 11, 1, 2, 0), false},
+
+		{"Berlin spring before the gap", berlinSpring, at(berlin, 2026, 3, 29, 1, 30), true},
+		{"Berlin spring after the gap", berlinSpring, at(berlin, 2026, 3, 29, 3, 30), true},
+		{"Berlin spring at end", berlinSpring, at(berlin, 2026, 3, 29, 4, 0), false},
+		{"Berlin fall first 02:30", berlinFall, utc(berlin, time.October, 25, 0, 30), true},    // CEST
+		{"Berlin fall repeated 02:30", berlinFall, utc(berlin, time.October, 25, 1, 30), true}, // CET
+		{"Berlin fall at end", berlinFall, utc(berlin, time.October, 25, 2, 0), false},
+
+		{"start equals end, day before", wholeSaturday, at(time.UTC, 2026, 3, 6, 23, 59), false},
+		{"start equals end, midnight", wholeSaturday, at(time.UTC, 2026, 3, 7, 0, 0), true},
+		{"start equals end, last minute", wholeSaturday, at(time.UTC, 2026, 3, 7, 23, 59), true},
+		{"start equals end, day after", wholeSaturday, at(time.UTC, 2026, 3, 8, 0, 0), false},
+		{"start equals end, every day", everyDay, at(ny, 2026, 11, 1, 1, 30), true},
+	}
+	for _, tt := range tests {
+		if got := tt.period.Contains(tt.t); got != tt.want {
+			t.Errorf("%s: Contains(%s) = %v, want %v", tt.name, tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestInQuietHours(t *testing.T) {
+	ny := mustZone(t, "America/New_York")
+	periods := []QuietPeriod{
+		{Days: []int{int(time.Saturday)}, Start: "22:00", End: "07:00"},
+		{Days: []int{int(time.Sunday)}, Start: "12:00", End: "13:00"},
+	}
+	tests := []struct {
+		t    time.Time
+		want bool
+	}{
+		{time.Date(2026, 3, 8, 6, 30, 0, 0, ny), true}, // First period, across the spring-forward night
+		{time.Date(2026, 3, 8, 12, 30, 0, 0, ny), true},
+		{time.Date(2026, 3, 8, 9, 0, 0, 0, ny), false},
+		{time.Date(2026, 3, 7, 21, 0, 0, 0, ny), false},
+	}
+	for _, tt := range tests {
+		if got := InQuietHours(periods, tt.t); got != tt.want {
+			t.Errorf("InQuietHours(%s) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+	if InQuietHours(nil, time.Now()) {
+		t.Error("no periods reported quiet")
+	}
+}
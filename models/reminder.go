@@ -0,0 +1,146 @@
+package models
+
+import (
+	"sort"
+	"time"
+	"todo-ball/dates"
+)
+
+// Units of a relative reminder
+const (
+	UnitMinutes = "minutes"
+	UnitHours   = "hours"
+	UnitDays    = "days"
+)
+
+// MaxReminders is the most reminders one todo can have.
+const MaxReminders = 10
+
+// Reminder is one moment a todo reminds the user: Before units ahead of
+// its due date, or at the instant At. Relative reminders move with the
+// due date.
+type Reminder struct {
+	Before int        `json:"before,omitempty"`
+	Unit   string     `json:"unit,omitempty"`                // "minutes", "hours" or "days"; empty if At is set
+	At     *time.Time `json:"at,omitempty" ts_type:"string"` // Absolute reminders only
+}
+
+// RemindBefore returns a reminder d ahead of the due date, in the largest
+// unit that d is a whole number of.
+func RemindBefore(d time.Duration) Reminder {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return Reminder{Before: int(d / (24 * time.Hour)), Unit: UnitDays}
+	case d%time.Hour == 0:
+		return Reminder{Before: int(d / time.Hour), Unit: UnitHours}
+	}
+	return Reminder{Before: int((d + time.Minute - 1) / time.Minute), Unit: UnitMinutes}
+}
+
+// Time returns when r fires for item. Days are calendar days in the
+// item's zone, so "1 day before 09:00" is 09:00 the day before.
+func (r Reminder) Time(item TodoItem) time.Time {
+	if r.At != nil {
+		return *r.At
+	}
+	switch r.Unit {
+	case UnitDays:
+		return dates.AddDays(item.DueDate, -r.Before, item.Location())
+	case UnitHours:
+		return item.DueDate.Add(-time.Duration(r.Before) * time.Hour)
+	}
+	return item.DueDate.Add(-time.Duration(r.Before) * time.Minute)
+}
+
+// maxBefore is the largest Before in unit that stays within
+// MaxReminderDays. Before is compared with it rather than multiplied out,
+// which could overflow a time.Duration.
+func maxBefore(unit string) int {
+	switch unit {
+	case UnitDays:
+		return MaxReminderDays
+	case UnitHours:
+		return MaxReminderDays * 24
+	}
+	return MaxReminderDays * 24 * 60
+}
+
+// ReminderList returns the item's reminders. Items saved before todos had
+// several get one from ReminderDays until MigrateReminderDays runs.
+func (t TodoItem) ReminderList() []Reminder {
+	if len(t.Reminders) == 0 && t.ReminderDays > 0 {
+		return []Reminder{{Before: t.ReminderDays, Unit: UnitDays}}
+	}
+	return t.Reminders
+}
+
+// ReminderTimes returns when the item's reminders fire, earliest first.
+func (t TodoItem) ReminderTimes() []time.Time {
+	if !t.HasDueDate() {
+		return nil
+	}
+	list := t.ReminderList()
+	times := make([]time.Time, len(list))
+	for i, r := range list {
+		times[i] = r.Time(t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times
+}
+
+// LastReminder returns the latest reminder that fired at or before now,
+// the one nearest to now. A new one replaces it each time another fires.
+func (t TodoItem) LastReminder(now time.Time) (time.Time, bool) {
+	var last time.Time
+	for _, at := range t.ReminderTimes() {
+		if at.After(now) {
+			break
+		}
+		last = at
+	}
+	return last, !last.IsZero()
+}
+
+// MigrateReminderDays turns ReminderDays into a relative reminder.
+// Reports whether the item changed.
+func (t TodoItem) MigrateReminderDays() (TodoItem, bool) {
+	if t.ReminderDays == 0 {
+		return t, false
+	}
+	if len(t.Reminders) == 0 {
+		t.Reminders = []Reminder{{Before: t.ReminderDays, Unit: UnitDays}}
+	}
+	t.ReminderDays = 0
+	return t, true
+}
+
+func checkReminders(v *validator, t TodoItem) {
+	if len(t.Reminders) > MaxReminders {
+		v.add("reminders", RuleLength, MaxReminders)
+		return
+	}
+	for _, r := range t.Reminders {
+		if r.At != nil {
+			if r.Unit != "" || r.Before != 0 {
+				v.add("reminders", RuleFormat, "before+unit / at")
+				return
+			}
+			if t.HasDueDate() && r.At.After(t.DueDate) {
+				v.add("reminders", RuleNotAfter, t.DueLocal().Format("2006-01-02 15:04"))
+				return
+			}
+			continue
+		}
+		switch r.Unit {
+		case UnitMinutes, UnitHours, UnitDays:
+		default:
+			v.add("reminders", RuleOneOf, UnitMinutes+"/"+UnitHours+"/"+UnitDays)
+			return
+		}
+		// At most MaxReminderDays ahead, whatever the unit
+		if r.Before < 0 || r.Before > maxBefore(r.Unit) {
+			v.add("reminders", RuleRange, 0, MaxReminderDays)
+			return
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Colour modes of the main window
+const (
+	ColorModeLight  = "light"
+	ColorModeDark   = "dark"
+	ColorModeSystem = "system" // Follow the Windows setting
+)
+
+const (
+	MaxThemeName = 30
+	MaxThemes    = 50 // User-defined ones
+)
+
+// Theme is a named set of appearance settings. Built-in themes ship
+// with the app; user themes are kept in themes.json.
+type Theme struct {
+	Name            string  `json:"name"`
+	Builtin         bool    `json:"builtin,omitempty"`
+	ColorMode       string  `json:"color_mode"`
+	ThemeColor      string  `json:"theme_color"`
+	EdgeLightColor  string  `json:"edge_light_color"`
+	ReminderColor   string  `json:"reminder_color"`
+	FloatingOpacity float64 `json:"floating_opacity"`
+}
+
+// BuiltinThemes returns the presets, the first matching DefaultConfig.
+func BuiltinThemes() []Theme {
+	return []Theme{
+		{Name: "经典", ColorMode: ColorModeLight, ThemeColor: "#2ecc71", EdgeLightColor: "#2ecc71", ReminderColor: "#e74c3c", FloatingOpacity: 1.0},
+		{Name: "海洋", ColorMode: ColorModeLight, ThemeColor: "#3498db", EdgeLightColor: "#2980b9", ReminderColor: "#e67e22", FloatingOpacity: 1.0},
+		{Name: "森林", ColorMode: ColorModeLight, ThemeColor: "#27ae60", EdgeLightColor: "#16a085", ReminderColor: "#c0392b", FloatingOpacity: 0.9},
+		{Name: "日落", ColorMode: ColorModeLight, ThemeColor: "#e67e22", EdgeLightColor: "#d35400", ReminderColor: "#8e44ad", FloatingOpacity: 1.0},
+		{Name: "暗夜", ColorMode: ColorModeDark, ThemeColor: "#9b59b6", EdgeLightColor: "#34495e", ReminderColor: "#e74c3c", FloatingOpacity: 0.85},
+	}
+}
+
+// FindBuiltinTheme returns the preset called name.
+func FindBuiltinTheme(name string) (Theme, bool) {
+	for _, t := range BuiltinThemes() {
+		if t.Name == name {
+			t.Builtin = true
+			return t, true
+		}
+	}
+	return Theme{}, false
+}
+
+// ThemeFrom captures the appearance settings of c as a theme.
+func ThemeFrom(name string, c AppConfig) Theme {
+	return Theme{
+		Name:            strings.TrimSpace(name),
+		ColorMode:       c.ColorMode,
+		ThemeColor:      c.ThemeColor,
+		EdgeLightColor:  c.EdgeLightColor,
+		ReminderColor:   c.ReminderColor,
+		FloatingOpacity: c.FloatingOpacity,
+	}
+}
+
+// WithTheme returns c with the appearance settings of t.
+func (c AppConfig) WithTheme(t Theme) AppConfig {
+	c.ThemeName = t.Name
+	c.ColorMode = t.ColorMode
+	c.ThemeColor = t.ThemeColor
+	c.EdgeLightColor = t.EdgeLightColor
+	c.ReminderColor = t.ReminderColor
+	c.FloatingOpacity = t.FloatingOpacity
+	return c
+}
+
+// ValidateTheme checks a theme before it is saved or imported.
+func ValidateTheme(t Theme) error {
+	var v validator
+	name := strings.TrimSpace(t.Name)
+	if name == "" {
+		v.add("name", RuleRequired)
+	} else if utf8.RuneCountInString(name) > MaxThemeName {
+		v.add("name", RuleLength, MaxThemeName)
+	}
+	if !validColorMode(t.ColorMode) {
+		v.add("color_mode", RuleOneOf, ColorModeLight+"/"+ColorModeDark+"/"+ColorModeSystem)
+	}
+	if !IsHexColor(t.ThemeColor) {
+		v.add("theme_color", RuleFormat, "#rrggbb")
+	}
+	if t.EdgeLightColor != "" && !IsHexColor(t.EdgeLightColor) {
+		v.add("edge_light_color", RuleFormat, "#rrggbb")
+	}
+	if !IsHexColor(t.ReminderColor) {
+		v.add("reminder_color", RuleFormat, "#rrggbb")
+	}
+	if t.FloatingOpacity < MinOpacity || t.FloatingOpacity > MaxOpacity {
+		v.add("floating_opacity", RuleRange, MinOpacity, MaxOpacity)
+	}
+	return v.err()
+}
+
+func validColorMode(mode string) bool {
+	switch mode {
+	case ColorModeLight, ColorModeDark, ColorModeSystem:
+		return true
+	}
+	return false
+}
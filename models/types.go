@@ -5,34 +5,192 @@ import (
 )
 
 type TodoItem struct {
-	ID        string    `json:"id"`
-	Title     string    `json:"title"`
-	DueDate   time.Time `json:"due_date" ts_type:"string"`
-	Completed bool      `json:"completed"`
-	Deleted   bool      `json:"deleted"`
-	CreatedAt time.Time `json:"created_at" ts_type:"string"`
+	ID      string    `json:"id"`
+	Title   string    `json:"title"`
+	DueDate time.Time `json:"due_date" ts_type:"string"` // The instant it is due, written with TimeZone's offset
+	// IANA zone the due time was set in, "" for the computer's zone. The
+	// due date is shown in this zone, so it doesn't move when travelling
+	TimeZone string `json:"time_zone,omitempty"`
+	// Due at the end of DueDate's day: DueDate is the midnight ending it
+	AllDay bool `json:"all_day,omitempty"`
+	// Start date: hidden from the ball, reminders and urgency until then.
+	// Nil if the item is available now
+	DeferUntil  *time.Time `json:"defer_until,omitempty" ts_type:"string"`
+	Completed   bool       `json:"completed"`
+	Deleted     bool       `json:"deleted"`
+	CreatedAt   time.Time  `json:"created_at" ts_type:"string"`
 	CompletedAt *time.Time `json:"completed_at,omitempty" ts_type:"string"`
-	ReminderDays int    `json:"reminder_days"` // Days before due to remind (override global if needed, or primary)
+	Reminders   []Reminder `json:"reminders,omitempty"` // When to remind, see Reminder
+	// Deprecated: a single reminder this many days ahead, as saved by older
+	// versions. Moved into Reminders by MigrateReminderDays
+	ReminderDays int      `json:"reminder_days,omitempty"`
+	Priority     string   `json:"priority,omitempty"` // "low", "normal" or "high"; empty means normal
+	Tags         []string `json:"tags,omitempty"`
+	// Completion changes, oldest first. Kept when the item is archived
+	History []TodoEvent `json:"history,omitempty"`
+}
+
+// TodoEvent is one entry of a todo's history.
+type TodoEvent struct {
+	Type string    `json:"type"` // EventCompleted or EventUncompleted
+	At   time.Time `json:"at" ts_type:"string"`
+}
+
+const (
+	EventCompleted   = "completed"
+	EventUncompleted = "uncompleted"
+)
+
+// ArchiveEntry is a completed todo found by an archive search.
+type ArchiveEntry struct {
+	Todo     TodoItem `json:"todo"`
+	ListID   string   `json:"list_id"`
+	ListName string   `json:"list_name"`
+	Archived bool     `json:"archived"` // False while it is still in its list
+}
+
+// ArchivePage is one page of archive search results.
+type ArchivePage struct {
+	Entries  []ArchiveEntry `json:"entries"`
+	Total    int            `json:"total"`
+	Page     int            `json:"page"` // 1-based
+	PageSize int            `json:"page_size"`
 }
 
 type AppConfig struct {
-	ThemeColor       string  `json:"theme_color"`      // Hex code
-	FloatingOpacity  float64 `json:"floating_opacity"` // 0.1 to 1.0
-	CustomIconPath   string  `json:"custom_icon_path"` // Path to png
-	EdgeLightColor   string  `json:"edge_light_color"` // Normal state border color (optional, or use ThemeColor)
-	ReminderColor    string  `json:"reminder_color"`   // Urgent state border color
-	StartOnBoot      bool    `json:"start_on_boot"`
-	NotificationDays int     `json:"notification_days"`  // N days before due
-	FloatingBallMode string  `json:"floating_ball_mode"` // "standard" or "custom"
-	WindowWidth      int     `json:"window_width"`
-	WindowHeight     int     `json:"window_height"`
+	ThemeColor       string       `json:"theme_color"`      // Hex code
+	FloatingOpacity  float64      `json:"floating_opacity"` // 0.1 to 1.0
+	CustomIconPath   string       `json:"custom_icon_path"` // Asset name from SelectFile, "" for none
+	EdgeLightColor   string       `json:"edge_light_color"` // Normal state border color (optional, or use ThemeColor)
+	ReminderColor    string       `json:"reminder_color"`   // Urgent state border color
+	StartOnBoot      bool         `json:"start_on_boot"`
+	NotificationDays int          `json:"notification_days"`  // N days before due
+	FloatingBallMode string       `json:"floating_ball_mode"` // "standard" or "custom"; superseded by BallContent
+	WindowWidth      int          `json:"window_width"`
+	WindowHeight     int          `json:"window_height"`
+	Hotkeys          HotkeyConfig `json:"hotkeys"`
+	LogLevel         string       `json:"log_level"`       // "debug", "info", "warn" or "error"
+	ActiveList       string       `json:"active_list"`     // TodoList.ID shown in the main window
+	BallCountMode    string       `json:"ball_count_mode"` // "active" or "combined"
+	// Completed todos older than this many days move to the archive; 0 keeps them
+	ArchiveAfterDays int `json:"archive_after_days"`
+	// Focus timer lengths in minutes; a 0 break goes straight back to idle
+	FocusWorkMinutes  int `json:"focus_work_minutes"`
+	FocusBreakMinutes int `json:"focus_break_minutes"`
+	// Time tracking pauses after this many minutes without input; 0 never
+	IdleMinutes int `json:"idle_minutes"`
+	// Main window colours: "light", "dark" or "system"
+	ColorMode string `json:"color_mode"`
+	// Theme last applied, "" once the colours are edited by hand
+	ThemeName string `json:"theme_name"`
+	// Ball width in logical pixels; a pill is half as tall
+	BallSize    int    `json:"ball_size"`
+	BallShape   string `json:"ball_shape"`   // "circle", "rounded" or "pill"
+	BallContent string `json:"ball_content"` // "count", "countdown", "progress" or "icon"
+	// Mouse input passes through the ball; toggled by hotkey or tray
+	ClickThrough bool `json:"click_through"`
+	// Also pass input through while a fullscreen app is in front
+	ClickThroughFullscreen bool `json:"click_through_fullscreen"`
+	// The ball fades to FadeOpacity after this many seconds without the
+	// cursor on it; 0 never
+	FadeAfterSeconds int     `json:"fade_after_seconds"`
+	FadeOpacity      float64 `json:"fade_opacity"`
+	// The user counts as busy while a fullscreen app is in front, or while
+	// Windows is in presentation mode
+	BusyFullscreen   bool `json:"busy_fullscreen"`
+	BusyPresentation bool `json:"busy_presentation"`
+	// What happens to the ball while busy: "hide", "lower" or "none"
+	BusyBallAction string `json:"busy_ball_action"`
+	// Hold reminders while busy and show them once the user is free
+	QueueReminders bool `json:"queue_reminders"`
+	// No reminders, and the ball keeps its normal colour, during these
+	QuietHours []QuietPeriod `json:"quiet_hours"`
+}
+
+// TodoList is one entry of the list registry (lists.json).
+// Each list keeps its todos in its own file.
+type TodoList struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	File      string    `json:"file"`            // File name inside the data dir
+	Color     string    `json:"color,omitempty"` // Ball colour while this list is active; empty uses EdgeLightColor
+	CreatedAt time.Time `json:"created_at" ts_type:"string"`
+	// Leave this list out of the ball's combined count
+	ExcludeFromBall bool `json:"exclude_from_ball,omitempty"`
+}
+
+// EncryptionStatus tells the UI whether to show the unlock screen.
+type EncryptionStatus struct {
+	Enabled bool `json:"enabled"`
+	Locked  bool `json:"locked"`
+}
+
+// HotkeyConfig holds system-wide shortcuts such as "Ctrl+Alt+N".
+// An empty string disables the shortcut.
+type HotkeyConfig struct {
+	QuickAdd       string `json:"quick_add"`       // Show main window and focus the add box
+	ToggleMain     string `json:"toggle_main"`     // Show/hide the main window
+	ToggleBall     string `json:"toggle_ball"`     // Show/hide the floating ball
+	CompleteUrgent string `json:"complete_urgent"` // Mark the most urgent pending todo as done
+	ClickThrough   string `json:"click_through"`   // Toggle click-through of the ball
 }
 
+const (
+	PriorityLow    = "low"
+	PriorityNormal = "normal"
+	PriorityHigh   = "high"
+)
+
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+// DefaultListID is the list backed by the original todos.json
+const DefaultListID = "default"
+
+const (
+	BallCountActive   = "active"
+	BallCountCombined = "combined"
+)
+
 const (
 	ModeStandard = "standard"
 	ModeCustom   = "custom"
 )
 
+const (
+	BallShapeCircle  = "circle"
+	BallShapeRounded = "rounded" // Rounded square
+	BallShapePill    = "pill"
+)
+
+// What the ball shows. A running focus timer replaces the count and
+// countdown, and fills the progress ring.
+const (
+	BallContentCount     = "count"     // Pending todos, over the custom icon if set
+	BallContentCountdown = "countdown" // Time to the next due todo
+	BallContentProgress  = "progress"  // Ring of completed todos around the count
+	BallContentIcon      = "icon"      // Custom icon only
+)
+
+// What the ball does while the user is busy
+const (
+	BusyActionHide  = "hide"  // Hidden until the user is free
+	BusyActionLower = "lower" // No longer above other windows
+	BusyActionNone  = "none"  // Stays; only reminders are held back
+)
+
+// BallContentFor maps the old FloatingBallMode to a content mode.
+func BallContentFor(mode string) string {
+	if mode == ModeCustom {
+		return BallContentIcon
+	}
+	return BallContentCount
+}
+
 func DefaultConfig() AppConfig {
 	return AppConfig{
 		ThemeColor:       "#2ecc71",
@@ -43,5 +201,27 @@ func DefaultConfig() AppConfig {
 		FloatingBallMode: ModeStandard,
 		WindowWidth:      1080,
 		WindowHeight:     720,
+		Hotkeys: HotkeyConfig{
+			QuickAdd:   "Ctrl+Alt+N",
+			ToggleMain: "Ctrl+Alt+T",
+			ToggleBall: "Ctrl+Alt+B",
+		},
+		LogLevel:          LogLevelInfo,
+		ActiveList:        DefaultListID,
+		BallCountMode:     BallCountActive,
+		ArchiveAfterDays:  30,
+		FocusWorkMinutes:  25,
+		FocusBreakMinutes: 5,
+		IdleMinutes:       5,
+		ColorMode:         ColorModeLight,
+		BallSize:          80,
+		BallShape:         BallShapeCircle,
+		BallContent:       BallContentCount,
+		FadeOpacity:       0.3,
+		BusyFullscreen:    true,
+		BusyPresentation:  true,
+		BusyBallAction:    BusyActionHide,
+		QueueReminders:    true,
+		QuietHours:        []QuietPeriod{},
 	}
 }
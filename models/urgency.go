@@ -0,0 +1,55 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// HasDueDate reports whether the item has a due date set.
+func (t TodoItem) HasDueDate() bool {
+	return !t.DueDate.IsZero()
+}
+
+// IsOverdue reports whether a pending item is past its due date.
+// Deferred items are never overdue until they start.
+func (t TodoItem) IsOverdue(now time.Time) bool {
+	return t.IsAvailable(now) && t.HasDueDate() && t.DueDate.Before(now)
+}
+
+// IsUpcoming reports whether a pending item is due within its reminder window.
+// Matches the ball's colouring: due in the future and the reminder nearest
+// to now has fired.
+func (t TodoItem) IsUpcoming(now time.Time) bool {
+	if !t.IsAvailable(now) || !t.HasDueDate() || t.DueDate.Before(now) {
+		return false
+	}
+	_, fired := t.LastReminder(now)
+	return fired
+}
+
+// SortByUrgency orders todos so the one to handle first comes first:
+// pending items by due date (most overdue first), then pending items
+// without a due date, then completed items.
+func SortByUrgency(todos []TodoItem) {
+	sort.SliceStable(todos, func(i, j int) bool {
+		a, b := todos[i], todos[j]
+		if a.Completed != b.Completed {
+			return !a.Completed
+		}
+		if a.HasDueDate() != b.HasDueDate() {
+			return a.HasDueDate()
+		}
+		return a.DueDate.Before(b.DueDate)
+	})
+}
+
+// MostUrgent returns the pending item that should be handled first.
+func MostUrgent(todos []TodoItem) (TodoItem, bool) {
+	sorted := make([]TodoItem, len(todos))
+	copy(sorted, todos)
+	SortByUrgency(sorted)
+	if len(sorted) == 0 || sorted[0].Completed {
+		return TodoItem{}, false
+	}
+	return sorted[0], true
+}
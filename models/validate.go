@@ -0,0 +1,334 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"todo-ball/dates"
+	"unicode/utf8"
+)
+
+// Validation limits
+const (
+	MaxTitleLength  = 500
+	MaxListName     = 50
+	MaxReminderDays = 365
+	MaxArchiveDays  = 3650
+	MinDueYear      = 1970
+	MaxDueYear      = 9999 // Later times cannot be written as JSON
+	MaxFocusWork    = 180  // Minutes
+	MaxFocusBreak   = 60
+	MaxIdleMinutes  = 240
+	MinBallSize     = 40
+	MaxBallSize     = 160
+	MaxFadeAfter    = 3600 // Seconds
+	MinOpacity      = 0.1
+	MaxOpacity      = 1.0
+	MinWindowWidth  = 400
+	MaxWindowWidth  = 7680
+	MinWindowHeight = 300
+	MaxWindowHeight = 4320
+)
+
+// Validation rules reported in FieldError.Rule
+const (
+	RuleRequired = "required"  // Field must be set
+	RuleRange    = "range"     // Params: min, max
+	RuleLength   = "length"    // Params: max
+	RuleFormat   = "format"    // Params: expected format
+	RuleOneOf    = "one_of"    // Params: allowed values joined by "/"
+	RuleNotAfter = "not_after" // Params: latest allowed value
+)
+
+// FieldError describes one invalid field, using the JSON field name.
+type FieldError struct {
+	Field  string
+	Rule   string
+	Params []any
+}
+
+// ValidationErrors is returned by the Validate functions when at least one field is invalid.
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	parts := make([]string, len(v))
+	for i, f := range v {
+		parts[i] = f.Field + ": " + f.Rule
+	}
+	return "invalid " + strings.Join(parts, ", ")
+}
+
+type validator struct {
+	errs ValidationErrors
+}
+
+func (v *validator) add(field, rule string, params ...any) {
+	v.errs = append(v.errs, FieldError{Field: field, Rule: rule, Params: params})
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// IsHexColor reports whether s is a #rgb or #rrggbb colour.
+func IsHexColor(s string) bool {
+	return hexColor.MatchString(s)
+}
+
+// ValidateTodo checks an item before it is stored.
+func ValidateTodo(t TodoItem) error {
+	var v validator
+	if t.ID == "" {
+		v.add("id", RuleRequired)
+	}
+	title := strings.TrimSpace(t.Title)
+	if title == "" {
+		v.add("title", RuleRequired)
+	} else if utf8.RuneCountInString(title) > MaxTitleLength {
+		v.add("title", RuleLength, MaxTitleLength)
+	}
+	if t.DueDate.IsZero() {
+		v.add("due_date", RuleRequired)
+	} else if y := t.DueDate.UTC().Year(); y < MinDueYear || y > MaxDueYear {
+		v.add("due_date", RuleRange, MinDueYear, MaxDueYear)
+	}
+	if !dates.ValidZone(t.TimeZone) {
+		v.add("time_zone", RuleFormat, "Area/City")
+	} else if t.AllDay && !t.DueDate.Equal(dates.StartOfDay(t.DueDate, t.Location())) {
+		// All-day items fall due at the midnight ending their day
+		v.add("due_date", RuleFormat, "00:00")
+	}
+	if t.DeferUntil != nil && t.HasDueDate() && t.DeferUntil.After(t.DueDate) {
+		v.add("defer_until", RuleNotAfter, t.DueLocal().Format("2006-01-02 15:04"))
+	}
+	if t.ReminderDays < 0 || t.ReminderDays > MaxReminderDays {
+		v.add("reminder_days", RuleRange, 0, MaxReminderDays)
+	}
+	checkReminders(&v, t)
+	switch t.Priority {
+	case "", PriorityLow, PriorityNormal, PriorityHigh:
+	default:
+		v.add("priority", RuleOneOf, PriorityLow+"/"+PriorityNormal+"/"+PriorityHigh)
+	}
+	for _, tag := range t.Tags {
+		if strings.TrimSpace(tag) == "" || strings.ContainsAny(tag, " \t\n#") {
+			v.add("tags", RuleFormat, "#tag")
+			break
+		}
+	}
+	return v.err()
+}
+
+// ValidateConfig checks settings coming from the frontend.
+func ValidateConfig(c AppConfig) error {
+	var v validator
+	if c.FloatingOpacity < MinOpacity || c.FloatingOpacity > MaxOpacity {
+		v.add("floating_opacity", RuleRange, MinOpacity, MaxOpacity)
+	}
+	if !IsHexColor(c.ThemeColor) {
+		v.add("theme_color", RuleFormat, "#rrggbb")
+	}
+	// Edge light colour is optional and falls back to the theme colour
+	if c.EdgeLightColor != "" && !IsHexColor(c.EdgeLightColor) {
+		v.add("edge_light_color", RuleFormat, "#rrggbb")
+	}
+	if !IsHexColor(c.ReminderColor) {
+		v.add("reminder_color", RuleFormat, "#rrggbb")
+	}
+	if c.NotificationDays < 0 || c.NotificationDays > MaxReminderDays {
+		v.add("notification_days", RuleRange, 0, MaxReminderDays)
+	}
+	if c.FloatingBallMode != ModeStandard && c.FloatingBallMode != ModeCustom {
+		v.add("floating_ball_mode", RuleOneOf, ModeStandard+"/"+ModeCustom)
+	}
+	if c.BallCountMode != BallCountActive && c.BallCountMode != BallCountCombined {
+		v.add("ball_count_mode", RuleOneOf, BallCountActive+"/"+BallCountCombined)
+	}
+	switch c.LogLevel {
+	case LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError:
+	default:
+		v.add("log_level", RuleOneOf, LogLevelDebug+"/"+LogLevelInfo+"/"+LogLevelWarn+"/"+LogLevelError)
+	}
+	if c.ArchiveAfterDays < 0 || c.ArchiveAfterDays > MaxArchiveDays {
+		v.add("archive_after_days", RuleRange, 0, MaxArchiveDays)
+	}
+	if c.FocusWorkMinutes < 1 || c.FocusWorkMinutes > MaxFocusWork {
+		v.add("focus_work_minutes", RuleRange, 1, MaxFocusWork)
+	}
+	if c.FocusBreakMinutes < 0 || c.FocusBreakMinutes > MaxFocusBreak {
+		v.add("focus_break_minutes", RuleRange, 0, MaxFocusBreak)
+	}
+	if c.IdleMinutes < 0 || c.IdleMinutes > MaxIdleMinutes {
+		v.add("idle_minutes", RuleRange, 0, MaxIdleMinutes)
+	}
+	if c.BallSize < MinBallSize || c.BallSize > MaxBallSize {
+		v.add("ball_size", RuleRange, MinBallSize, MaxBallSize)
+	}
+	switch c.BallShape {
+	case BallShapeCircle, BallShapeRounded, BallShapePill:
+	default:
+		v.add("ball_shape", RuleOneOf, BallShapeCircle+"/"+BallShapeRounded+"/"+BallShapePill)
+	}
+	switch c.BallContent {
+	case BallContentCount, BallContentCountdown, BallContentProgress, BallContentIcon:
+	default:
+		v.add("ball_content", RuleOneOf, BallContentCount+"/"+BallContentCountdown+"/"+BallContentProgress+"/"+BallContentIcon)
+	}
+	if c.FadeAfterSeconds < 0 || c.FadeAfterSeconds > MaxFadeAfter {
+		v.add("fade_after_seconds", RuleRange, 0, MaxFadeAfter)
+	}
+	if c.FadeOpacity < MinOpacity || c.FadeOpacity > MaxOpacity {
+		v.add("fade_opacity", RuleRange, MinOpacity, MaxOpacity)
+	}
+	switch c.BusyBallAction {
+	case BusyActionHide, BusyActionLower, BusyActionNone:
+	default:
+		v.add("busy_ball_action", RuleOneOf, BusyActionHide+"/"+BusyActionLower+"/"+BusyActionNone)
+	}
+	checkQuietHours(&v, c.QuietHours)
+	if !validColorMode(c.ColorMode) {
+		v.add("color_mode", RuleOneOf, ColorModeLight+"/"+ColorModeDark+"/"+ColorModeSystem)
+	}
+	if utf8.RuneCountInString(c.ThemeName) > MaxThemeName {
+		v.add("theme_name", RuleLength, MaxThemeName)
+	}
+	if c.WindowWidth < MinWindowWidth || c.WindowWidth > MaxWindowWidth {
+		v.add("window_width", RuleRange, MinWindowWidth, MaxWindowWidth)
+	}
+	if c.WindowHeight < MinWindowHeight || c.WindowHeight > MaxWindowHeight {
+		v.add("window_height", RuleRange, MinWindowHeight, MaxWindowHeight)
+	}
+	return v.err()
+}
+
+// ValidateList checks a list registry entry.
+func ValidateList(l TodoList) error {
+	var v validator
+	name := strings.TrimSpace(l.Name)
+	if name == "" {
+		v.add("name", RuleRequired)
+	} else if utf8.RuneCountInString(name) > MaxListName {
+		v.add("name", RuleLength, MaxListName)
+	}
+	if l.Color != "" && !IsHexColor(l.Color) {
+		v.add("color", RuleFormat, "#rrggbb")
+	}
+	return v.err()
+}
+
+// RepairConfig clamps out-of-range values and replaces malformed ones
+// with defaults, so a hand-edited config.json never reaches the UI broken.
+// It returns the repaired config and the fields that were changed.
+func RepairConfig(c AppConfig) (AppConfig, ValidationErrors) {
+	err := ValidateConfig(c)
+	if err == nil {
+		return c, nil
+	}
+	def := DefaultConfig()
+	fields := err.(ValidationErrors)
+	for _, f := range fields {
+		switch f.Field {
+		case "floating_opacity":
+			if c.FloatingOpacity <= 0 {
+				c.FloatingOpacity = def.FloatingOpacity
+			} else {
+				c.FloatingOpacity = clampFloat(c.FloatingOpacity, MinOpacity, MaxOpacity)
+			}
+		case "theme_color":
+			c.ThemeColor = def.ThemeColor
+		case "edge_light_color":
+			c.EdgeLightColor = def.EdgeLightColor
+		case "reminder_color":
+			c.ReminderColor = def.ReminderColor
+		case "notification_days":
+			c.NotificationDays = clampInt(c.NotificationDays, 0, MaxReminderDays)
+		case "floating_ball_mode":
+			c.FloatingBallMode = def.FloatingBallMode
+		case "log_level":
+			c.LogLevel = def.LogLevel
+		case "ball_count_mode":
+			c.BallCountMode = def.BallCountMode
+		case "archive_after_days":
+			c.ArchiveAfterDays = clampInt(c.ArchiveAfterDays, 0, MaxArchiveDays)
+		case "focus_work_minutes":
+			if c.FocusWorkMinutes <= 0 {
+				c.FocusWorkMinutes = def.FocusWorkMinutes
+			} else {
+				c.FocusWorkMinutes = min(c.FocusWorkMinutes, MaxFocusWork)
+			}
+		case "focus_break_minutes":
+			c.FocusBreakMinutes = clampInt(c.FocusBreakMinutes, 0, MaxFocusBreak)
+		case "idle_minutes":
+			c.IdleMinutes = clampInt(c.IdleMinutes, 0, MaxIdleMinutes)
+		case "ball_size":
+			if c.BallSize <= 0 {
+				c.BallSize = def.BallSize
+			} else {
+				c.BallSize = clampInt(c.BallSize, MinBallSize, MaxBallSize)
+			}
+		case "ball_shape":
+			c.BallShape = def.BallShape
+		case "ball_content":
+			c.BallContent = BallContentFor(c.FloatingBallMode)
+		case "fade_after_seconds":
+			c.FadeAfterSeconds = clampInt(c.FadeAfterSeconds, 0, MaxFadeAfter)
+		case "fade_opacity":
+			if c.FadeOpacity <= 0 {
+				c.FadeOpacity = def.FadeOpacity
+			} else {
+				c.FadeOpacity = clampFloat(c.FadeOpacity, MinOpacity, MaxOpacity)
+			}
+		case "busy_ball_action":
+			c.BusyBallAction = def.BusyBallAction
+		case "quiet_hours":
+			valid := []QuietPeriod{}
+			for _, p := range c.QuietHours {
+				if p.valid() && len(valid) < MaxQuietPeriods {
+					valid = append(valid, p)
+				}
+			}
+			c.QuietHours = valid
+		case "color_mode":
+			c.ColorMode = def.ColorMode
+		case "theme_name":
+			c.ThemeName = ""
+		case "window_width":
+			if c.WindowWidth <= 0 {
+				c.WindowWidth = def.WindowWidth
+			} else {
+				c.WindowWidth = clampInt(c.WindowWidth, MinWindowWidth, MaxWindowWidth)
+			}
+		case "window_height":
+			if c.WindowHeight <= 0 {
+				c.WindowHeight = def.WindowHeight
+			} else {
+				c.WindowHeight = clampInt(c.WindowHeight, MinWindowHeight, MaxWindowHeight)
+			}
+		}
+	}
+	return c, fields
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func clampFloat(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestValidateTodoDueDate(t *testing.T) {
+	tests := []struct {
+		due  time.Time
+		rule string // "" if valid
+	}{
+		{time.Date(2026, 3, 4, 17, 0, 0, 0, time.UTC), ""},
+		{time.Time{}, RuleRequired},
+		{time.Date(1969, 12, 31, 23, 0, 0, 0, time.UTC), RuleRange},
+		{time.Date(MaxDueYear, 12, 31, 23, 0, 0, 0, time.UTC), ""},
+		{time.Date(MaxDueYear+1, 1, 1, 0, 0, 0, 0, time.UTC), RuleRange},
+	}
+	for _, tt := range tests {
+		err := ValidateTodo(TodoItem{ID: "1", Title: "t", DueDate: tt.due})
+		var verrs ValidationErrors
+		switch {
+		case tt.rule == "" && err != nil:
+			t.Errorf("due %s: %v", tt.due, err)
+		case tt.rule != "" && (!errors.As(err, &verrs) || verrs[0].Field != "due_date" || verrs[0].Rule != tt.rule):
+			t.Errorf("due %s: error = %v, want due_date %s", tt.due, err, tt.rule)
+		}
+	}
+}
+
+func TestValidateTodoReminders(t *testing.T) {
+	due := time.Date(2026, 3, 4, 17, 0, 0, 0, time.UTC)
+	tests := []struct {
+		r    Reminder
+		want bool
+	}{
+		{Reminder{Before: MaxReminderDays, Unit: UnitDays}, true},
+		{Reminder{Before: MaxReminderDays + 1, Unit: UnitDays}, false},
+		{Reminder{Before: MaxReminderDays * 24, Unit: UnitHours}, true},
+		{Reminder{Before: MaxReminderDays*24 + 1, Unit: UnitHours}, false},
+		{Reminder{Before: MaxReminderDays * 24 * 60, Unit: UnitMinutes}, true},
+		{Reminder{Before: -1, Unit: UnitMinutes}, false},
+		// Would wrap around to a small span if multiplied out
+		{Reminder{Before: math.MaxInt64/int(24*time.Hour) + 1, Unit: UnitDays}, false},
+		{Reminder{Before: math.MaxInt64/int(time.Minute) + 1, Unit: UnitMinutes}, false},
+	}
+	for _, tt := range tests {
+		err := ValidateTodo(TodoItem{ID: "1", Title: "t", DueDate: due, Reminders: []Reminder{tt.r}})
+		if (err == nil) != tt.want {
+			t.Errorf("reminder %+v: error = %v, want valid %v", tt.r, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	procRegisterHotKey     = user32.NewProc("RegisterHotKey")
+	procUnregisterHotKey   = user32.NewProc("UnregisterHotKey")
+	procGetMessageW        = user32.NewProc("GetMessageW")
+	procPostThreadMessageW = user32.NewProc("PostThreadMessageW")
+	procPeekMessageW       = user32.NewProc("PeekMessageW")
+
+	procGetCurrentThreadId = kernel32.NewProc("GetCurrentThreadId")
+)
+
+const (
+	MOD_ALT      = 0x0001
+	MOD_CONTROL  = 0x0002
+	MOD_SHIFT    = 0x0004
+	MOD_WIN      = 0x0008
+	MOD_NOREPEAT = 0x4000
+
+	WM_QUIT   = 0x0012
+	WM_HOTKEY = 0x0312
+	WM_APP    = 0x8000
+
+	PM_NOREMOVE = 0x0000
+
+	ERROR_HOTKEY_ALREADY_REGISTERED = 1409
+)
+
+// ErrHotkeyInUse is returned when another application already owns the key combination.
+var ErrHotkeyInUse = errors.New("hotkey already registered by another application")
+
+// Hotkey is a parsed key combination such as "Ctrl+Alt+N".
+type Hotkey struct {
+	Modifiers uint32
+	Key       uint32
+}
+
+var modifierNames = map[string]uint32{
+	"CTRL":    MOD_CONTROL,
+	"CONTROL": MOD_CONTROL,
+	"ALT":     MOD_ALT,
+	"SHIFT":   MOD_SHIFT,
+	"WIN":     MOD_WIN,
+}
+
+var keyNames = map[string]uint32{
+	"SPACE":  0x20,
+	"ENTER":  0x0D,
+	"TAB":    0x09,
+	"ESC":    0x1B,
+	"INSERT": 0x2D,
+	"DELETE": 0x2E,
+	"HOME":   0x24,
+	"END":    0x23,
+	"PGUP":   0x21,
+	"PGDN":   0x22,
+	"LEFT":   0x25,
+	"UP":     0x26,
+	"RIGHT":  0x27,
+	"DOWN":   0x28,
+}
+
+// ParseHotkey parses strings like "Ctrl+Alt+N" or "Win+Shift+F2".
+// At least one modifier is required so plain typing is never swallowed.
+func ParseHotkey(s string) (Hotkey, error) {
+	var hk Hotkey
+	parts := strings.Split(s, "+")
+	for i, p := range parts {
+		name := strings.ToUpper(strings.TrimSpace(p))
+		if name == "" {
+			return hk, fmt.Errorf("invalid hotkey %q", s)
+		}
+		if i < len(parts)-1 {
+			mod, ok := modifierNames[name]
+			if !ok {
+				return hk, fmt.Errorf("invalid modifier %q in hotkey %q", p, s)
+			}
+			hk.Modifiers |= mod
+			continue
+		}
+
+		switch {
+		case len(name) == 1 && (name[0] >= 'A' && name[0] <= 'Z' || name[0] >= '0' && name[0] <= '9'):
+			hk.Key = uint32(name[0])
+		case len(name) >= 2 && name[0] == 'F':
+			var n int
+			if _, err := fmt.Sscanf(name[1:], "%d", &n); err != nil || n < 1 || n > 24 {
+				return hk, fmt.Errorf("invalid key %q in hotkey %q", p, s)
+			}
+			hk.Key = 0x70 + uint32(n-1) // VK_F1
+		default:
+			vk, ok := keyNames[name]
+			if !ok {
+				return hk, fmt.Errorf("invalid key %q in hotkey %q", p, s)
+			}
+			hk.Key = vk
+		}
+	}
+	if hk.Modifiers == 0 {
+		return hk, fmt.Errorf("hotkey %q needs at least one modifier", s)
+	}
+	return hk, nil
+}
+
+type MSG struct {
+	Hwnd    uintptr
+	Message uint32
+	WParam  uintptr
+	LParam  uintptr
+	Time    uint32
+	Pt      POINT
+}
+
+// HotkeyManager owns a dedicated OS thread with a message loop.
+// RegisterHotKey binds hotkeys to the calling thread, so every
+// register/unregister call is marshalled onto that thread.
+type HotkeyManager struct {
+	threadID uint32
+	handler  func(id int)
+
+	mu      sync.Mutex
+	pending []*hotkeyJob
+	closed  bool
+}
+
+// hotkeyJob is a call waiting to run on the hotkey thread.
+type hotkeyJob struct {
+	fn   func()
+	done chan struct{}
+}
+
+// NewHotkeyManager starts the hotkey thread. handler is called on that
+// thread with the id passed to Register whenever a hotkey fires.
+func NewHotkeyManager(handler func(id int)) (*HotkeyManager, error) {
+	m := &HotkeyManager{handler: handler}
+	ready := make(chan error, 1)
+	go m.loop(ready)
+	if err := <-ready; err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *HotkeyManager) loop(ready chan<- error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	tid, _, _ := procGetCurrentThreadId.Call()
+	m.threadID = uint32(tid)
+
+	// Force creation of the thread message queue before anyone posts to it
+	var msg MSG
+	procPeekMessageW.Call(uintptr(unsafe.Pointer(&msg)), 0, WM_APP, WM_APP, PM_NOREMOVE)
+	ready <- nil
+
+	for {
+		ret, _, _ := procGetMessageW.Call(uintptr(unsafe.Pointer(&msg)), 0, 0, 0)
+		if int32(ret) <= 0 {
+			return
+		}
+		switch msg.Message {
+		case WM_HOTKEY:
+			if m.handler != nil {
+				m.handler(int(msg.WParam))
+			}
+		case WM_APP:
+			m.mu.Lock()
+			jobs := m.pending
+			m.pending = nil
+			m.mu.Unlock()
+			for _, job := range jobs {
+				job.fn()
+				close(job.done)
+			}
+		}
+	}
+}
+
+// run executes fn on the hotkey thread and waits for it to finish. If
+// the thread can't be woken fn is dropped, unless the thread already
+// picked it up along with another job.
+func (m *HotkeyManager) run(fn func()) error {
+	job := &hotkeyJob{fn: fn, done: make(chan struct{})}
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return errors.New("hotkey manager closed")
+	}
+	m.pending = append(m.pending, job)
+	m.mu.Unlock()
+
+	ret, _, err := procPostThreadMessageW.Call(uintptr(m.threadID), WM_APP, 0, 0)
+	if ret == 0 {
+		m.mu.Lock()
+		for i, j := range m.pending {
+			if j == job {
+				m.pending = append(m.pending[:i], m.pending[i+1:]...)
+				m.mu.Unlock()
+				return fmt.Errorf("PostThreadMessage: %w", err)
+			}
+		}
+		m.mu.Unlock()
+	}
+	<-job.done
+	return nil
+}
+
+// Register binds hk to id. Returns ErrHotkeyInUse if another
+// application (or another id) already owns the combination.
+func (m *HotkeyManager) Register(id int, hk Hotkey) error {
+	var regErr error
+	err := m.run(func() {
+		ret, _, err := procRegisterHotKey.Call(0, uintptr(id), uintptr(hk.Modifiers|MOD_NOREPEAT), uintptr(hk.Key))
+		if ret == 0 {
+			if errno, ok := err.(syscall.Errno); ok && errno == ERROR_HOTKEY_ALREADY_REGISTERED {
+				regErr = ErrHotkeyInUse
+			} else {
+				regErr = err
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return regErr
+}
+
+// Unregister releases the hotkey bound to id. Unknown ids are ignored.
+func (m *HotkeyManager) Unregister(id int) {
+	m.run(func() {
+		procUnregisterHotKey.Call(0, uintptr(id))
+	})
+}
+
+// Close stops the message loop. Hotkeys still registered are released by the OS.
+func (m *HotkeyManager) Close() {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	m.mu.Unlock()
+	procPostThreadMessageW.Call(uintptr(m.threadID), WM_QUIT, 0, 0)
+}
@@ -2,6 +2,7 @@ package platform
 
 import (
 	"syscall"
+	"time"
 	"unsafe"
 )
 
@@ -18,6 +19,7 @@ var (
 	procLoadImageW          = user32.NewProc("LoadImageW")
 	procSetWindowRgn        = user32.NewProc("SetWindowRgn")
 	procShowWindow          = user32.NewProc("ShowWindow")
+	procIsWindowVisible     = user32.NewProc("IsWindowVisible")
 	procSetForegroundWindow = user32.NewProc("SetForegroundWindow")
 	procMonitorFromWindow   = user32.NewProc("MonitorFromWindow")
 	procGetMonitorInfoW     = user32.NewProc("GetMonitorInfoW")
@@ -27,10 +29,23 @@ var (
 	procDestroyMenu         = user32.NewProc("DestroyMenu")
 	procGetCursorPos        = user32.NewProc("GetCursorPos")
 	procGetAsyncKeyState    = user32.NewProc("GetAsyncKeyState")
+	procMessageBoxW         = user32.NewProc("MessageBoxW")
+	procGetLastInputInfo    = user32.NewProc("GetLastInputInfo")
+
+	procSetLayeredWindowAttributes = user32.NewProc("SetLayeredWindowAttributes")
+	procGetForegroundWindow        = user32.NewProc("GetForegroundWindow")
+	procGetShellWindow             = user32.NewProc("GetShellWindow")
+	procGetClassNameW              = user32.NewProc("GetClassNameW")
 
 	gdi32                 = syscall.NewLazyDLL("gdi32.dll")
 	procCreateEllipticRgn = gdi32.NewProc("CreateEllipticRgn")
 
+	shell32                          = syscall.NewLazyDLL("shell32.dll")
+	procSHQueryUserNotificationState = shell32.NewProc("SHQueryUserNotificationState")
+
+	shcore               = syscall.NewLazyDLL("shcore.dll")
+	procGetDpiForMonitor = shcore.NewProc("GetDpiForMonitor")
+
 	kernel32                = syscall.NewLazyDLL("kernel32.dll")
 	procCreateMutexW        = kernel32.NewProc("CreateMutexW")
 	procGetLastError        = kernel32.NewProc("GetLastError")
@@ -39,6 +54,7 @@ var (
 	procSetEvent            = kernel32.NewProc("SetEvent")
 	procWaitForSingleObject = kernel32.NewProc("WaitForSingleObject")
 	procCloseHandle         = kernel32.NewProc("CloseHandle")
+	procGetTickCount        = kernel32.NewProc("GetTickCount")
 )
 
 const (
@@ -107,22 +123,25 @@ func CreateMutex(name string) (uintptr, error) {
 }
 
 const (
-	GWL_STYLE        = -16
-	GWL_EXSTYLE      = -20
-	WS_CAPTION       = 0x00C00000
-	WS_THICKFRAME    = 0x00040000
-	WS_SYSMENU       = 0x00080000
-	WS_EX_LAYERED    = 0x00080000
-	WS_EX_TOOLWINDOW = 0x00000080
-	WS_EX_APPWINDOW  = 0x00040000
-	WS_POPUP         = 0x80000000
+	GWL_STYLE         = -16
+	GWL_EXSTYLE       = -20
+	WS_CAPTION        = 0x00C00000
+	WS_THICKFRAME     = 0x00040000
+	WS_SYSMENU        = 0x00080000
+	WS_EX_LAYERED     = 0x00080000
+	WS_EX_TRANSPARENT = 0x00000020
+	WS_EX_TOOLWINDOW  = 0x00000080
+	WS_EX_APPWINDOW   = 0x00040000
+	WS_POPUP          = 0x80000000
 
 	SWP_NOSIZE       = 0x0001
 	SWP_NOMOVE       = 0x0002
 	SWP_NOZORDER     = 0x0004
+	SWP_NOACTIVATE   = 0x0010
 	SWP_FRAMECHANGED = 0x0020
 
-	HWND_TOPMOST = -1
+	HWND_TOPMOST   = -1
+	HWND_NOTOPMOST = -2
 
 	WM_SETICON = 0x0080
 	ICON_SMALL = 0
@@ -132,12 +151,17 @@ const (
 	LR_LOADFROMFILE = 0x00000010
 	LR_DEFAULTSIZE  = 0x00000040
 
-	SW_SHOW    = 5
-	SW_RESTORE = 9
+	SW_HIDE           = 0
+	SW_SHOWNOACTIVATE = 4
+	SW_SHOW           = 5
+	SW_RESTORE        = 9
 
 	MONITOR_DEFAULTTONULL    = 0x00000000
 	MONITOR_DEFAULTTOPRIMARY = 0x00000001
 	MONITOR_DEFAULTTONEAREST = 0x00000002
+
+	MDT_EFFECTIVE_DPI = 0
+	USER_DEFAULT_DPI  = 96
 )
 
 type RECT struct {
@@ -176,14 +200,50 @@ func GetWindowRect(hwnd uintptr) *RECT {
 	return &rect
 }
 
-func SetWindowPos(hwnd uintptr, x, y, w, h int, flags uint) {
-	procSetWindowPos.Call(hwnd, 0, uintptr(x), uintptr(y), uintptr(w), uintptr(h), uintptr(flags))
+func SetWindowPos(hwnd uintptr, x, y, w, h int, flags uint) error {
+	ret, _, err := procSetWindowPos.Call(hwnd, 0, uintptr(x), uintptr(y), uintptr(w), uintptr(h), uintptr(flags))
+	if ret == 0 {
+		return err
+	}
+	return nil
 }
 
 func SetWindowLong(hwnd uintptr, index int, value int) {
 	procSetWindowLongW.Call(hwnd, uintptr(index), uintptr(value))
 }
 
+const LWA_ALPHA = 0x00000002
+
+// SetWindowAlpha makes the whole window translucent, 255 being opaque.
+func SetWindowAlpha(hwnd uintptr, alpha byte) error {
+	setExStyle(hwnd, WS_EX_LAYERED, true)
+	ret, _, err := procSetLayeredWindowAttributes.Call(hwnd, 0, uintptr(alpha), LWA_ALPHA)
+	if ret == 0 {
+		return err
+	}
+	return nil
+}
+
+// SetClickThrough lets mouse input pass through the window to whatever
+// is below it. The window must be layered, see SetWindowAlpha.
+func SetClickThrough(hwnd uintptr, enable bool) {
+	setExStyle(hwnd, WS_EX_TRANSPARENT, enable)
+}
+
+// setExStyle sets or clears bits of the extended window style, keeping
+// the others.
+func setExStyle(hwnd uintptr, bits uintptr, set bool) {
+	index := int32(GWL_EXSTYLE)
+	style, _, _ := procGetWindowLongW.Call(hwnd, uintptr(index))
+	updated := style &^ bits
+	if set {
+		updated |= bits
+	}
+	if updated != style {
+		procSetWindowLongW.Call(hwnd, uintptr(index), updated)
+	}
+}
+
 func SetWindowCircular(hwnd uintptr, width, height int) {
 	hrgn, _, _ := procCreateEllipticRgn.Call(0, 0, uintptr(width), uintptr(height))
 	if hrgn != 0 {
@@ -211,6 +271,14 @@ func SetTopMost(hwnd uintptr) {
 		uintptr(SWP_NOMOVE|SWP_NOSIZE))
 }
 
+// SetNotTopMost undoes SetTopMost, leaving the window where it is in the
+// normal z-order.
+func SetNotTopMost(hwnd uintptr) {
+	hwndNoTopMost := ^uintptr(1)
+	procSetWindowPos.Call(hwnd, hwndNoTopMost, 0, 0, 0, 0,
+		uintptr(SWP_NOMOVE|SWP_NOSIZE|SWP_NOACTIVATE))
+}
+
 func SetWindowIcon(hwnd uintptr, iconPath string, iconType int) {
 	ptr, _ := syscall.UTF16PtrFromString(iconPath)
 	hIcon, _, _ := procLoadImageW.Call(
@@ -229,15 +297,58 @@ func ShowNormal(hwnd uintptr) {
 	procShowWindow.Call(hwnd, uintptr(SW_RESTORE))
 }
 
+func HideWindow(hwnd uintptr) {
+	procShowWindow.Call(hwnd, uintptr(SW_HIDE))
+}
+
+// ShowNoActivate shows a window without stealing focus from the foreground app.
+func ShowNoActivate(hwnd uintptr) {
+	procShowWindow.Call(hwnd, uintptr(SW_SHOWNOACTIVATE))
+}
+
+func IsWindowVisible(hwnd uintptr) bool {
+	ret, _, _ := procIsWindowVisible.Call(hwnd)
+	return ret != 0
+}
+
 func SetForegroundWindow(hwnd uintptr) {
 	procSetForegroundWindow.Call(hwnd)
 }
 
+const (
+	MB_OK        = 0x00000000
+	MB_ICONERROR = 0x00000010
+)
+
+// MessageBox shows a blocking error dialog. Used before the webview is up.
+func MessageBox(title, text string) {
+	t, _ := syscall.UTF16PtrFromString(title)
+	m, _ := syscall.UTF16PtrFromString(text)
+	procMessageBoxW.Call(0, uintptr(unsafe.Pointer(m)), uintptr(unsafe.Pointer(t)), MB_OK|MB_ICONERROR)
+}
+
 func PostQuitMessage(hwnd uintptr) {
 	procSendMessageW.Call(hwnd, 0x0010, 0, 0) // WM_CLOSE
 }
 
-func GetMonitorRectForWindow(hwnd uintptr) (*RECT, error) {
+// MonitorInfo describes the monitor a window is on.
+// Monitor is the full monitor rectangle, Work excludes the taskbar and
+// docked app bars. DPI is the effective per-monitor DPI (96 = 100%).
+type MonitorInfo struct {
+	Monitor RECT
+	Work    RECT
+	DPI     uint32
+}
+
+// Scale converts a length in 96-DPI logical pixels to physical pixels on this monitor.
+func (m *MonitorInfo) Scale(px int) int {
+	if m.DPI == 0 {
+		return px
+	}
+	return px * int(m.DPI) / USER_DEFAULT_DPI
+}
+
+func GetMonitorInfoForWindow(hwnd uintptr) (*MonitorInfo, error) {
 	hMonitor, _, _ := procMonitorFromWindow.Call(hwnd, MONITOR_DEFAULTTONEAREST)
 	if hMonitor == 0 {
 		return nil, syscall.Errno(0)
@@ -249,7 +360,43 @@ func GetMonitorRectForWindow(hwnd uintptr) (*RECT, error) {
 	if ret == 0 {
 		return nil, syscall.Errno(0)
 	}
-	return &mi.RcMonitor, nil
+
+	return &MonitorInfo{
+		Monitor: mi.RcMonitor,
+		Work:    mi.RcWork,
+		DPI:     getDpiForMonitor(hMonitor),
+	}, nil
+}
+
+// getDpiForMonitor returns the effective DPI of a monitor.
+// GetDpiForMonitor is only available on Windows 8.1+, so fall back to 96.
+func getDpiForMonitor(hMonitor uintptr) uint32 {
+	if procGetDpiForMonitor.Find() != nil {
+		return USER_DEFAULT_DPI
+	}
+	var dpiX, dpiY uint32
+	ret, _, _ := procGetDpiForMonitor.Call(hMonitor, MDT_EFFECTIVE_DPI,
+		uintptr(unsafe.Pointer(&dpiX)), uintptr(unsafe.Pointer(&dpiY)))
+	if ret != 0 || dpiX == 0 { // S_OK == 0
+		return USER_DEFAULT_DPI
+	}
+	return dpiX
+}
+
+func GetMonitorRectForWindow(hwnd uintptr) (*RECT, error) {
+	info, err := GetMonitorInfoForWindow(hwnd)
+	if err != nil {
+		return nil, err
+	}
+	return &info.Monitor, nil
+}
+
+func GetWorkAreaForWindow(hwnd uintptr) (*RECT, error) {
+	info, err := GetMonitorInfoForWindow(hwnd)
+	if err != nil {
+		return nil, err
+	}
+	return &info.Work, nil
 }
 
 // Menu related constants and functions
@@ -278,6 +425,79 @@ func GetAsyncKeyState(vKey int) uint16 {
 	return uint16(ret)
 }
 
+type LASTINPUTINFO struct {
+	CbSize uint32
+	DwTime uint32
+}
+
+// IdleTime returns how long ago the last keyboard or mouse input of the
+// session was.
+func IdleTime() (time.Duration, error) {
+	info := LASTINPUTINFO{CbSize: uint32(unsafe.Sizeof(LASTINPUTINFO{}))}
+	ret, _, err := procGetLastInputInfo.Call(uintptr(unsafe.Pointer(&info)))
+	if ret == 0 {
+		return 0, err
+	}
+	now, _, _ := procGetTickCount.Call()
+	// Both are 32-bit tick counts; the subtraction survives the wrap every 49.7 days
+	return time.Duration(uint32(now)-info.DwTime) * time.Millisecond, nil
+}
+
+// Results of SHQueryUserNotificationState
+const (
+	QUNS_NOT_PRESENT             = 1 // Screen saver, locked or switching users
+	QUNS_BUSY                    = 2 // Fullscreen app
+	QUNS_RUNNING_D3D_FULL_SCREEN = 3 // Fullscreen Direct3D game or video
+	QUNS_PRESENTATION_MODE       = 4 // Presentation settings turned on
+	QUNS_ACCEPTS_NOTIFICATIONS   = 5
+	QUNS_QUIET_TIME              = 6 // First hour after a new user signs in
+	QUNS_APP                     = 7 // Windows Store app in front
+)
+
+// UserNotificationState asks the shell whether the user can be
+// interrupted right now. Focus assist is not reported.
+func UserNotificationState() (int, error) {
+	if err := procSHQueryUserNotificationState.Find(); err != nil {
+		return QUNS_ACCEPTS_NOTIFICATIONS, err
+	}
+	var state int32
+	ret, _, _ := procSHQueryUserNotificationState.Call(uintptr(unsafe.Pointer(&state)))
+	if ret != 0 { // S_OK == 0
+		return QUNS_ACCEPTS_NOTIFICATIONS, syscall.Errno(ret)
+	}
+	return int(state), nil
+}
+
+// ForegroundIsFullscreen reports whether the foreground window covers its
+// whole monitor, as games, videos and slide shows do. The desktop
+// doesn't count.
+func ForegroundIsFullscreen() bool {
+	hwnd, _, _ := procGetForegroundWindow.Call()
+	if hwnd == 0 {
+		return false
+	}
+	if shell, _, _ := procGetShellWindow.Call(); hwnd == shell {
+		return false
+	}
+	switch className(hwnd) {
+	case "Progman", "WorkerW": // Desktop behind the icons
+		return false
+	}
+	rect := GetWindowRect(hwnd)
+	mon, err := GetMonitorRectForWindow(hwnd)
+	if rect == nil || err != nil {
+		return false
+	}
+	return rect.Left <= mon.Left && rect.Top <= mon.Top &&
+		rect.Right >= mon.Right && rect.Bottom >= mon.Bottom
+}
+
+func className(hwnd uintptr) string {
+	buf := make([]uint16, 256)
+	n, _, _ := procGetClassNameW.Call(hwnd, uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+	return syscall.UTF16ToString(buf[:n])
+}
+
 func CreatePopupMenu() uintptr {
 	ret, _, _ := procCreatePopupMenu.Call()
 	return ret
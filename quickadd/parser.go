@@ -0,0 +1,629 @@
+// Package quickadd turns a single line of free text such as
+// "Send report tomorrow 5pm !high #work remind 2d" or "明天下午3点开会 #工作"
+// into the fields of a todo item.
+package quickadd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"todo-ball/dates"
+	"todo-ball/models"
+)
+
+// ErrorCode identifies why a quick-add line could not be parsed.
+type ErrorCode string
+
+const (
+	ErrEmptyTitle       ErrorCode = "empty_title"
+	ErrInvalidPriority  ErrorCode = "invalid_priority"
+	ErrInvalidDate      ErrorCode = "invalid_date"
+	ErrInvalidTime      ErrorCode = "invalid_time"
+	ErrInvalidReminder  ErrorCode = "invalid_reminder"
+	ErrConflictingDates ErrorCode = "conflicting_dates"
+)
+
+// MaxRelativeDays bounds offsets such as "in 3 days": the longest span,
+// in any unit, a relative date may reach from now.
+const MaxRelativeDays = 10 * 365
+
+// ParseError is returned for input the parser understood but rejected.
+// Fragment is the part of the input that caused it.
+type ParseError struct {
+	Code     ErrorCode
+	Fragment string
+}
+
+func (e *ParseError) Error() string {
+	if e.Fragment == "" {
+		return fmt.Sprintf("quickadd: %s", e.Code)
+	}
+	return fmt.Sprintf("quickadd: %s: %q", e.Code, e.Fragment)
+}
+
+// Result holds everything extracted from a line.
+// DueDate is zero if the text contained no date or time. AllDay is set
+// for a day without a time; DueDate is then the midnight ending that day.
+// Reminder is zero if no reminder offset was given.
+type Result struct {
+	Title    string
+	DueDate  time.Time
+	AllDay   bool
+	Priority string
+	Tags     []string
+	Reminder time.Duration
+}
+
+// Parser parses quick-add lines relative to Now in Location.
+// The zero value uses time.Now and time.Local.
+type Parser struct {
+	Now      func() time.Time
+	Location *time.Location
+
+	// DefaultHour/DefaultMinute are used when a date is given without a time.
+	// The zero value makes such a todo due all day.
+	DefaultHour   int
+	DefaultMinute int
+}
+
+// Parse extracts the due date, priority, tags and reminder offset from text.
+// Whatever is left over becomes the title.
+func (p *Parser) Parse(text string) (Result, error) {
+	st := &state{
+		parser: p,
+		now:    p.now(),
+		rest:   " " + strings.TrimSpace(text) + " ",
+	}
+	st.res.Priority = models.PriorityNormal
+
+	for _, r := range rules {
+		if err := st.apply(r); err != nil {
+			return Result{}, err
+		}
+	}
+
+	st.res.Title = strings.Join(strings.Fields(st.rest), " ")
+	if st.res.Title == "" {
+		return Result{}, &ParseError{Code: ErrEmptyTitle, Fragment: text}
+	}
+	st.res.DueDate, st.res.AllDay = st.dueDate()
+	return st.res, nil
+}
+
+func (p *Parser) now() time.Time {
+	loc := p.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	now := time.Now
+	if p.Now != nil {
+		now = p.Now
+	}
+	return now().In(loc)
+}
+
+// state accumulates matches while rules consume the input.
+type state struct {
+	parser *Parser
+	now    time.Time
+	rest   string
+	res    Result
+
+	// Calendar day, if any rule set one
+	hasDay bool
+	day    time.Time // midnight in the parser location
+
+	// Time of day, if any rule set one. halfDay is set when it said which
+	// half of the day (5pm, 下午5点), so "今晚8点" is 20:00.
+	hasClock     bool
+	hour, minute int
+	halfDay      bool
+
+	// Exact instant from relative phrases such as "in 3 hours"
+	hasInstant bool
+	instant    time.Time
+
+	// Time used when only a day is given ("tonight" → 20:00)
+	defaultClock *[2]int
+}
+
+type rule struct {
+	re *regexp.Regexp
+	fn func(st *state, m []string) error
+}
+
+// apply runs a rule over the remaining text and removes every match.
+func (st *state) apply(r rule) error {
+	for {
+		loc := r.re.FindStringSubmatchIndex(st.rest)
+		if loc == nil {
+			return nil
+		}
+		m := make([]string, len(loc)/2)
+		for i := range m {
+			if loc[2*i] >= 0 {
+				m[i] = st.rest[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		if err := r.fn(st, m); err != nil {
+			return err
+		}
+		st.rest = st.rest[:loc[0]] + " " + st.rest[loc[1]:]
+	}
+}
+
+func (st *state) setDay(d time.Time, fragment string) error {
+	if st.hasDay || st.hasInstant {
+		return &ParseError{Code: ErrConflictingDates, Fragment: strings.TrimSpace(fragment)}
+	}
+	st.hasDay = true
+	st.day = d
+	return nil
+}
+
+func (st *state) setClock(h, m int, fragment string) error {
+	if h < 0 || h > 23 || m < 0 || m > 59 {
+		return &ParseError{Code: ErrInvalidTime, Fragment: strings.TrimSpace(fragment)}
+	}
+	if st.hasClock || st.hasInstant {
+		return &ParseError{Code: ErrConflictingDates, Fragment: strings.TrimSpace(fragment)}
+	}
+	st.hasClock = true
+	st.hour, st.minute = h, m
+	return nil
+}
+
+func (st *state) setInstant(t time.Time, fragment string) error {
+	if st.hasDay || st.hasClock || st.hasInstant {
+		return &ParseError{Code: ErrConflictingDates, Fragment: strings.TrimSpace(fragment)}
+	}
+	st.hasInstant = true
+	st.instant = t
+	return nil
+}
+
+func (st *state) today() time.Time {
+	y, m, d := st.now.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, st.now.Location())
+}
+
+// dueDate combines the day and time found, and reports whether the todo
+// is due all day. Times are set on the wall clock, so "tomorrow 9am" is
+// 09:00 even when the clocks change overnight.
+func (st *state) dueDate() (time.Time, bool) {
+	loc := st.now.Location()
+	switch {
+	case st.hasInstant:
+		return st.instant, false
+	case st.hasDay && st.hasClock:
+		hour := st.hour
+		if st.defaultClock != nil && st.defaultClock[0] >= 12 && !st.halfDay && hour < 12 {
+			hour += 12
+		}
+		return dates.At(st.day, hour, st.minute, loc), false
+	case st.hasDay:
+		if st.defaultClock != nil {
+			return dates.At(st.day, st.defaultClock[0], st.defaultClock[1], loc), false
+		}
+		if st.parser.DefaultHour != 0 || st.parser.DefaultMinute != 0 {
+			return dates.At(st.day, st.parser.DefaultHour, st.parser.DefaultMinute, loc), false
+		}
+		return dates.EndOfDay(st.day, loc), true
+	case st.hasClock:
+		// Time only: today, or tomorrow if that time has already passed
+		t := dates.At(st.now, st.hour, st.minute, loc)
+		if !t.After(st.now) {
+			t = dates.AddDays(t, 1, loc)
+		}
+		return t, false
+	}
+	return time.Time{}, false
+}
+
+// upcomingWeekday returns the next day after today that falls on wd.
+func (st *state) upcomingWeekday(wd time.Weekday) time.Time {
+	today := st.today()
+	diff := (int(wd) - int(today.Weekday()) + 7) % 7
+	if diff == 0 {
+		diff = 7
+	}
+	return dates.AddDays(today, diff, st.now.Location())
+}
+
+// weekdayOfWeek returns wd in the week starting on Monday, offset by weeks.
+// weeks=0 is the current week, weeks=1 next week.
+func (st *state) weekdayOfWeek(wd time.Weekday, weeks int) time.Time {
+	today := st.today()
+	monday := dates.AddDays(today, -((int(today.Weekday()) + 6) % 7), st.now.Location())
+	return dates.AddDays(monday, (int(wd)+6)%7+7*weeks, st.now.Location())
+}
+
+var rules = []rule{
+	// Reminder offsets: "remind 2d", "remind me 3 hours before", "提前2天提醒"
+	{
+		re: regexp.MustCompile(`(?i)\sremind(?:\s+me)?\s+(\S+?)\s*(m|mins?|minutes?|h|hrs?|hours?|d|days?|w|wks?|weeks?)(?:\s+before)?\s`),
+		fn: func(st *state, m []string) error { return st.setReminder(m[1], m[2], m[0]) },
+	},
+	{
+		re: regexp.MustCompile(`提前\s*([0-9零一二两三四五六七八九十]+)\s*(分钟|个?小时|天|日|个?星期|周)\s*(?:提醒)?`),
+		fn: func(st *state, m []string) error { return st.setReminder(m[1], m[2], m[0]) },
+	},
+
+	// Tags
+	{
+		re: regexp.MustCompile(`\s#([\p{L}\p{N}_\-/]+)`),
+		fn: func(st *state, m []string) error {
+			tag := strings.ToLower(m[1])
+			for _, t := range st.res.Tags {
+				if t == tag {
+					return nil
+				}
+			}
+			st.res.Tags = append(st.res.Tags, tag)
+			return nil
+		},
+	},
+
+	// Priority: "!high", "!low", "!!", "!高"
+	{
+		re: regexp.MustCompile(`\s(!+)(\S*)`),
+		fn: func(st *state, m []string) error {
+			p, ok := parsePriority(m[1], m[2])
+			if !ok {
+				return &ParseError{Code: ErrInvalidPriority, Fragment: strings.TrimSpace(m[0])}
+			}
+			st.res.Priority = p
+			return nil
+		},
+	},
+
+	// Relative instants: "in 3 hours", "in two days", "三天后", "2小时后"
+	{
+		re: regexp.MustCompile(`(?i)\sin\s+(\S+)\s+(minutes?|mins?|hours?|hrs?|days?|weeks?)\s`),
+		fn: func(st *state, m []string) error { return st.setRelative(m[1], m[2], m[0]) },
+	},
+	{
+		re: regexp.MustCompile(`([0-9零一二两三四五六七八九十]+)\s*(分钟|个?小时|天|日|个?星期|周)(?:之|以)?后`),
+		fn: func(st *state, m []string) error { return st.setRelative(m[1], m[2], m[0]) },
+	},
+
+	// Named days
+	{
+		re: regexp.MustCompile(`(?i)\s(?:due\s+|by\s+|on\s+)?(the\s+day\s+after\s+tomorrow|day\s+after\s+tomorrow|today|tonight|tomorrow|tmrw?)\s`),
+		fn: func(st *state, m []string) error {
+			word := strings.Join(strings.Fields(strings.ToLower(m[1])), " ")
+			switch word {
+			case "today":
+				return st.setDay(st.today(), m[0])
+			case "tonight":
+				st.defaultClock = &[2]int{20, 0}
+				return st.setDay(st.today(), m[0])
+			case "tomorrow", "tmr", "tmrw":
+				return st.setDay(dates.AddDays(st.today(), 1, st.now.Location()), m[0])
+			default: // day after tomorrow
+				return st.setDay(dates.AddDays(st.today(), 2, st.now.Location()), m[0])
+			}
+		},
+	},
+	{
+		re: regexp.MustCompile(`(大后天|后天|明天|明早|明晚|今天|今早|今晚)`),
+		fn: func(st *state, m []string) error {
+			switch m[1] {
+			case "今天":
+				return st.setDay(st.today(), m[0])
+			case "今早":
+				st.defaultClock = &[2]int{9, 0}
+				return st.setDay(st.today(), m[0])
+			case "今晚":
+				st.defaultClock = &[2]int{20, 0}
+				return st.setDay(st.today(), m[0])
+			case "明天":
+				return st.setDay(dates.AddDays(st.today(), 1, st.now.Location()), m[0])
+			case "明早":
+				st.defaultClock = &[2]int{9, 0}
+				return st.setDay(dates.AddDays(st.today(), 1, st.now.Location()), m[0])
+			case "明晚":
+				st.defaultClock = &[2]int{20, 0}
+				return st.setDay(dates.AddDays(st.today(), 1, st.now.Location()), m[0])
+			case "后天":
+				return st.setDay(dates.AddDays(st.today(), 2, st.now.Location()), m[0])
+			default: // 大后天
+				return st.setDay(dates.AddDays(st.today(), 3, st.now.Location()), m[0])
+			}
+		},
+	},
+
+	// Weekdays: "friday", "next monday", "下周一", "周五", "星期天".
+	// "sat" and "sun" are left out, they are too common as plain words.
+	{
+		re: regexp.MustCompile(`(?i)\s(?:due\s+|by\s+|on\s+)?(next\s+|this\s+)?(monday|mon|tuesday|tues?|wednesday|wed|thursday|thurs?|thu|friday|fri|saturday|sunday)\s`),
+		fn: func(st *state, m []string) error {
+			wd := englishWeekdays[strings.ToLower(m[2])]
+			switch strings.TrimSpace(strings.ToLower(m[1])) {
+			case "next":
+				return st.setDay(st.weekdayOfWeek(wd, 1), m[0])
+			case "this":
+				return st.setDay(st.weekdayOfWeek(wd, 0), m[0])
+			}
+			return st.setDay(st.upcomingWeekday(wd), m[0])
+		},
+	},
+	{
+		re: regexp.MustCompile(`(下下|下个?|本|这个?)?(?:周|星期|礼拜)([一二三四五六日天])`),
+		fn: func(st *state, m []string) error {
+			wd := chineseWeekdays[m[2]]
+			switch {
+			case m[1] == "下下":
+				return st.setDay(st.weekdayOfWeek(wd, 2), m[0])
+			case strings.HasPrefix(m[1], "下"):
+				return st.setDay(st.weekdayOfWeek(wd, 1), m[0])
+			case m[1] != "":
+				return st.setDay(st.weekdayOfWeek(wd, 0), m[0])
+			}
+			return st.setDay(st.upcomingWeekday(wd), m[0])
+		},
+	},
+
+	// Absolute dates: "2026-03-01", "3/1", "3月1日"
+	{
+		re: regexp.MustCompile(`(?i)\s(?:due\s+|by\s+|on\s+)?(\d{4})-(\d{1,2})-(\d{1,2})\s`),
+		fn: func(st *state, m []string) error {
+			y, _ := strconv.Atoi(m[1])
+			return st.setDate(y, m[2], m[3], m[0])
+		},
+	},
+	{
+		re: regexp.MustCompile(`(?i)\s(?:due\s+|by\s+|on\s+)?(\d{1,2})/(\d{1,2})\s`),
+		fn: func(st *state, m []string) error { return st.setDate(0, m[1], m[2], m[0]) },
+	},
+	{
+		re: regexp.MustCompile(`(?:(\d{4})年)?(\d{1,2})月(\d{1,2})[日号]`),
+		fn: func(st *state, m []string) error {
+			y := 0
+			if m[1] != "" {
+				y, _ = strconv.Atoi(m[1])
+			}
+			return st.setDate(y, m[2], m[3], m[0])
+		},
+	},
+
+	// Times: "5pm", "5:30pm", "at 17:00", "noon", "下午5点", "晚上8点半", "9:30"
+	{
+		re: regexp.MustCompile(`(?i)\s(?:at\s+)?(\d{1,2})(?::(\d{2}))?\s*(am|pm)\s`),
+		fn: func(st *state, m []string) error {
+			h, _ := strconv.Atoi(m[1])
+			min := 0
+			if m[2] != "" {
+				min, _ = strconv.Atoi(m[2])
+			}
+			if h < 1 || h > 12 {
+				return &ParseError{Code: ErrInvalidTime, Fragment: strings.TrimSpace(m[0])}
+			}
+			h %= 12
+			if strings.EqualFold(m[3], "pm") {
+				h += 12
+			}
+			st.halfDay = true
+			return st.setClock(h, min, m[0])
+		},
+	},
+	{
+		re: regexp.MustCompile(`(?i)\s(?:at\s+)?(noon|midnight)\s`),
+		fn: func(st *state, m []string) error {
+			st.halfDay = true
+			if strings.EqualFold(m[1], "noon") {
+				return st.setClock(12, 0, m[0])
+			}
+			return st.setClock(23, 59, m[0])
+		},
+	},
+	{
+		re: regexp.MustCompile(`(凌晨|早上|早晨|上午|中午|下午|傍晚|晚上)?\s*([0-9零一二两三四五六七八九十]+)\s*[点點时]\s*(半|一刻|三刻|[0-9零一二两三四五六七八九十]+\s*分?)?`),
+		fn: func(st *state, m []string) error {
+			h, ok := parseNumber(m[2])
+			if !ok {
+				return &ParseError{Code: ErrInvalidTime, Fragment: m[0]}
+			}
+			switch m[1] {
+			case "中午":
+				if h < 11 {
+					h += 12
+				}
+			case "下午", "傍晚", "晚上":
+				if h < 12 {
+					h += 12
+				}
+			}
+			st.halfDay = m[1] != ""
+			min := 0
+			switch suffix := strings.TrimSuffix(strings.TrimSpace(m[3]), "分"); suffix {
+			case "":
+			case "半":
+				min = 30
+			case "一刻":
+				min = 15
+			case "三刻":
+				min = 45
+			default:
+				if min, ok = parseNumber(strings.TrimSpace(suffix)); !ok {
+					return &ParseError{Code: ErrInvalidTime, Fragment: m[0]}
+				}
+			}
+			return st.setClock(h, min, m[0])
+		},
+	},
+	{
+		re: regexp.MustCompile(`(?i)(?:\s(?:at\s+)?|[到在])(\d{1,2})[:：](\d{2})`),
+		fn: func(st *state, m []string) error {
+			h, _ := strconv.Atoi(m[1])
+			min, _ := strconv.Atoi(m[2])
+			return st.setClock(h, min, m[0])
+		},
+	},
+}
+
+func (st *state) setDate(year int, month, day, fragment string) error {
+	mo, _ := strconv.Atoi(month)
+	d, _ := strconv.Atoi(day)
+	today := st.today()
+	explicitYear := year != 0
+	if !explicitYear {
+		year = today.Year()
+	}
+	t := time.Date(year, time.Month(mo), d, 0, 0, 0, 0, today.Location())
+	// Reject overflow such as 2/30 which time.Date would normalise
+	if mo < 1 || mo > 12 || t.Day() != d {
+		return &ParseError{Code: ErrInvalidDate, Fragment: strings.TrimSpace(fragment)}
+	}
+	// Month/day without a year means the next such date
+	if !explicitYear && t.Before(today) {
+		t = time.Date(year+1, time.Month(mo), d, 0, 0, 0, 0, today.Location())
+	}
+	return st.setDay(t, fragment)
+}
+
+func (st *state) setRelative(n, unit, fragment string) error {
+	count, ok := parseNumber(n)
+	if !ok {
+		return &ParseError{Code: ErrInvalidDate, Fragment: strings.TrimSpace(fragment)}
+	}
+	u, ok := parseUnit(unit)
+	if !ok || count < 0 || count > maxCount(u, MaxRelativeDays) {
+		return &ParseError{Code: ErrInvalidDate, Fragment: strings.TrimSpace(fragment)}
+	}
+	// Day and week offsets name a calendar day; minutes and hours an instant
+	switch u {
+	case 24 * time.Hour:
+		return st.setDay(dates.AddDays(st.today(), count, st.now.Location()), fragment)
+	case 7 * 24 * time.Hour:
+		return st.setDay(dates.AddDays(st.today(), 7*count, st.now.Location()), fragment)
+	}
+	return st.setInstant(st.now.Add(time.Duration(count)*u), fragment)
+}
+
+func (st *state) setReminder(n, unit, fragment string) error {
+	count, ok := parseNumber(n)
+	u, unitOK := parseUnit(unit)
+	if !ok || !unitOK || count < 0 || count > maxCount(u, models.MaxReminderDays) {
+		return &ParseError{Code: ErrInvalidReminder, Fragment: strings.TrimSpace(fragment)}
+	}
+	if st.res.Reminder != 0 {
+		return &ParseError{Code: ErrInvalidReminder, Fragment: strings.TrimSpace(fragment)}
+	}
+	st.res.Reminder = time.Duration(count) * u
+	return nil
+}
+
+// maxCount is how many of unit fit in days, so that counts up to it
+// cannot overflow a time.Duration.
+func maxCount(unit time.Duration, days int) int {
+	return int(time.Duration(days) * 24 * time.Hour / unit)
+}
+
+func parseUnit(s string) (time.Duration, bool) {
+	s = strings.ToLower(strings.TrimPrefix(s, "个"))
+	switch {
+	case s == "分钟" || strings.HasPrefix(s, "m"):
+		return time.Minute, true
+	case s == "小时" || strings.HasPrefix(s, "h"):
+		return time.Hour, true
+	case s == "天" || s == "日" || strings.HasPrefix(s, "d"):
+		return 24 * time.Hour, true
+	case s == "星期" || s == "周" || strings.HasPrefix(s, "w"):
+		return 7 * 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+func parsePriority(bangs, word string) (string, bool) {
+	if word == "" {
+		switch len(bangs) {
+		case 1:
+			return models.PriorityNormal, true
+		case 2, 3:
+			return models.PriorityHigh, true
+		}
+		return "", false
+	}
+	if len(bangs) != 1 {
+		return "", false
+	}
+	switch strings.ToLower(word) {
+	case "high", "h", "urgent", "1", "高", "紧急":
+		return models.PriorityHigh, true
+	case "normal", "medium", "med", "m", "2", "中", "普通":
+		return models.PriorityNormal, true
+	case "low", "l", "3", "低":
+		return models.PriorityLow, true
+	}
+	return "", false
+}
+
+var englishWeekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var chineseWeekdays = map[string]time.Weekday{
+	"一": time.Monday, "二": time.Tuesday, "三": time.Wednesday, "四": time.Thursday,
+	"五": time.Friday, "六": time.Saturday, "日": time.Sunday, "天": time.Sunday,
+}
+
+var englishNumbers = map[string]int{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+	"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10,
+}
+
+var chineseDigits = map[rune]int{
+	'零': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4,
+	'五': 5, '六': 6, '七': 7, '八': 8, '九': 9,
+}
+
+// parseNumber accepts Arabic digits, English words up to ten and
+// Chinese numerals up to 99 (三, 十二, 二十五).
+func parseNumber(s string) (int, bool) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, true
+	}
+	if n, ok := englishNumbers[strings.ToLower(s)]; ok {
+		return n, true
+	}
+
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return 0, false
+	}
+	n := 0
+	tens := false
+	for i, r := range runes {
+		if r == '十' {
+			if tens {
+				return 0, false
+			}
+			tens = true
+			if i == 0 {
+				n = 1
+			}
+			n *= 10
+			continue
+		}
+		d, ok := chineseDigits[r]
+		if !ok {
+			return 0, false
+		}
+		if tens {
+			n += d
+		} else {
+			n = n*10 + d
+		}
+	}
+	return n, true
+}
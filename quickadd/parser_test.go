@@ -0,0 +1,157 @@
+package quickadd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"todo-ball/models"
+)
+
+func parserAt(t *testing.T, zone string, now time.Time) *Parser {
+	t.Helper()
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		t.Skipf("zone %s not available: %v", zone, err)
+	}
+	return &Parser{Now: func() time.Time { return now }, Location: loc}
+}
+
+func TestParse(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("zone Asia/Shanghai not available: %v", err)
+	}
+	// Wednesday 2026-03-04 10:00
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, shanghai)
+	p := parserAt(t, "Asia/Shanghai", now)
+	at := func(d, h, min int) time.Time { return time.Date(2026, 3, d, h, min, 0, 0, shanghai) }
+	// All-day todos are due at the midnight ending the day
+	allDay := func(d int) time.Time { return at(d+1, 0, 0) }
+
+	tests := []struct {
+		text string
+		want Result
+	}{
+		{"Send report tomorrow 5pm !high #work remind 2d", Result{Title: "Send report", DueDate: at(5, 17, 0), Priority: models.PriorityHigh, Tags: []string{"work"}, Reminder: 48 * time.Hour}},
+		{"plain title", Result{Title: "plain title", Priority: models.PriorityNormal}},
+
+		// Relative days
+		{"pay rent today", Result{Title: "pay rent", DueDate: allDay(4), AllDay: true, Priority: models.PriorityNormal}},
+		{"pay rent day after tomorrow", Result{Title: "pay rent", DueDate: allDay(6), AllDay: true, Priority: models.PriorityNormal}},
+		{"pay rent in 3 days", Result{Title: "pay rent", DueDate: allDay(7), AllDay: true, Priority: models.PriorityNormal}},
+		{"pay rent in 3650 days", Result{Title: "pay rent", DueDate: time.Date(2036, 3, 2, 0, 0, 0, 0, shanghai), AllDay: true, Priority: models.PriorityNormal}},
+		{"pay rent in 2 weeks", Result{Title: "pay rent", DueDate: allDay(18), AllDay: true, Priority: models.PriorityNormal}},
+		{"三天后 体检", Result{Title: "体检", DueDate: allDay(7), AllDay: true, Priority: models.PriorityNormal}},
+		{"大后天交房租", Result{Title: "交房租", DueDate: allDay(7), AllDay: true, Priority: models.PriorityNormal}},
+		{"check oven in 2 hours", Result{Title: "check oven", DueDate: at(4, 12, 0), Priority: models.PriorityNormal}},
+		{"tonight movie", Result{Title: "movie", DueDate: at(4, 20, 0), Priority: models.PriorityNormal}},
+
+		// Weekdays; today is a Wednesday
+		{"call mom friday", Result{Title: "call mom", DueDate: allDay(6), AllDay: true, Priority: models.PriorityNormal}},
+		{"call mom wednesday", Result{Title: "call mom", DueDate: allDay(11), AllDay: true, Priority: models.PriorityNormal}},
+		{"review this monday", Result{Title: "review", DueDate: allDay(2), AllDay: true, Priority: models.PriorityNormal}},
+		{"meet next monday 9:30", Result{Title: "meet", DueDate: at(9, 9, 30), Priority: models.PriorityNormal}},
+		{"下周五 交报告", Result{Title: "交报告", DueDate: allDay(13), AllDay: true, Priority: models.PriorityNormal}},
+		{"周日 爬山", Result{Title: "爬山", DueDate: allDay(8), AllDay: true, Priority: models.PriorityNormal}},
+
+		// Times
+		{"lunch noon", Result{Title: "lunch", DueDate: at(4, 12, 0), Priority: models.PriorityNormal}},
+		{"standup 9:30", Result{Title: "standup", DueDate: at(5, 9, 30), Priority: models.PriorityNormal}}, // Already past today
+		{"call at 11:15", Result{Title: "call", DueDate: at(4, 11, 15), Priority: models.PriorityNormal}},
+		{"明天下午3点开会 #工作", Result{Title: "开会", DueDate: at(5, 15, 0), Priority: models.PriorityNormal, Tags: []string{"工作"}}},
+		{"今晚8点半 跑步", Result{Title: "跑步", DueDate: at(4, 20, 30), Priority: models.PriorityNormal}},
+		{"明晚 9:30 电影", Result{Title: "电影", DueDate: at(5, 21, 30), Priority: models.PriorityNormal}},
+		{"明晚上午9点 电影", Result{Title: "电影", DueDate: at(5, 9, 0), Priority: models.PriorityNormal}},
+		{"report 3/10 12am", Result{Title: "report", DueDate: at(10, 0, 0), Priority: models.PriorityNormal}},
+
+		// Priority
+		{"fix it !!", Result{Title: "fix it", Priority: models.PriorityHigh}},
+		{"fix it !low", Result{Title: "fix it", Priority: models.PriorityLow}},
+		{"fix it !高", Result{Title: "fix it", Priority: models.PriorityHigh}},
+		{"fix it !", Result{Title: "fix it", Priority: models.PriorityNormal}},
+
+		// Tags are lower-cased and deduplicated
+		{"task #Home #home #a/b", Result{Title: "task", Priority: models.PriorityNormal, Tags: []string{"home", "a/b"}}},
+		{"提前两天提醒 体检 3月20日", Result{Title: "体检", DueDate: allDay(20), AllDay: true, Priority: models.PriorityNormal, Reminder: 48 * time.Hour}},
+	}
+	for _, tt := range tests {
+		got, err := p.Parse(tt.text)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.text, err)
+			continue
+		}
+		if !got.DueDate.Equal(tt.want.DueDate) {
+			t.Errorf("Parse(%q) due = %s, want %s", tt.text, got.DueDate, tt.want.DueDate)
+		}
+		got.DueDate, tt.want.DueDate = time.Time{}, time.Time{}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseAcrossDST(t *testing.T) {
+	// Saturday before the US clocks go forward
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("zone America/New_York not available: %v", err)
+	}
+	p := parserAt(t, "America/New_York", time.Date(2026, 3, 7, 12, 0, 0, 0, ny))
+	tests := []struct {
+		text string
+		want time.Time
+	}{
+		{"call tomorrow 9am", time.Date(2026, 3, 8, 9, 0, 0, 0, ny)},
+		{"call tomorrow", time.Date(2026, 3, 9, 0, 0, 0, 0, ny)},
+		{"call in 24 hours", time.Date(2026, 3, 8, 13, 0, 0, 0, ny)},
+	}
+	for _, tt := range tests {
+		got, err := p.Parse(tt.text)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.text, err)
+			continue
+		}
+		if !got.DueDate.Equal(tt.want) {
+			t.Errorf("Parse(%q) due = %s, want %s", tt.text, got.DueDate, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	p := parserAt(t, "Asia/Shanghai", time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC))
+	tests := []struct {
+		text     string
+		code     ErrorCode
+		fragment string
+	}{
+		{"", ErrEmptyTitle, ""},
+		{"#work tomorrow", ErrEmptyTitle, "#work tomorrow"},
+		{"fix it !urgentish", ErrInvalidPriority, "!urgentish"},
+		{"fix it !!!!", ErrInvalidPriority, "!!!!"},
+		{"party 2/30", ErrInvalidDate, "2/30"},
+		{"party 2026-13-01", ErrInvalidDate, "2026-13-01"},
+		{"party in many days", ErrInvalidDate, "in many days"},
+		{"party 13pm", ErrInvalidTime, "13pm"},
+		{"party at 25:00", ErrInvalidTime, "at 25:00"},
+		{"party in 3651 days", ErrInvalidDate, "in 3651 days"},
+		{"party in 9223372036 hours", ErrInvalidDate, "in 9223372036 hours"},
+		{"party remind 2d remind 3d", ErrInvalidReminder, "remind 3d"},
+		{"party remind 366d", ErrInvalidReminder, "remind 366d"},
+		{"party remind 99999999999w", ErrInvalidReminder, "remind 99999999999w"},
+		{"party tomorrow friday", ErrConflictingDates, "friday"},
+		{"party tomorrow in 2 hours", ErrConflictingDates, "tomorrow"},
+		{"party 5pm noon", ErrConflictingDates, "noon"},
+	}
+	for _, tt := range tests {
+		_, err := p.Parse(tt.text)
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("Parse(%q) error = %v, want %s", tt.text, err, tt.code)
+			continue
+		}
+		if pe.Code != tt.code || pe.Fragment != tt.fragment {
+			t.Errorf("Parse(%q) = %s %q, want %s %q", tt.text, pe.Code, pe.Fragment, tt.code, tt.fragment)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+	"todo-ball/apperr"
+	"todo-ball/models"
+)
+
+// Why reminders are quiet
+const (
+	quietDND      = "dnd"
+	quietSchedule = "schedule"
+)
+
+// Longest do-not-disturb that can be set in one go
+const maxDNDMinutes = 7 * 24 * 60
+
+// QuietStatus tells the UIs whether reminders are quiet.
+type QuietStatus struct {
+	Active   bool   `json:"active"`
+	Reason   string `json:"reason"`    // "dnd" or "schedule", "" if not quiet
+	DNDUntil string `json:"dnd_until"` // RFC 3339, "" while do-not-disturb is off
+}
+
+// quietStatus checks the do-not-disturb switch, then the quiet hours.
+func (a *App) quietStatus(now time.Time) QuietStatus {
+	return a.quietStatusFor(a.Store.Config(), now)
+}
+
+// quietStatusFor is quietStatus with the quiet hours of cfg, for callers
+// that already hold a copy of the config.
+func (a *App) quietStatusFor(cfg models.AppConfig, now time.Time) QuietStatus {
+	until, err := a.dnd.Until()
+	if err != nil {
+		slog.Warn("read do-not-disturb failed", "err", err)
+	}
+	if !until.IsZero() {
+		return QuietStatus{Active: true, Reason: quietDND, DNDUntil: until.Format(time.RFC3339)}
+	}
+	if models.InQuietHours(cfg.QuietHours, now) {
+		return QuietStatus{Active: true, Reason: quietSchedule}
+	}
+	return QuietStatus{}
+}
+
+// GetQuietStatus returns whether reminders are quiet right now.
+func (a *App) GetQuietStatus() (QuietStatus, error) {
+	return a.quietStatus(time.Now()), nil
+}
+
+// SetDND turns do-not-disturb on for the given minutes, or off for 0.
+func (a *App) SetDND(minutes int) (QuietStatus, error) {
+	if minutes < 0 || minutes > maxDNDMinutes {
+		return QuietStatus{}, apperr.Invalid("minutes", "dnd.invalid_duration", maxDNDMinutes/60)
+	}
+	var until time.Time
+	if minutes > 0 {
+		until = time.Now().Add(time.Duration(minutes) * time.Minute)
+	}
+	if err := a.dnd.Set(until); err != nil {
+		return QuietStatus{}, apperr.Wrap(apperr.IO, "dnd.save_failed", err)
+	}
+	slog.Info("do-not-disturb changed", "minutes", minutes)
+	a.notifyUpdate()
+	return a.quietStatus(time.Now()), nil
+}
+
+func (a *App) setDNDFromTray(minutes int) {
+	if _, err := a.SetDND(minutes); err != nil {
+		slog.Error("set do-not-disturb from tray failed", "minutes", minutes, "err", err)
+	}
+}
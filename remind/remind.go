@@ -0,0 +1,125 @@
+// Package remind decides when a todo reminds the user: at each of its
+// reminders and again when it becomes overdue. The ball shows a reminder
+// by changing colour and pulsing.
+//
+// Reminders can be held back while the user is busy, for example in a
+// fullscreen app, and are handed out together once they are free. During
+// quiet hours and do-not-disturb they are dropped.
+package remind
+
+import (
+	"sync"
+	"time"
+	"todo-ball/models"
+)
+
+// Kinds of reminder, in the order a todo fires them. A todo with several
+// reminders fires KindUpcoming once for each.
+const (
+	KindUpcoming = "upcoming"
+	KindOverdue  = "overdue"
+)
+
+// Mode says what Check does with reminders that fire.
+type Mode int
+
+const (
+	Deliver  Mode = iota // Return them, along with any queued ones
+	Hold                 // Queue them for a later Deliver
+	Suppress             // Drop them; queued ones stay queued
+)
+
+// Reminder is one todo reaching its reminder window or due time.
+type Reminder struct {
+	TodoID string    `json:"todo_id"`
+	Title  string    `json:"title"`
+	Kind   string    `json:"kind"`
+	At     time.Time `json:"at" ts_type:"string"` // When it fired
+
+	stage stage
+}
+
+// stage is how far a todo has got: its kind, and the reminder or due
+// time that put it there.
+type stage struct {
+	kind string
+	at   int64 // Unix nanoseconds
+}
+
+// Scheduler remembers which reminders have fired, so each fires once.
+// The first Check only records the current state: todos already due when
+// the app starts are shown by the ball's colour, not as new reminders.
+type Scheduler struct {
+	mu     sync.Mutex
+	primed bool
+	fired  map[string]stage // Todo ID -> last stage fired
+	queue  []Reminder       // Held back while busy, oldest first
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{fired: map[string]stage{}}
+}
+
+// Check compares pending with the previous call and handles the
+// reminders that fired since according to mode. It returns what is to be
+// shown now. Queued reminders of todos that were completed or deleted
+// meanwhile are dropped.
+func (s *Scheduler) Check(pending []models.TodoItem, now time.Time, mode Mode) []Reminder {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := map[string]stage{}
+	var fired []Reminder
+	for _, item := range pending {
+		st, ok := stageAt(item, now)
+		if !ok {
+			continue
+		}
+		current[item.ID] = st
+		if s.primed && s.fired[item.ID] != st {
+			fired = append(fired, Reminder{TodoID: item.ID, Title: item.Title, Kind: st.kind, At: now, stage: st})
+		}
+	}
+	s.fired = current
+	s.primed = true
+
+	// A queued reminder is stale once its todo has left the pending list
+	// or moved on to a later reminder, which fired above
+	queue := s.queue[:0]
+	for _, r := range s.queue {
+		if st, ok := current[r.TodoID]; ok && st == r.stage {
+			queue = append(queue, r)
+		}
+	}
+	s.queue = queue
+
+	switch mode {
+	case Hold:
+		s.queue = append(s.queue, fired...)
+		return nil
+	case Suppress:
+		return nil
+	}
+	out := append(s.queue, fired...)
+	s.queue = nil
+	return out
+}
+
+// Queued returns how many reminders are held back.
+func (s *Scheduler) Queued() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queue)
+}
+
+// stageAt returns the reminder of item that fired last, if any.
+func stageAt(item models.TodoItem, now time.Time) (stage, bool) {
+	switch {
+	case item.IsOverdue(now):
+		return stage{kind: KindOverdue, at: item.DueDate.UnixNano()}, true
+	case item.IsUpcoming(now):
+		at, _ := item.LastReminder(now)
+		return stage{kind: KindUpcoming, at: at.UnixNano()}, true
+	}
+	return stage{}, false
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"log/slog"
+)
+
+// migrateReminderDays moves the single reminder of todos saved by older
+// versions into their reminder lists. Main process only.
+func (a *App) migrateReminderDays() {
+	if a.Store.IsLocked() {
+		return
+	}
+	migrated, err := a.Store.MigrateReminderDays()
+	if err != nil {
+		slog.Error("migrate reminder days failed", "err", err)
+		return
+	}
+	if migrated > 0 {
+		slog.Info("migrated reminder days", "count", migrated)
+		a.notifyUpdate()
+	}
+}
@@ -0,0 +1,69 @@
+// Package statefile keeps a small JSON state file shared by the main
+// window and the ball (separate processes). Load re-reads the file only
+// when its modification time or size changed since it was last read or
+// written, so it is cheap enough to call before every operation. Save
+// replaces the file atomically, so the other process never reads half
+// of it.
+package statefile
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// File is one state file holding a T.
+type File[T any] struct {
+	path string
+	seen os.FileInfo // File as last read or written, nil if none
+}
+
+// New returns the state file at path. The file need not exist.
+func New[T any](path string) *File[T] {
+	return &File[T]{path: path}
+}
+
+// Load sets *v to the file's content if the file changed since the last
+// Load or Save, or to the zero T if there is no file.
+func (f *File[T]) Load(v *T) error {
+	info, err := os.Stat(f.path)
+	if os.IsNotExist(err) {
+		var zero T
+		*v = zero
+		f.seen = nil
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if f.seen != nil && info.ModTime().Equal(f.seen.ModTime()) && info.Size() == f.seen.Size() {
+		return nil
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+	var st T
+	if err := json.Unmarshal(data, &st); err != nil {
+		return err
+	}
+	*v = st
+	f.seen = info
+	return nil
+}
+
+// Save writes v to the file.
+func (f *File[T]) Save(v T) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		return err
+	}
+	f.seen, _ = os.Stat(f.path)
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"time"
+	"todo-ball/apperr"
+	"todo-ball/stats"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// GetStats returns productivity figures over all lists and the archive.
+// rangeName is week, last_week, month, last_month, year, all or "<n>d".
+func (a *App) GetStats(rangeName string) (stats.Summary, error) {
+	now := time.Now()
+	r, err := stats.ParseRange(strings.TrimSpace(rangeName), now)
+	if err != nil {
+		e := apperr.Invalid("range", "stats.invalid_range", rangeName)
+		e.Err = err
+		return stats.Summary{}, e
+	}
+	todos, err := a.Store.AllTodos()
+	if err != nil {
+		return stats.Summary{}, storeError("file.read_failed", err)
+	}
+	return stats.Compute(todos, r, now), nil
+}
+
+// GetWeeklyReport renders the summary of an ISO week ("2024-W09", empty
+// for the current week) as markdown or html.
+func (a *App) GetWeeklyReport(week string, format string) (string, error) {
+	if !stats.ValidFormat(format) {
+		return "", apperr.Invalid("format", "report.invalid_format", format)
+	}
+	now := time.Now()
+	start := stats.WeekStart(now)
+	if week = strings.TrimSpace(week); week != "" {
+		var err error
+		if start, err = stats.ParseWeek(week, time.Local); err != nil {
+			e := apperr.Invalid("week", "report.invalid_week", week)
+			e.Err = err
+			return "", e
+		}
+	}
+	todos, err := a.Store.AllTodos()
+	if err != nil {
+		return "", storeError("file.read_failed", err)
+	}
+
+	var buf bytes.Buffer
+	if err := stats.Render(&buf, stats.Weekly(todos, start, now), format); err != nil {
+		return "", apperr.Wrap(apperr.Internal, "internal", err)
+	}
+	return buf.String(), nil
+}
+
+// ExportWeeklyReport asks where to save the weekly report and writes it.
+// Returns the chosen path, or "" if the dialog was cancelled.
+func (a *App) ExportWeeklyReport(week string, format string) (string, error) {
+	report, err := a.GetWeeklyReport(week, format)
+	if err != nil {
+		return "", err
+	}
+
+	ext, name := ".md", "Markdown"
+	if format == stats.FormatHTML {
+		ext, name = ".html", "HTML"
+	}
+	if week == "" {
+		week = stats.WeekName(time.Now())
+	}
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "导出周报",
+		DefaultFilename: "周报-" + week + ext,
+		Filters: []runtime.FileFilter{
+			{DisplayName: name, Pattern: "*" + ext},
+		},
+	})
+	if err != nil {
+		return "", apperr.Wrap(apperr.IO, "dialog.failed", err)
+	}
+	if path == "" {
+		return "", nil
+	}
+	if err := os.WriteFile(path, []byte(report), 0644); err != nil {
+		return "", apperr.Wrap(apperr.IO, "report.save_failed", err)
+	}
+	return path, nil
+}
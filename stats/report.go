@@ -0,0 +1,158 @@
+package stats
+
+import (
+	htmltemplate "html/template"
+	"io"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+	"todo-ball/models"
+)
+
+// Report formats accepted by Render.
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// ReportItem is one line of a weekly report.
+type ReportItem struct {
+	Title string
+	Tags  []string
+	Due   time.Time
+	Done  time.Time // Zero for overdue items
+	Late  bool
+}
+
+// Report is a weekly summary for retrospectives.
+type Report struct {
+	Week      string // ISO week, e.g. 2024-W09
+	Generated time.Time
+	Summary   Summary
+	Completed []ReportItem // Completed during the week, in order
+	Overdue   []ReportItem // Still pending and past due at generation time
+}
+
+// Weekly builds the report for the week starting at weekStart (a Monday,
+// see ParseWeek). now is in the location used for calendar days.
+func Weekly(todos []models.TodoItem, weekStart time.Time, now time.Time) Report {
+	r := Range{From: weekStart, To: weekStart.AddDate(0, 0, 7)}
+	rep := Report{
+		Week:      WeekName(weekStart),
+		Generated: now,
+		Summary:   Compute(todos, r, now),
+	}
+	for _, t := range todos {
+		if done, ok := completedAt(t); ok && in(r, done) {
+			rep.Completed = append(rep.Completed, ReportItem{
+				Title: t.Title,
+				Tags:  t.Tags,
+				Due:   t.DueDate.In(now.Location()),
+				Done:  done.In(now.Location()),
+				Late:  !t.DueDate.IsZero() && done.After(t.DueDate),
+			})
+		} else if t.IsOverdue(now) {
+			rep.Overdue = append(rep.Overdue, ReportItem{Title: t.Title, Tags: t.Tags, Due: t.DueDate.In(now.Location()), Late: true})
+		}
+	}
+	sort.Slice(rep.Completed, func(i, j int) bool { return rep.Completed[i].Done.Before(rep.Completed[j].Done) })
+	sort.Slice(rep.Overdue, func(i, j int) bool { return rep.Overdue[i].Due.Before(rep.Overdue[j].Due) })
+	return rep
+}
+
+var funcs = map[string]any{
+	"percent": func(f float64) int { return int(f*100 + 0.5) },
+	"date":    func(t time.Time) string { return t.Format("01-02 15:04") },
+	"tags":    func(tags []string) string { return strings.Join(tags, " #") },
+	"mdEscape": func(s string) string {
+		return strings.NewReplacer("|", `\|`, "*", `\*`, "_", `\_`, "\n", " ").Replace(s)
+	},
+}
+
+const markdownReport = `# 周报 {{.Week}}
+
+{{.Summary.From}} ~ {{.Summary.To}}
+
+| 指标 | 数值 |
+| --- | --- |
+| 完成 | {{.Summary.Completed}} |
+| 新建 | {{.Summary.Created}} |
+| 按时完成率 | {{percent .Summary.OnTimeRate}}% ({{.Summary.OnTime}} 按时 / {{.Summary.Late}} 逾期) |
+| 平均完成用时 | {{printf "%.1f" .Summary.AvgLeadTimeHours}} 小时 |
+| 连续完成天数 | {{.Summary.CurrentStreak}} (最长 {{.Summary.LongestStreak}}) |
+| 逾期未完成 | {{.Summary.OverdueBacklog}} |
+
+## 每日完成
+
+{{range .Summary.PerDay}}- {{.Date}}: {{.Completed}}
+{{end}}
+## 已完成 ({{len .Completed}})
+
+{{range .Completed}}- {{date .Done}} {{mdEscape .Title}}{{if .Tags}} #{{tags .Tags}}{{end}}{{if .Late}} (逾期){{end}}
+{{else}}无
+{{end}}
+## 逾期未完成 ({{len .Overdue}})
+
+{{range .Overdue}}- 截止 {{date .Due}} {{mdEscape .Title}}{{if .Tags}} #{{tags .Tags}}{{end}}
+{{else}}无
+{{end}}`
+
+const htmlReport = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>周报 {{.Week}}</title>
+<style>
+body { font-family: "Microsoft YaHei", sans-serif; max-width: 800px; margin: 2em auto; color: #333; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ddd; padding: 6px 12px; text-align: left; }
+.late { color: #e74c3c; }
+</style>
+</head>
+<body>
+<h1>周报 {{.Week}}</h1>
+<p>{{.Summary.From}} ~ {{.Summary.To}}</p>
+<table>
+<tr><th>完成</th><td>{{.Summary.Completed}}</td></tr>
+<tr><th>新建</th><td>{{.Summary.Created}}</td></tr>
+<tr><th>按时完成率</th><td>{{percent .Summary.OnTimeRate}}% ({{.Summary.OnTime}} 按时 / {{.Summary.Late}} 逾期)</td></tr>
+<tr><th>平均完成用时</th><td>{{printf "%.1f" .Summary.AvgLeadTimeHours}} 小时</td></tr>
+<tr><th>连续完成天数</th><td>{{.Summary.CurrentStreak}} (最长 {{.Summary.LongestStreak}})</td></tr>
+<tr><th>逾期未完成</th><td>{{.Summary.OverdueBacklog}}</td></tr>
+</table>
+<h2>每日完成</h2>
+<table>
+{{range .Summary.PerDay}}<tr><td>{{.Date}}</td><td>{{.Completed}}</td></tr>
+{{end}}</table>
+<h2>已完成 ({{len .Completed}})</h2>
+<ul>
+{{range .Completed}}<li{{if .Late}} class="late"{{end}}>{{date .Done}} {{.Title}}{{if .Tags}} #{{tags .Tags}}{{end}}{{if .Late}} (逾期){{end}}</li>
+{{else}}<li>无</li>
+{{end}}</ul>
+<h2>逾期未完成 ({{len .Overdue}})</h2>
+<ul>
+{{range .Overdue}}<li class="late">截止 {{date .Due}} {{.Title}}{{if .Tags}} #{{tags .Tags}}{{end}}</li>
+{{else}}<li>无</li>
+{{end}}</ul>
+</body>
+</html>
+`
+
+var (
+	markdownTmpl = texttemplate.Must(texttemplate.New("markdown").Funcs(funcs).Parse(markdownReport))
+	htmlTmpl     = htmltemplate.Must(htmltemplate.New("html").Funcs(funcs).Parse(htmlReport))
+)
+
+// Render writes rep as Markdown or HTML. Titles are escaped for the format.
+func Render(w io.Writer, rep Report, format string) error {
+	if format == FormatHTML {
+		return htmlTmpl.Execute(w, rep)
+	}
+	return markdownTmpl.Execute(w, rep)
+}
+
+// ValidFormat reports whether format is accepted by Render.
+func ValidFormat(format string) bool {
+	return format == FormatMarkdown || format == FormatHTML
+}
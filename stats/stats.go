@@ -0,0 +1,257 @@
+// Package stats computes productivity figures from todos: completions
+// per day and week, on-time rate, lead time, streaks and overdue backlog.
+// Everything is bucketed by calendar day in the given location.
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"todo-ball/models"
+)
+
+const dayLayout = "2006-01-02"
+
+// Range is the half-open interval [From, To). A zero From means "since
+// the first todo".
+type Range struct {
+	From time.Time
+	To   time.Time
+}
+
+// Range names accepted by ParseRange, besides "<n>d" for the last n days.
+const (
+	RangeWeek      = "week"
+	RangeLastWeek  = "last_week"
+	RangeMonth     = "month"
+	RangeLastMonth = "last_month"
+	RangeYear      = "year"
+	RangeAll       = "all"
+)
+
+// MaxRangeDays bounds n in "<n>d", about ten years.
+const MaxRangeDays = 3660
+
+// ParseRange turns a range name into dates relative to now.
+// Weeks start on Monday; "<n>d" allows at most MaxRangeDays days.
+func ParseRange(name string, now time.Time) (Range, error) {
+	today := startOfDay(now)
+	tomorrow := today.AddDate(0, 0, 1)
+	switch name {
+	case RangeWeek, "":
+		return Range{WeekStart(now), tomorrow}, nil
+	case RangeLastWeek:
+		start := WeekStart(now).AddDate(0, 0, -7)
+		return Range{start, start.AddDate(0, 0, 7)}, nil
+	case RangeMonth:
+		return Range{time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()), tomorrow}, nil
+	case RangeLastMonth:
+		end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		return Range{end.AddDate(0, -1, 0), end}, nil
+	case RangeYear:
+		return Range{time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location()), tomorrow}, nil
+	case RangeAll:
+		return Range{To: tomorrow}, nil
+	}
+	if n, err := strconv.Atoi(strings.TrimSuffix(name, "d")); err == nil && strings.HasSuffix(name, "d") && n > 0 {
+		if n > MaxRangeDays {
+			return Range{}, fmt.Errorf("range %q longer than %d days", name, MaxRangeDays)
+		}
+		return Range{today.AddDate(0, 0, 1-n), tomorrow}, nil
+	}
+	return Range{}, fmt.Errorf("unknown range %q", name)
+}
+
+// WeekStart returns Monday 00:00 of the week containing t.
+func WeekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return startOfDay(t).AddDate(0, 0, -offset)
+}
+
+// ParseWeek parses an ISO week such as "2024-W09" in loc.
+func ParseWeek(s string, loc *time.Location) (time.Time, error) {
+	var year, week int
+	if _, err := fmt.Sscanf(s, "%d-W%d", &year, &week); err != nil || week < 1 || week > 53 {
+		return time.Time{}, fmt.Errorf("invalid week %q, want YYYY-Www", s)
+	}
+	// 4 January is always in week 1
+	jan4 := time.Date(year, 1, 4, 0, 0, 0, 0, loc)
+	return WeekStart(jan4).AddDate(0, 0, 7*(week-1)), nil
+}
+
+// WeekName formats the ISO week of t, e.g. "2024-W09".
+func WeekName(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+type DayCount struct {
+	Date      string `json:"date"` // YYYY-MM-DD
+	Completed int    `json:"completed"`
+}
+
+type WeekCount struct {
+	Week      string `json:"week"`  // ISO week, e.g. 2024-W09
+	Start     string `json:"start"` // Monday, YYYY-MM-DD
+	Completed int    `json:"completed"`
+}
+
+// Summary holds the figures for one range.
+type Summary struct {
+	From string `json:"from"` // YYYY-MM-DD, empty for "all"
+	To   string `json:"to"`   // YYYY-MM-DD, inclusive
+
+	Completed int `json:"completed"`
+	Created   int `json:"created"`
+	OnTime    int `json:"on_time"` // Completed no later than the due date
+	Late      int `json:"late"`
+	// OnTime / (OnTime + Late), 0 when nothing had a due date
+	OnTimeRate float64 `json:"on_time_rate"`
+	// Mean time from creation to completion
+	AvgLeadTimeHours float64 `json:"avg_lead_time_hours"`
+
+	// Days in a row with at least one completion, ending today (or
+	// yesterday, so the streak isn't lost before today's first one)
+	CurrentStreak int `json:"current_streak"`
+	LongestStreak int `json:"longest_streak"`
+
+	// Pending todos already past due, at the time of computing
+	OverdueBacklog int `json:"overdue_backlog"`
+
+	PerDay  []DayCount  `json:"per_day"`
+	PerWeek []WeekCount `json:"per_week"`
+}
+
+// completedAt returns the current completion time of a completed todo.
+func completedAt(t models.TodoItem) (time.Time, bool) {
+	if !t.Completed || t.CompletedAt == nil {
+		return time.Time{}, false
+	}
+	return *t.CompletedAt, true
+}
+
+func in(r Range, t time.Time) bool {
+	return !t.Before(r.From) && t.Before(r.To)
+}
+
+// Compute summarises todos (from every list and the archive) over r.
+// now is in the location used for calendar days.
+func Compute(todos []models.TodoItem, r Range, now time.Time) Summary {
+	loc := now.Location()
+	var s Summary
+	if !r.From.IsZero() {
+		s.From = r.From.In(loc).Format(dayLayout)
+	}
+	s.To = r.To.In(loc).Add(-time.Nanosecond).Format(dayLayout)
+
+	perDay := map[string]int{}
+	allDays := map[string]bool{}
+	var leadTotal time.Duration
+	first := r.From
+
+	for _, t := range todos {
+		if in(r, t.CreatedAt) {
+			s.Created++
+		}
+		if t.IsOverdue(now) {
+			s.OverdueBacklog++
+		}
+		done, ok := completedAt(t)
+		if !ok {
+			continue
+		}
+		day := done.In(loc).Format(dayLayout)
+		allDays[day] = true
+		if !in(r, done) {
+			continue
+		}
+
+		s.Completed++
+		perDay[day]++
+		if first.IsZero() || done.Before(first) {
+			first = done
+		}
+		if !t.DueDate.IsZero() {
+			if done.After(t.DueDate) {
+				s.Late++
+			} else {
+				s.OnTime++
+			}
+		}
+		if !t.CreatedAt.IsZero() && done.After(t.CreatedAt) {
+			leadTotal += done.Sub(t.CreatedAt)
+		}
+	}
+
+	if s.OnTime+s.Late > 0 {
+		s.OnTimeRate = float64(s.OnTime) / float64(s.OnTime+s.Late)
+	}
+	if s.Completed > 0 {
+		s.AvgLeadTimeHours = leadTotal.Hours() / float64(s.Completed)
+	}
+	s.CurrentStreak, s.LongestStreak = streaks(allDays, now)
+	s.PerDay, s.PerWeek = buckets(perDay, first, r.To, loc)
+	if s.From == "" && !first.IsZero() {
+		s.From = first.In(loc).Format(dayLayout)
+	}
+	return s
+}
+
+// buckets lists every day and week in [from, to), including empty ones,
+// so charts have no gaps.
+func buckets(perDay map[string]int, from, to time.Time, loc *time.Location) ([]DayCount, []WeekCount) {
+	days := []DayCount{}
+	weeks := []WeekCount{}
+	if from.IsZero() {
+		return days, weeks
+	}
+	for d := startOfDay(from.In(loc)); d.Before(to); d = d.AddDate(0, 0, 1) {
+		key := d.Format(dayLayout)
+		days = append(days, DayCount{Date: key, Completed: perDay[key]})
+
+		week := WeekName(d)
+		if len(weeks) == 0 || weeks[len(weeks)-1].Week != week {
+			weeks = append(weeks, WeekCount{Week: week, Start: WeekStart(d).Format(dayLayout)})
+		}
+		weeks[len(weeks)-1].Completed += perDay[key]
+	}
+	return days, weeks
+}
+
+func streaks(days map[string]bool, now time.Time) (current, longest int) {
+	keys := make([]string, 0, len(days))
+	for d := range days {
+		keys = append(keys, d)
+	}
+	sort.Strings(keys)
+
+	run := 0
+	var prev time.Time
+	for _, k := range keys {
+		d, _ := time.ParseInLocation(dayLayout, k, now.Location())
+		if run > 0 && prev.AddDate(0, 0, 1).Equal(d) {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+		prev = d
+	}
+
+	day := startOfDay(now)
+	if !days[day.Format(dayLayout)] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for days[day.Format(dayLayout)] {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+	return current, longest
+}
@@ -0,0 +1,214 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"todo-ball/models"
+)
+
+func mustZone(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("zone %s not available: %v", name, err)
+	}
+	return loc
+}
+
+func TestParseRange(t *testing.T) {
+	shanghai := mustZone(t, "Asia/Shanghai")
+	// Wednesday
+	now := time.Date(2026, 3, 4, 15, 0, 0, 0, shanghai)
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 0, 0, 0, 0, shanghai) }
+	tomorrow := day(3, 5)
+
+	tests := []struct {
+		name string
+		want Range
+	}{
+		{"", Range{day(3, 2), tomorrow}},
+		{RangeWeek, Range{day(3, 2), tomorrow}},
+		{RangeLastWeek, Range{day(2, 23), day(3, 2)}},
+		{RangeMonth, Range{day(3, 1), tomorrow}},
+		{RangeLastMonth, Range{day(2, 1), day(3, 1)}},
+		{RangeYear, Range{day(1, 1), tomorrow}},
+		{RangeAll, Range{To: tomorrow}},
+		{"1d", Range{day(3, 4), tomorrow}},
+		{"7d", Range{day(2, 26), tomorrow}},
+		{"3660d", Range{time.Date(2016, 2, 26, 0, 0, 0, 0, shanghai), tomorrow}},
+	}
+	for _, tt := range tests {
+		got, err := ParseRange(tt.name, now)
+		if err != nil {
+			t.Errorf("ParseRange(%q): %v", tt.name, err)
+			continue
+		}
+		if !got.From.Equal(tt.want.From) || !got.To.Equal(tt.want.To) {
+			t.Errorf("ParseRange(%q) = %s - %s, want %s - %s", tt.name, got.From, got.To, tt.want.From, tt.want.To)
+		}
+	}
+
+	for _, name := range []string{"3661d", "99999999999999999999d", "0d", "-1d", "7", "d", "xd", "fortnight"} {
+		if _, err := ParseRange(name, now); err == nil {
+			t.Errorf("ParseRange(%q) succeeded", name)
+		}
+	}
+}
+
+func TestParseWeek(t *testing.T) {
+	shanghai := mustZone(t, "Asia/Shanghai")
+	tests := []struct {
+		week string
+		want time.Time
+	}{
+		{"2026-W10", time.Date(2026, 3, 2, 0, 0, 0, 0, shanghai)},
+		{"2026-W01", time.Date(2025, 12, 29, 0, 0, 0, 0, shanghai)}, // Starts in the year before
+		{"2021-W01", time.Date(2021, 1, 4, 0, 0, 0, 0, shanghai)},   // 1-3 January are in 2020-W53
+		{"2020-W53", time.Date(2020, 12, 28, 0, 0, 0, 0, shanghai)},
+		{"2024-W09", time.Date(2024, 2, 26, 0, 0, 0, 0, shanghai)},
+	}
+	for _, tt := range tests {
+		got, err := ParseWeek(tt.week, shanghai)
+		if err != nil {
+			t.Errorf("ParseWeek(%q): %v", tt.week, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseWeek(%q) = %s, want %s", tt.week, got, tt.want)
+		}
+		if name := WeekName(got); name != tt.week {
+			t.Errorf("WeekName(ParseWeek(%q)) = %q", tt.week, name)
+		}
+	}
+	if got := WeekName(time.Date(2021, 1, 3, 12, 0, 0, 0, shanghai)); got != "2020-W53" {
+		t.Errorf("WeekName(2021-01-03) = %q, want 2020-W53", got)
+	}
+
+	for _, week := range []string{"2026-W00", "2026-W54", "2026-10", "W10", ""} {
+		if _, err := ParseWeek(week, shanghai); err == nil {
+			t.Errorf("ParseWeek(%q) succeeded", week)
+		}
+	}
+}
+
+func TestStreaks(t *testing.T) {
+	shanghai := mustZone(t, "Asia/Shanghai")
+	ny := mustZone(t, "America/New_York")
+	set := func(days ...string) map[string]bool {
+		m := map[string]bool{}
+		for _, d := range days {
+			m[d] = true
+		}
+		return m
+	}
+	now := time.Date(2026, 3, 4, 15, 0, 0, 0, shanghai)
+
+	tests := []struct {
+		name             string
+		days             map[string]bool
+		now              time.Time
+		current, longest int
+	}{
+		{"none", set(), now, 0, 0},
+		{"ending today", set("2026-03-03", "2026-03-04"), now, 2, 2},
+		{"ending yesterday", set("2026-03-01", "2026-03-02", "2026-03-03"), now, 3, 3},
+		{"gap before yesterday", set("2026-03-01", "2026-03-02"), now, 0, 2},
+		{"longer one before a gap", set("2026-02-20", "2026-02-21", "2026-02-22", "2026-02-23", "2026-03-03", "2026-03-04"), now, 2, 4},
+		{"across the month and year", set("2025-12-31", "2026-01-01", "2026-01-02"), now, 0, 3},
+		// The day the US clocks go forward is 23 hours long
+		{"across DST", set("2026-03-07", "2026-03-08", "2026-03-09"), time.Date(2026, 3, 9, 8, 0, 0, 0, ny), 3, 3},
+	}
+	for _, tt := range tests {
+		current, longest := streaks(tt.days, tt.now)
+		if current != tt.current || longest != tt.longest {
+			t.Errorf("%s: streaks = %d, %d, want %d, %d", tt.name, current, longest, tt.current, tt.longest)
+		}
+	}
+}
+
+func TestCompute(t *testing.T) {
+	shanghai := mustZone(t, "Asia/Shanghai")
+	at := func(d, h, min int) time.Time { return time.Date(2026, 3, d, h, min, 0, 0, shanghai) }
+	ptr := func(t time.Time) *time.Time { return &t }
+	now := at(4, 15, 0)
+	// All-day todos are due at the midnight ending their day
+	todos := []models.TodoItem{
+		{ID: "last minute", DueDate: at(4, 0, 0), AllDay: true, TimeZone: "Asia/Shanghai",
+			CreatedAt: at(3, 11, 59), Completed: true, CompletedAt: ptr(at(3, 23, 59))},
+		{ID: "next morning", DueDate: at(4, 0, 0), AllDay: true, TimeZone: "Asia/Shanghai",
+			CreatedAt: at(3, 0, 1), Completed: true, CompletedAt: ptr(at(4, 0, 1))},
+		{ID: "exactly due", DueDate: at(2, 10, 0), TimeZone: "Asia/Shanghai",
+			CreatedAt: at(2, 7, 0), Completed: true, CompletedAt: ptr(at(2, 10, 0))},
+		{ID: "last week", DueDate: at(1, 0, 0), TimeZone: "Asia/Shanghai",
+			CreatedAt: time.Date(2026, 2, 27, 9, 0, 0, 0, shanghai), Completed: true, CompletedAt: ptr(at(1, 9, 0))},
+		{ID: "overdue", DueDate: at(4, 0, 0), AllDay: true, TimeZone: "Asia/Shanghai", CreatedAt: at(2, 9, 0)},
+		{ID: "due later", DueDate: at(6, 0, 0), AllDay: true, TimeZone: "Asia/Shanghai", CreatedAt: at(4, 9, 0)},
+	}
+	r, err := ParseRange(RangeWeek, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Compute(todos, r, now)
+	want := Summary{
+		From:             "2026-03-02",
+		To:               "2026-03-04",
+		Completed:        3,
+		Created:          5,
+		OnTime:           2,
+		Late:             1,
+		OnTimeRate:       2.0 / 3,
+		AvgLeadTimeHours: (12 + 24 + 3) / 3.0,
+		CurrentStreak:    4, // 1-4 March
+		LongestStreak:    4,
+		OverdueBacklog:   1,
+		PerDay: []DayCount{
+			{Date: "2026-03-02", Completed: 1},
+			{Date: "2026-03-03", Completed: 1},
+			{Date: "2026-03-04", Completed: 1},
+		},
+		PerWeek: []WeekCount{{Week: "2026-W10", Start: "2026-03-02", Completed: 3}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Compute =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// "all" starts at the first completion
+	r, _ = ParseRange(RangeAll, now)
+	got = Compute(todos, r, now)
+	if got.From != "2026-03-01" || got.Completed != 4 || len(got.PerDay) != 4 || len(got.PerWeek) != 2 {
+		t.Errorf("Compute(all) = %+v, want 4 days from 2026-03-01 over two weeks", got)
+	}
+}
+
+func TestBuckets(t *testing.T) {
+	shanghai := mustZone(t, "Asia/Shanghai")
+	perDay := map[string]int{"2026-02-27": 2, "2026-03-02": 1}
+	days, weeks := buckets(perDay, time.Date(2026, 2, 27, 15, 0, 0, 0, shanghai), time.Date(2026, 3, 4, 0, 0, 0, 0, shanghai), shanghai)
+	wantDays := []DayCount{
+		{Date: "2026-02-27", Completed: 2},
+		{Date: "2026-02-28"},
+		{Date: "2026-03-01"},
+		{Date: "2026-03-02", Completed: 1},
+		{Date: "2026-03-03"},
+	}
+	wantWeeks := []WeekCount{
+		{Week: "2026-W09", Start: "2026-02-23", Completed: 2},
+		{Week: "2026-W10", Start: "2026-03-02", Completed: 1},
+	}
+	if !reflect.DeepEqual(days, wantDays) || !reflect.DeepEqual(weeks, wantWeeks) {
+		t.Errorf("buckets = %+v %+v, want %+v %+v", days, weeks, wantDays, wantWeeks)
+	}
+
+	// Each calendar day once, whatever its length
+	ny := mustZone(t, "America/New_York")
+	days, _ = buckets(nil, time.Date(2026, 3, 7, 12, 0, 0, 0, ny), time.Date(2026, 3, 10, 0, 0, 0, 0, ny), ny)
+	if len(days) != 3 || days[1].Date != "2026-03-08" || days[2].Date != "2026-03-09" {
+		t.Errorf("days across DST = %+v", days)
+	}
+
+	days, weeks = buckets(perDay, time.Time{}, time.Now(), shanghai)
+	if days == nil || weeks == nil || len(days)+len(weeks) != 0 {
+		t.Errorf("buckets without a start = %v %v, want empty slices", days, weeks)
+	}
+}
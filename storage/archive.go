@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+	"todo-ball/models"
+)
+
+// ArchiveListID is the backend list holding the archived todos of list.
+func ArchiveListID(list string) string {
+	return "archive-" + list
+}
+
+// allListIDsLocked returns every backend list: the user's lists and
+// their archives.
+func (s *Storage) allListIDsLocked() []string {
+	ids := make([]string, 0, 2*len(s.Lists))
+	for _, l := range s.Lists {
+		ids = append(ids, l.ID, ArchiveListID(l.ID))
+	}
+	return ids
+}
+
+// ArchiveCompleted moves todos completed before cutoff from every list
+// into its archive and returns how many were moved.
+func (s *Storage) ArchiveCompleted(cutoff time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lockedLocked() {
+		return 0, ErrLocked
+	}
+
+	moved := 0
+	for _, l := range s.Lists {
+		done, err := s.backend.ByCompletion(l.ID, true)
+		if err != nil {
+			return moved, err
+		}
+		archive := ArchiveListID(l.ID)
+		for _, t := range done {
+			if t.CompletedAt == nil || !t.CompletedAt.Before(cutoff) {
+				continue
+			}
+			// Copy first: a crash in between leaves a duplicate, never a loss
+			err := s.backend.Add(archive, t)
+			if errors.Is(err, ErrDuplicateID) {
+				err = s.backend.Update(archive, t)
+			}
+			if err != nil {
+				return moved, err
+			}
+			if err := s.backend.Delete(l.ID, t.ID); err != nil && !errors.Is(err, ErrNotFound) {
+				return moved, err
+			}
+			moved++
+		}
+	}
+
+	if moved > 0 {
+		todos, err := s.backend.Todos(s.config.ActiveList)
+		if err != nil {
+			return moved, err
+		}
+		s.Todos = todos
+	}
+	return moved, nil
+}
+
+// SearchArchive returns completed todos, archived or not, whose
+// completion time is in [from, to) and whose title or tags contain query.
+// Zero from/to leave that end open. Newest first.
+func (s *Storage) SearchArchive(from, to time.Time, query string) ([]models.ArchiveEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.lockedLocked() {
+		return nil, ErrLocked
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	var entries []models.ArchiveEntry
+	for _, l := range s.Lists {
+		archived, err := s.backend.Todos(ArchiveListID(l.ID))
+		if err != nil {
+			return nil, err
+		}
+		done, err := s.backend.ByCompletion(l.ID, true)
+		if err != nil {
+			return nil, err
+		}
+		for i, t := range append(archived, done...) {
+			at := completedAt(t)
+			if at.IsZero() ||
+				!from.IsZero() && at.Before(from) ||
+				!to.IsZero() && !at.Before(to) ||
+				!matchesQuery(t, query) {
+				continue
+			}
+			entries = append(entries, models.ArchiveEntry{
+				Todo:     t,
+				ListID:   l.ID,
+				ListName: l.Name,
+				Archived: i < len(archived),
+			})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return completedAt(entries[i].Todo).After(completedAt(entries[j].Todo))
+	})
+	return entries, nil
+}
+
+// completedAt falls back to the history for items whose CompletedAt was
+// lost, e.g. written by an older version.
+func completedAt(t models.TodoItem) time.Time {
+	if t.CompletedAt != nil {
+		return *t.CompletedAt
+	}
+	for i := len(t.History) - 1; i >= 0; i-- {
+		if t.History[i].Type == models.EventCompleted {
+			return t.History[i].At
+		}
+	}
+	return time.Time{}
+}
+
+func matchesQuery(t models.TodoItem, query string) bool {
+	if query == "" || strings.Contains(strings.ToLower(t.Title), query) {
+		return true
+	}
+	for _, tag := range t.Tags {
+		if strings.Contains(strings.ToLower(tag), query) {
+			return true
+		}
+	}
+	return false
+}
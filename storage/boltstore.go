@@ -0,0 +1,482 @@
+package storage
+
+import (
+	"encoding/json"
+	"time"
+	"todo-ball/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltFileName holds every list when the bolt backend is in use.
+const BoltFileName = "todos.db"
+
+// Bucket layout:
+//
+//	lists/<list id>/todos  ID -> encoded TodoItem
+//	lists/<list id>/due    dueKey(due date, ID) -> nil
+//	lists/<list id>/done   doneKey(completed, ID) -> nil
+//	lists/<list id>/rekey  ID -> record re-encoded by an unfinished Recode
+//
+// The indexes are never encrypted, so an encrypted store still reveals
+// due dates and completion state, but not titles or tags.
+var (
+	bucketLists = []byte("lists")
+	bucketTodos = []byte("todos")
+	bucketDue   = []byte("due")
+	bucketDone  = []byte("done")
+	bucketRekey = []byte("rekey")
+)
+
+// dueKeyLayout sorts lexically in time order, including the zero time.
+const dueKeyLayout = "20060102T150405.000000000"
+
+// boltStore is a transactional backend for large lists: each change
+// writes one record instead of the whole list.
+//
+// Main and ball both use the store, and bolt locks the file while it is
+// open, so every operation opens and closes the database. Read-only
+// transactions take a shared lock and don't block each other.
+type boltStore struct {
+	path  string
+	codec Codec
+}
+
+// NewBoltStore opens (creating if needed) the bolt file at path.
+func NewBoltStore(path string, codec Codec) (Store, error) {
+	b := &boltStore{path: path, codec: codec}
+	err := b.update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketLists)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *boltStore) open(readOnly bool) (*bolt.DB, error) {
+	return bolt.Open(b.path, 0600, &bolt.Options{Timeout: 2 * time.Second, ReadOnly: readOnly})
+}
+
+func (b *boltStore) view(fn func(tx *bolt.Tx) error) error {
+	db, err := b.open(true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.View(fn)
+}
+
+func (b *boltStore) update(fn func(tx *bolt.Tx) error) error {
+	db, err := b.open(false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Update(fn)
+}
+
+// listBucket returns the bucket of list, or nil if it doesn't exist.
+func listBucket(tx *bolt.Tx, list string) *bolt.Bucket {
+	lists := tx.Bucket(bucketLists)
+	if lists == nil {
+		return nil
+	}
+	return lists.Bucket([]byte(list))
+}
+
+func createListBucket(tx *bolt.Tx, list string) (*bolt.Bucket, error) {
+	lists, err := tx.CreateBucketIfNotExists(bucketLists)
+	if err != nil {
+		return nil, err
+	}
+	lb, err := lists.CreateBucketIfNotExists([]byte(list))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range [][]byte{bucketTodos, bucketDue, bucketDone} {
+		if _, err := lb.CreateBucketIfNotExists(name); err != nil {
+			return nil, err
+		}
+	}
+	return lb, nil
+}
+
+func recordName(list, id string) string {
+	return list + "/" + id
+}
+
+func dueKey(due time.Time, id string) []byte {
+	return []byte(due.UTC().Format(dueKeyLayout) + "\x00" + id)
+}
+
+func doneKey(completed bool, id string) []byte {
+	if completed {
+		return []byte("1\x00" + id)
+	}
+	return []byte("0\x00" + id)
+}
+
+func (b *boltStore) decode(list string, id, data []byte, codec Codec) (models.TodoItem, error) {
+	var item models.TodoItem
+	plain, err := codec.Decode(recordName(list, string(id)), data)
+	if err != nil {
+		return item, err
+	}
+	err = json.Unmarshal(plain, &item)
+	return item, err
+}
+
+// get decodes the record id, which must be stored under lb.
+func (b *boltStore) get(lb *bolt.Bucket, list string, id []byte) (models.TodoItem, bool, error) {
+	data := lb.Bucket(bucketTodos).Get(id)
+	if data == nil {
+		return models.TodoItem{}, false, nil
+	}
+	item, err := b.decode(list, id, data, b.codec)
+	return item, true, err
+}
+
+func (b *boltStore) put(lb *bolt.Bucket, list string, item models.TodoItem) error {
+	plain, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	data, err := b.codec.Encode(recordName(list, item.ID), plain)
+	if err != nil {
+		return err
+	}
+	id := []byte(item.ID)
+	if err := lb.Bucket(bucketTodos).Put(id, data); err != nil {
+		return err
+	}
+	if err := lb.Bucket(bucketDue).Put(dueKey(item.DueDate, item.ID), nil); err != nil {
+		return err
+	}
+	return lb.Bucket(bucketDone).Put(doneKey(item.Completed, item.ID), nil)
+}
+
+func (b *boltStore) remove(lb *bolt.Bucket, old models.TodoItem) error {
+	if err := lb.Bucket(bucketTodos).Delete([]byte(old.ID)); err != nil {
+		return err
+	}
+	if err := lb.Bucket(bucketDue).Delete(dueKey(old.DueDate, old.ID)); err != nil {
+		return err
+	}
+	return lb.Bucket(bucketDone).Delete(doneKey(old.Completed, old.ID))
+}
+
+// Todos returns the list ordered by ID, which is creation order for
+// IDs generated by the app.
+func (b *boltStore) Todos(list string) ([]models.TodoItem, error) {
+	todos := []models.TodoItem{}
+	pending := false
+	err := b.view(func(tx *bolt.Tx) error {
+		lb := listBucket(tx, list)
+		if lb == nil {
+			return nil
+		}
+		if staged := lb.Bucket(bucketRekey); staged != nil {
+			done, err := b.stagedCommitted(list, staged)
+			if err != nil {
+				return err
+			}
+			if done {
+				pending = true
+				return nil
+			}
+			// Not committed: the originals are still the current records
+		}
+		return lb.Bucket(bucketTodos).ForEach(func(id, data []byte) error {
+			item, err := b.decode(list, id, data, b.codec)
+			if err != nil {
+				return err
+			}
+			todos = append(todos, item)
+			return nil
+		})
+	})
+	if err != nil || !pending {
+		return todos, err
+	}
+	if err := b.finishRecode(list); err != nil {
+		return nil, err
+	}
+	return b.Todos(list)
+}
+
+// stagedCommitted reports whether the records staged by a Recode belong
+// to the committed side, see recodeCommitted. All were staged together,
+// so the first one tells. An empty stage has nothing to lose.
+func (b *boltStore) stagedCommitted(list string, staged *bolt.Bucket) (bool, error) {
+	k, v := staged.Cursor().First()
+	if k == nil {
+		return true, nil
+	}
+	return recodeCommitted(b.codec, recordName(list, string(k)), v)
+}
+
+// finishRecode completes a Recode that was committed but didn't get to
+// move the staged records, because it crashed or because it runs in the
+// other process. Staged records that aren't committed are kept: only
+// Recode itself replaces them.
+func (b *boltStore) finishRecode(list string) error {
+	return b.update(func(tx *bolt.Tx) error {
+		lb := listBucket(tx, list)
+		if lb == nil || lb.Bucket(bucketRekey) == nil {
+			return nil
+		}
+		staged := lb.Bucket(bucketRekey)
+		done, err := b.stagedCommitted(list, staged)
+		if err != nil || !done {
+			return err
+		}
+		todos := lb.Bucket(bucketTodos)
+		if err := staged.ForEach(func(id, data []byte) error {
+			return todos.Put(id, data)
+		}); err != nil {
+			return err
+		}
+		return lb.DeleteBucket(bucketRekey)
+	})
+}
+
+func (b *boltStore) Add(list string, item models.TodoItem) error {
+	return b.update(func(tx *bolt.Tx) error {
+		lb, err := createListBucket(tx, list)
+		if err != nil {
+			return err
+		}
+		if lb.Bucket(bucketTodos).Get([]byte(item.ID)) != nil {
+			return ErrDuplicateID
+		}
+		return b.put(lb, list, item)
+	})
+}
+
+func (b *boltStore) Update(list string, item models.TodoItem) error {
+	return b.update(func(tx *bolt.Tx) error {
+		lb := listBucket(tx, list)
+		if lb == nil {
+			return ErrNotFound
+		}
+		old, ok, err := b.get(lb, list, []byte(item.ID))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrNotFound
+		}
+		if err := b.remove(lb, old); err != nil {
+			return err
+		}
+		return b.put(lb, list, item)
+	})
+}
+
+func (b *boltStore) Delete(list string, id string) error {
+	return b.update(func(tx *bolt.Tx) error {
+		lb := listBucket(tx, list)
+		if lb == nil {
+			return ErrNotFound
+		}
+		old, ok, err := b.get(lb, list, []byte(id))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrNotFound
+		}
+		return b.remove(lb, old)
+	})
+}
+
+func (b *boltStore) Replace(list string, todos []models.TodoItem) error {
+	return b.update(func(tx *bolt.Tx) error {
+		lists, err := tx.CreateBucketIfNotExists(bucketLists)
+		if err != nil {
+			return err
+		}
+		if lists.Bucket([]byte(list)) != nil {
+			if err := lists.DeleteBucket([]byte(list)); err != nil {
+				return err
+			}
+		}
+		lb, err := createListBucket(tx, list)
+		if err != nil {
+			return err
+		}
+		for _, item := range todos {
+			if lb.Bucket(bucketTodos).Get([]byte(item.ID)) != nil {
+				return ErrDuplicateID
+			}
+			if err := b.put(lb, list, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// collect decodes the records named by index keys (key prefix + "\x00" + ID).
+func (b *boltStore) collect(lb *bolt.Bucket, list string, c *bolt.Cursor, k []byte, more func(k []byte) bool) ([]models.TodoItem, error) {
+	var todos []models.TodoItem
+	for ; k != nil && more(k); k, _ = c.Next() {
+		sep := lastZero(k)
+		if sep < 0 {
+			continue
+		}
+		item, ok, err := b.get(lb, list, k[sep+1:])
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			todos = append(todos, item)
+		}
+	}
+	return todos, nil
+}
+
+func lastZero(k []byte) int {
+	for i := len(k) - 1; i >= 0; i-- {
+		if k[i] == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+func (b *boltStore) DueBetween(list string, from, to time.Time) ([]models.TodoItem, error) {
+	var todos []models.TodoItem
+	err := b.view(func(tx *bolt.Tx) error {
+		lb := listBucket(tx, list)
+		if lb == nil {
+			return nil
+		}
+		c := lb.Bucket(bucketDue).Cursor()
+		start := []byte(from.UTC().Format(dueKeyLayout))
+		end := string(to.UTC().Format(dueKeyLayout))
+		k, _ := c.Seek(start)
+		var err error
+		todos, err = b.collect(lb, list, c, k, func(k []byte) bool {
+			return string(k[:len(dueKeyLayout)]) < end
+		})
+		return err
+	})
+	return todos, err
+}
+
+func (b *boltStore) ByCompletion(list string, completed bool) ([]models.TodoItem, error) {
+	var todos []models.TodoItem
+	prefix := doneKey(completed, "")
+	err := b.view(func(tx *bolt.Tx) error {
+		lb := listBucket(tx, list)
+		if lb == nil {
+			return nil
+		}
+		c := lb.Bucket(bucketDone).Cursor()
+		k, _ := c.Seek(prefix)
+		var err error
+		todos, err = b.collect(lb, list, c, k, func(k []byte) bool {
+			return len(k) >= len(prefix) && string(k[:len(prefix)]) == string(prefix)
+		})
+		return err
+	})
+	return todos, err
+}
+
+func (b *boltStore) DropList(list string) error {
+	return b.update(func(tx *bolt.Tx) error {
+		lists := tx.Bucket(bucketLists)
+		if lists == nil || lists.Bucket([]byte(list)) == nil {
+			return nil
+		}
+		return lists.DeleteBucket([]byte(list))
+	})
+}
+
+// Recode stages the re-encoded records in a rekey bucket, commits, then
+// moves them over the originals. Todos finishes the move after a crash.
+func (b *boltStore) Recode(lists []string, from, to Codec, commit func() error) error {
+	for _, list := range lists {
+		if err := b.finishRecode(list); err != nil {
+			return err
+		}
+	}
+	err := b.update(func(tx *bolt.Tx) error {
+		for _, list := range lists {
+			lb := listBucket(tx, list)
+			if lb == nil {
+				continue
+			}
+			if lb.Bucket(bucketRekey) != nil {
+				if err := lb.DeleteBucket(bucketRekey); err != nil {
+					return err
+				}
+			}
+			staged, err := lb.CreateBucket(bucketRekey)
+			if err != nil {
+				return err
+			}
+			err = lb.Bucket(bucketTodos).ForEach(func(id, data []byte) error {
+				name := recordName(list, string(id))
+				plain, err := from.Decode(name, data)
+				if err != nil {
+					return err
+				}
+				sealed, err := to.Encode(name, plain)
+				if err != nil {
+					return err
+				}
+				return staged.Put(id, sealed)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := commit(); err != nil {
+		b.dropStaged(lists)
+		return err
+	}
+
+	return b.update(func(tx *bolt.Tx) error {
+		for _, list := range lists {
+			lb := listBucket(tx, list)
+			if lb == nil || lb.Bucket(bucketRekey) == nil {
+				continue
+			}
+			todos := lb.Bucket(bucketTodos)
+			if err := lb.Bucket(bucketRekey).ForEach(func(id, data []byte) error {
+				return todos.Put(id, data)
+			}); err != nil {
+				return err
+			}
+			if err := lb.DeleteBucket(bucketRekey); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *boltStore) dropStaged(lists []string) {
+	b.update(func(tx *bolt.Tx) error {
+		for _, list := range lists {
+			if lb := listBucket(tx, list); lb != nil && lb.Bucket(bucketRekey) != nil {
+				if err := lb.DeleteBucket(bucketRekey); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (b *boltStore) Close() error { return nil }
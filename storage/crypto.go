@@ -0,0 +1,418 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Encryption at rest.
+//
+// encryption.json holds the Argon2id parameters, the salt and a check
+// value sealed with the derived key. Each todo file is then stored as an
+// envelope {"format", "kid", "nonce", "data"} sealed with XChaCha20-Poly1305,
+// using the record name as associated data so records can't be swapped.
+// Plain records are still accepted when reading, which lets enabling,
+// disabling and re-keying rewrite them one at a time. The encoding is
+// done by keyCodec, the backend only stores the bytes.
+
+const (
+	EncryptionFileName = "encryption.json"
+	envelopeFormat     = "todo-ball-encrypted-v1"
+
+	MinPassphraseLength = 8
+)
+
+var (
+	ErrLocked             = errors.New("store is locked")
+	ErrWrongPassphrase    = errors.New("wrong passphrase")
+	ErrNotEncrypted       = errors.New("store is not encrypted")
+	ErrAlreadyEncrypted   = errors.New("store is already encrypted")
+	ErrPassphraseTooShort = errors.New("passphrase too short")
+)
+
+var checkPlaintext = []byte("todo-ball key check")
+
+type kdfParams struct {
+	Salt    []byte `json:"salt"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"` // KiB
+	Threads uint8  `json:"threads"`
+}
+
+type encryptionInfo struct {
+	Version int       `json:"version"`
+	KDF     string    `json:"kdf"`
+	Cipher  string    `json:"cipher"`
+	Params  kdfParams `json:"params"`
+	Check   []byte    `json:"check"` // nonce || sealed checkPlaintext
+}
+
+type envelope struct {
+	Format string `json:"format"`
+	KeyID  string `json:"kid"`
+	Nonce  []byte `json:"nonce"`
+	Data   []byte `json:"data"`
+}
+
+func newEncryptionInfo(passphrase string) (*encryptionInfo, []byte, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, nil, err
+	}
+	info := &encryptionInfo{
+		Version: 1,
+		KDF:     "argon2id",
+		Cipher:  "xchacha20poly1305",
+		Params:  kdfParams{Salt: salt, Time: 3, Memory: 64 * 1024, Threads: 4},
+	}
+	key := info.deriveKey(passphrase)
+	check, err := seal(key, checkPlaintext, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	info.Check = check
+	return info, key, nil
+}
+
+func (info *encryptionInfo) deriveKey(passphrase string) []byte {
+	p := info.Params
+	return argon2.IDKey([]byte(passphrase), p.Salt, p.Time, p.Memory, p.Threads, chacha20poly1305.KeySize)
+}
+
+// keyID identifies the salt a file was written under, so a re-key
+// interrupted halfway can tell old files from new ones.
+func (info *encryptionInfo) keyID() string {
+	sum := sha256.Sum256(info.Params.Salt)
+	return hex.EncodeToString(sum[:8])
+}
+
+func (info *encryptionInfo) verify(key []byte) bool {
+	plain, err := open(key, info.Check, nil)
+	return err == nil && subtle.ConstantTimeCompare(plain, checkPlaintext) == 1
+}
+
+// seal returns nonce || ciphertext.
+func seal(key, plaintext, ad []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, ad), nil
+}
+
+func open(key, sealed, ad []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], ad)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plain, nil
+}
+
+func isEnvelope(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	if !bytes.HasPrefix(data, []byte("{")) {
+		return false
+	}
+	var probe struct {
+		Format string `json:"format"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.Format == envelopeFormat
+}
+
+func encodeEnvelope(info *encryptionInfo, key []byte, name string, plain []byte) ([]byte, error) {
+	sealed, err := seal(key, plain, []byte(name))
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := chacha20poly1305.NonceSizeX
+	return json.Marshal(envelope{
+		Format: envelopeFormat,
+		KeyID:  info.keyID(),
+		Nonce:  sealed[:nonceSize],
+		Data:   sealed[nonceSize:],
+	})
+}
+
+// envelopeKeyID returns the key ID data was sealed under, "" for plain
+// records.
+func envelopeKeyID(data []byte) string {
+	if !isEnvelope(data) {
+		return ""
+	}
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return ""
+	}
+	return env.KeyID
+}
+
+func decodeEnvelope(key []byte, name string, data []byte) ([]byte, error) {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, err
+	}
+	return open(key, append(env.Nonce, env.Data...), []byte(name))
+}
+
+// keyCodec encrypts records with key. A nil info means encryption is
+// off; a nil key means it is on but locked.
+type keyCodec struct {
+	info *encryptionInfo
+	key  []byte
+}
+
+func (c keyCodec) Encode(name string, plain []byte) ([]byte, error) {
+	if c.info == nil {
+		return plain, nil
+	}
+	if c.key == nil {
+		return nil, ErrLocked
+	}
+	return encodeEnvelope(c.info, c.key, name, plain)
+}
+
+func (c keyCodec) Decode(name string, data []byte) ([]byte, error) {
+	if !isEnvelope(data) {
+		return data, nil
+	}
+	if c.key == nil {
+		return nil, ErrLocked
+	}
+	return decodeEnvelope(c.key, name, data)
+}
+
+// liveCodec follows the store's current encryption state. Backends only
+// call it while Storage holds s.mu.
+type liveCodec struct{ s *Storage }
+
+func (c liveCodec) Encode(name string, plain []byte) ([]byte, error) {
+	return keyCodec{c.s.enc, c.s.key}.Encode(name, plain)
+}
+
+func (c liveCodec) Decode(name string, data []byte) ([]byte, error) {
+	return keyCodec{c.s.enc, c.s.key}.Decode(name, data)
+}
+
+// Committed compares the key ID of data with encryption.json as it is
+// on disk now. s.enc may be older: the other process commits a new
+// passphrase without this one knowing.
+func (c liveCodec) Committed(name string, data []byte) (bool, error) {
+	info, err := readEncryptionInfo(c.s.DataDir)
+	if err != nil {
+		return false, err
+	}
+	kid := ""
+	if info != nil {
+		kid = info.keyID()
+	}
+	return envelopeKeyID(data) == kid, nil
+}
+
+// readEncryptionInfo reads encryption.json in dir, nil if there is none.
+func readEncryptionInfo(dir string) (*encryptionInfo, error) {
+	data, err := os.ReadFile(filepath.Join(dir, EncryptionFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var info encryptionInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// loadEncryptionLocked reads encryption.json. A missing file means the
+// store is not encrypted.
+func (s *Storage) loadEncryptionLocked() error {
+	info, err := readEncryptionInfo(s.DataDir)
+	if err != nil {
+		return err
+	}
+	if info == nil {
+		s.enc = nil
+		s.key = nil
+		return nil
+	}
+	if s.enc != nil && s.key != nil && !bytes.Equal(s.enc.Params.Salt, info.Params.Salt) {
+		// Passphrase changed by the other process
+		s.key = nil
+	}
+	s.enc = info
+	return nil
+}
+
+func (s *Storage) saveEncryptionLocked(info *encryptionInfo) error {
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(s.DataDir, EncryptionFileName), data)
+}
+
+// IsEncrypted reports whether the store files are encrypted.
+func (s *Storage) IsEncrypted() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.enc != nil
+}
+
+// IsLocked reports whether the store is encrypted and no key is loaded.
+func (s *Storage) IsLocked() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lockedLocked()
+}
+
+func (s *Storage) lockedLocked() bool {
+	return s.enc != nil && s.key == nil
+}
+
+// Unlock derives the key from passphrase and loads the todos.
+func (s *Storage) Unlock(passphrase string) error {
+	s.mu.RLock()
+	info := s.enc
+	s.mu.RUnlock()
+	if info == nil {
+		return ErrNotEncrypted
+	}
+	// Argon2 is slow on purpose, don't hold the lock while deriving
+	return s.UnlockWithKey(info.deriveKey(passphrase))
+}
+
+// UnlockWithKey unlocks with a key handed over by the other process.
+func (s *Storage) UnlockWithKey(key []byte) error {
+	s.mu.Lock()
+	if s.enc == nil {
+		s.mu.Unlock()
+		return ErrNotEncrypted
+	}
+	if !s.enc.verify(key) {
+		s.mu.Unlock()
+		return ErrWrongPassphrase
+	}
+	s.key = append([]byte(nil), key...)
+	s.mu.Unlock()
+	return s.LoadTodos()
+}
+
+// Key returns a copy of the unlocked key, or nil.
+// Only meant for handing the key to the ball process over a pipe.
+func (s *Storage) Key() []byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.key == nil {
+		return nil
+	}
+	return append([]byte(nil), s.key...)
+}
+
+// EnableEncryption encrypts every list with a key derived from passphrase.
+func (s *Storage) EnableEncryption(passphrase string) error {
+	if len(passphrase) < MinPassphraseLength {
+		return ErrPassphraseTooShort
+	}
+	info, key, err := newEncryptionInfo(passphrase)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.enc != nil {
+		return ErrAlreadyEncrypted
+	}
+	return s.backend.Recode(s.allListIDsLocked(), keyCodec{}, keyCodec{info, key}, func() error {
+		if err := s.saveEncryptionLocked(info); err != nil {
+			return err
+		}
+		s.enc, s.key = info, key
+		return nil
+	})
+}
+
+// ChangePassphrase re-encrypts every list under a new passphrase.
+// See Store.Recode for how a crash half way is survived. The other
+// process must not be using the store meanwhile.
+func (s *Storage) ChangePassphrase(oldPassphrase, newPassphrase string) error {
+	if len(newPassphrase) < MinPassphraseLength {
+		return ErrPassphraseTooShort
+	}
+	s.mu.RLock()
+	oldInfo := s.enc
+	s.mu.RUnlock()
+	if oldInfo == nil {
+		return ErrNotEncrypted
+	}
+	oldKey := oldInfo.deriveKey(oldPassphrase)
+	if !oldInfo.verify(oldKey) {
+		return ErrWrongPassphrase
+	}
+	newInfo, newKey, err := newEncryptionInfo(newPassphrase)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.key = oldKey
+	return s.backend.Recode(s.allListIDsLocked(), keyCodec{oldInfo, oldKey}, keyCodec{newInfo, newKey}, func() error {
+		if err := s.saveEncryptionLocked(newInfo); err != nil {
+			return err
+		}
+		s.enc, s.key = newInfo, newKey
+		return nil
+	})
+}
+
+// DisableEncryption stores every list as plain JSON again.
+func (s *Storage) DisableEncryption(passphrase string) error {
+	s.mu.RLock()
+	info := s.enc
+	s.mu.RUnlock()
+	if info == nil {
+		return ErrNotEncrypted
+	}
+	key := info.deriveKey(passphrase)
+	if !info.verify(key) {
+		return ErrWrongPassphrase
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.key = key
+	// Plain records are readable in either state, so encryption.json
+	// is removed only after every record is rewritten
+	err := s.backend.Recode(s.allListIDsLocked(), keyCodec{info, key}, keyCodec{}, func() error { return nil })
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(s.DataDir, EncryptionFileName)); err != nil {
+		return err
+	}
+	s.enc, s.key = nil, nil
+	return nil
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const (
+	// PortableMarker next to the executable keeps all data beside it
+	PortableMarker = "portable.txt"
+
+	dataDirName = "TodoBall"
+)
+
+// ExeDir returns the directory containing the running executable.
+func ExeDir() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(exe), nil
+}
+
+// ResolveDataDir picks where todos, config and logs are stored:
+// the override (from --data-dir) if given, the exe directory in portable
+// mode, otherwise the per-user data directory.
+func ResolveDataDir(override string) (dir string, portable bool, err error) {
+	if override != "" {
+		dir, err = filepath.Abs(override)
+		return dir, false, err
+	}
+
+	exeDir, err := ExeDir()
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := os.Stat(filepath.Join(exeDir, PortableMarker)); err == nil {
+		return exeDir, true, nil
+	}
+
+	dir, err = userDataDir()
+	return dir, false, err
+}
+
+// userDataDir returns %APPDATA%\TodoBall on Windows,
+// ~/Library/Application Support/TodoBall on macOS and
+// $XDG_DATA_HOME/TodoBall (default ~/.local/share) elsewhere.
+func userDataDir() (string, error) {
+	switch runtime.GOOS {
+	case "windows", "darwin":
+		base, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(base, dataDirName), nil
+	}
+
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" && filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, dataDirName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", dataDirName), nil
+}
+
+// migrateLegacyFiles copies todos.json and config.json from next to the
+// executable (where older versions kept them) into dir. Files already in
+// dir are never overwritten, and the originals are left in place so a
+// downgrade still finds its data.
+func migrateLegacyFiles(dir string) {
+	exeDir, err := ExeDir()
+	if err != nil || sameDir(exeDir, dir) {
+		return
+	}
+	for _, name := range []string{DataFileName, ConfigFileName} {
+		src := filepath.Join(exeDir, name)
+		dst := filepath.Join(dir, name)
+		if _, err := os.Stat(dst); err == nil {
+			continue
+		}
+		if _, err := os.Stat(src); err != nil {
+			continue
+		}
+		if err := copyFile(src, dst); err != nil {
+			slog.Error("migrate legacy data file failed", "from", src, "to", dst, "err", err)
+			continue
+		}
+		slog.Info("migrated legacy data file", "from", src, "to", dst)
+	}
+}
+
+func sameDir(a, b string) bool {
+	ia, errA := os.Stat(a)
+	ib, errB := os.Stat(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return os.SameFile(ia, ib)
+}
+
+// copyFile writes to a temp file first so a crash never leaves a partial dst.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+package storage
+
+// NewKeyCodec returns the codec of a store encrypted with passphrase.
+func NewKeyCodec(passphrase string) (Codec, error) {
+	info, key, err := newEncryptionInfo(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return keyCodec{info, key}, nil
+}
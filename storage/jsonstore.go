@@ -0,0 +1,249 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+	"todo-ball/models"
+)
+
+const pendingSuffix = ".rekey"
+
+// jsonStore keeps each list as one JSON array file, the original format.
+// Every change rewrites the whole file.
+type jsonStore struct {
+	dir   string
+	codec Codec
+}
+
+// NewJSONStore stores lists as todos.json / todos-<id>.json in dir.
+func NewJSONStore(dir string, codec Codec) Store {
+	return &jsonStore{dir: dir, codec: codec}
+}
+
+func (j *jsonStore) path(list string) string {
+	return filepath.Join(j.dir, ListFileName(list))
+}
+
+func (j *jsonStore) Todos(list string) ([]models.TodoItem, error) {
+	return j.read(list, j.codec)
+}
+
+func (j *jsonStore) read(list string, codec Codec) ([]models.TodoItem, error) {
+	if err := j.finishRecode(list); err != nil {
+		return nil, err
+	}
+	path := j.path(list)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return []models.TodoItem{}, nil
+	}
+
+	data, err := readWithRetry(path)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := codec.Decode(filepath.Base(path), data)
+	if err != nil {
+		return nil, err
+	}
+
+	todos := []models.TodoItem{}
+	if err := json.Unmarshal(plain, &todos); err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+func readWithRetry(path string) ([]byte, error) {
+	var data []byte
+	var err error
+
+	// Retry loop for file locking
+	for i := 0; i < 5; i++ {
+		data, err = os.ReadFile(path)
+		if err == nil {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return data, err
+}
+
+func (j *jsonStore) write(list string, todos []models.TodoItem) error {
+	data, err := json.MarshalIndent(todos, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := j.path(list)
+	data, err = j.codec.Encode(filepath.Base(path), data)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (j *jsonStore) Add(list string, item models.TodoItem) error {
+	todos, err := j.Todos(list)
+	if err != nil {
+		return err
+	}
+	for _, t := range todos {
+		if t.ID == item.ID {
+			return ErrDuplicateID
+		}
+	}
+	return j.write(list, append(todos, item))
+}
+
+func (j *jsonStore) Update(list string, item models.TodoItem) error {
+	todos, err := j.Todos(list)
+	if err != nil {
+		return err
+	}
+	for i, t := range todos {
+		if t.ID == item.ID {
+			todos[i] = item
+			return j.write(list, todos)
+		}
+	}
+	return ErrNotFound
+}
+
+func (j *jsonStore) Delete(list string, id string) error {
+	todos, err := j.Todos(list)
+	if err != nil {
+		return err
+	}
+	for i, t := range todos {
+		if t.ID == id {
+			return j.write(list, append(todos[:i:i], todos[i+1:]...))
+		}
+	}
+	return ErrNotFound
+}
+
+func (j *jsonStore) Replace(list string, todos []models.TodoItem) error {
+	if todos == nil {
+		todos = []models.TodoItem{}
+	}
+	return j.write(list, todos)
+}
+
+func (j *jsonStore) DueBetween(list string, from, to time.Time) ([]models.TodoItem, error) {
+	todos, err := j.Todos(list)
+	if err != nil {
+		return nil, err
+	}
+	var due []models.TodoItem
+	for _, t := range todos {
+		if !t.DueDate.Before(from) && t.DueDate.Before(to) {
+			due = append(due, t)
+		}
+	}
+	sortByDue(due)
+	return due, nil
+}
+
+func (j *jsonStore) ByCompletion(list string, completed bool) ([]models.TodoItem, error) {
+	todos, err := j.Todos(list)
+	if err != nil {
+		return nil, err
+	}
+	var matched []models.TodoItem
+	for _, t := range todos {
+		if t.Completed == completed {
+			matched = append(matched, t)
+		}
+	}
+	return matched, nil
+}
+
+func (j *jsonStore) DropList(list string) error {
+	if err := os.Remove(j.path(list)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// finishRecode renames the copy written by a Recode over the list file
+// once it is committed, in case that Recode crashed before renaming it
+// or runs in the other process. A copy that isn't committed is kept:
+// only Recode itself replaces it.
+func (j *jsonStore) finishRecode(list string) error {
+	path := j.path(list)
+	pending, err := os.ReadFile(path + pendingSuffix)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	done, err := recodeCommitted(j.codec, filepath.Base(path), pending)
+	if err != nil || !done {
+		return err
+	}
+	// The other process may have renamed it first
+	if err := os.Rename(path+pendingSuffix, path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Recode writes the re-encoded files next to the originals, commits,
+// then renames them over. read finishes a rename cut short by a crash.
+func (j *jsonStore) Recode(lists []string, from, to Codec, commit func() error) error {
+	for _, list := range lists {
+		if err := j.finishRecode(list); err != nil {
+			return err
+		}
+	}
+	var written []string
+	for _, list := range lists {
+		path := j.path(list)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		data, err := readWithRetry(path)
+		if err != nil {
+			return err
+		}
+		name := filepath.Base(path)
+		plain, err := from.Decode(name, data)
+		if err != nil {
+			return err
+		}
+		sealed, err := to.Encode(name, plain)
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(path+pendingSuffix, sealed); err != nil {
+			return err
+		}
+		written = append(written, path)
+	}
+
+	if err := commit(); err != nil {
+		for _, path := range written {
+			os.Remove(path + pendingSuffix)
+		}
+		return err
+	}
+
+	for _, path := range written {
+		if err := os.Rename(path+pendingSuffix, path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *jsonStore) Close() error { return nil }
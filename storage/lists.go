@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"todo-ball/models"
+)
+
+const ListsFileName = "lists.json"
+
+var (
+	ErrListNotFound  = errors.New("list not found")
+	ErrDuplicateList = errors.New("list name already exists")
+	ErrDeleteDefault = errors.New("the default list cannot be deleted")
+)
+
+func defaultList() models.TodoList {
+	return models.TodoList{
+		ID:   models.DefaultListID,
+		Name: "默认",
+		File: ListFileName(models.DefaultListID),
+	}
+}
+
+// LoadLists reads the list registry. Without lists.json only the
+// default list exists, backed by the original todos.json.
+func (s *Storage) LoadLists() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Lists = []models.TodoList{defaultList()}
+
+	path := filepath.Join(s.DataDir, ListsFileName)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var lists []models.TodoList
+	if err := json.Unmarshal(data, &lists); err != nil {
+		return err
+	}
+	for _, l := range lists {
+		// The JSON backend derives file names from the ID, don't
+		// trust a hand-edited one
+		l.File = ListFileName(l.ID)
+		if l.ID == models.DefaultListID {
+			// Keep the user's name/colour
+			s.Lists[0] = l
+			continue
+		}
+		s.Lists = append(s.Lists, l)
+	}
+	return nil
+}
+
+func (s *Storage) saveListsLocked() error {
+	data, err := json.MarshalIndent(s.Lists, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(s.DataDir, ListsFileName), data)
+}
+
+func (s *Storage) findListLocked(id string) int {
+	for i, l := range s.Lists {
+		if l.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// GetLists returns a copy of the list registry.
+func (s *Storage) GetLists() []models.TodoList {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	lists := make([]models.TodoList, len(s.Lists))
+	copy(lists, s.Lists)
+	return lists
+}
+
+// CreateList registers a new, empty list.
+func (s *Storage) CreateList(name, color string) (models.TodoList, error) {
+	name = strings.TrimSpace(name)
+	id := fmt.Sprintf("%d", time.Now().UnixNano())
+	list := models.TodoList{
+		ID:        id,
+		Name:      name,
+		File:      ListFileName(id),
+		Color:     color,
+		CreatedAt: time.Now(),
+	}
+	if err := models.ValidateList(list); err != nil {
+		return models.TodoList{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, l := range s.Lists {
+		if strings.EqualFold(l.Name, name) {
+			return models.TodoList{}, ErrDuplicateList
+		}
+	}
+	s.Lists = append(s.Lists, list)
+	if err := s.saveListsLocked(); err != nil {
+		s.Lists = s.Lists[:len(s.Lists)-1]
+		return models.TodoList{}, err
+	}
+	return list, nil
+}
+
+// SwitchList makes id the active list, saves the config and loads its todos.
+func (s *Storage) SwitchList(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.findListLocked(id) < 0 {
+		return ErrListNotFound
+	}
+	if s.lockedLocked() {
+		return ErrLocked
+	}
+	todos, err := s.backend.Todos(id)
+	if err != nil {
+		return err
+	}
+	prev := s.config.ActiveList
+	s.config.ActiveList = id
+	if err := s.saveConfigLocked(); err != nil {
+		s.config.ActiveList = prev
+		return err
+	}
+	s.Todos = todos
+	return nil
+}
+
+// DeleteList removes a list and its todos. Deleting the active list
+// switches back to the default one.
+func (s *Storage) DeleteList(id string) error {
+	if id == models.DefaultListID {
+		return ErrDeleteDefault
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.findListLocked(id)
+	if i < 0 {
+		return ErrListNotFound
+	}
+	if s.config.ActiveList == id {
+		todos, err := s.backend.Todos(models.DefaultListID)
+		if err != nil {
+			return err
+		}
+		s.config.ActiveList = models.DefaultListID
+		if err := s.saveConfigLocked(); err != nil {
+			s.config.ActiveList = id
+			return err
+		}
+		s.Todos = todos
+	}
+
+	// The three-index slice makes append copy, leaving prev intact
+	prev := s.Lists
+	s.Lists = append(s.Lists[:i:i], s.Lists[i+1:]...)
+	if err := s.saveListsLocked(); err != nil {
+		s.Lists = prev
+		return err
+	}
+	if err := s.backend.DropList(ArchiveListID(id)); err != nil {
+		return err
+	}
+	return s.backend.DropList(id)
+}
+
+// GetListTodos reads the todos of any list without switching to it.
+func (s *Storage) GetListTodos(id string) ([]models.TodoItem, error) {
+	s.mu.RLock()
+	if id == s.config.ActiveList {
+		todos := make([]models.TodoItem, len(s.Todos))
+		copy(todos, s.Todos)
+		s.mu.RUnlock()
+		return todos, nil
+	}
+	if s.findListLocked(id) < 0 {
+		s.mu.RUnlock()
+		return nil, ErrListNotFound
+	}
+	defer s.mu.RUnlock()
+	if s.lockedLocked() {
+		return nil, ErrLocked
+	}
+	return s.backend.Todos(id)
+}
+
+// AllTodos returns the todos of every list and every archive.
+func (s *Storage) AllTodos() ([]models.TodoItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.lockedLocked() {
+		return nil, ErrLocked
+	}
+	var all []models.TodoItem
+	for _, id := range s.allListIDsLocked() {
+		todos, err := s.backend.Todos(id)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, todos...)
+	}
+	return all, nil
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// MigratedSuffix is appended to the files a migration replaced. They are
+// kept so a migration can be undone by hand.
+const MigratedSuffix = ".migrated"
+
+// MigrateTo copies every list into the backend kind and switches to it.
+// The app must not be running: the other process would keep using the
+// old backend.
+func (s *Storage) MigrateTo(kind string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lockedLocked() {
+		return ErrLocked
+	}
+	if kind == DetectBackend(s.DataDir) {
+		return fmt.Errorf("data is already stored in the %s backend", kind)
+	}
+
+	var target Store
+	boltPath := filepath.Join(s.DataDir, BoltFileName)
+	// Build the bolt file under another name, DetectBackend would pick
+	// up a half-written one
+	tmpBolt := boltPath + ".tmp"
+	switch kind {
+	case BackendJSON:
+		target = NewJSONStore(s.DataDir, liveCodec{s})
+	case BackendBolt:
+		os.Remove(tmpBolt)
+		var err error
+		if target, err = NewBoltStore(tmpBolt, liveCodec{s}); err != nil {
+			return err
+		}
+		// Leaves nothing behind on failure, no-op once renamed
+		defer os.Remove(tmpBolt)
+	default:
+		return fmt.Errorf("unknown storage backend %q", kind)
+	}
+
+	ids := s.allListIDsLocked()
+	for _, id := range ids {
+		todos, err := s.backend.Todos(id)
+		if err != nil {
+			return fmt.Errorf("read list %s: %w", id, err)
+		}
+		if err := target.Replace(id, todos); err != nil {
+			return fmt.Errorf("write list %s: %w", id, err)
+		}
+		copied, err := target.Todos(id)
+		if err != nil {
+			return fmt.Errorf("verify list %s: %w", id, err)
+		}
+		if len(copied) != len(todos) {
+			return fmt.Errorf("verify list %s: copied %d of %d todos", id, len(copied), len(todos))
+		}
+	}
+
+	// Switch over, then move the old data aside
+	if kind == BackendBolt {
+		if err := os.Rename(tmpBolt, boltPath); err != nil {
+			return err
+		}
+		target.(*boltStore).path = boltPath
+		for _, id := range ids {
+			path := filepath.Join(s.DataDir, ListFileName(id))
+			if err := os.Rename(path, path+MigratedSuffix); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	} else {
+		if err := os.Rename(boltPath, boltPath+MigratedSuffix); err != nil {
+			return err
+		}
+	}
+	s.backend.Close()
+	s.backend = target
+	return nil
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+	"todo-ball/models"
+)
+
+// TestRecodeWithOtherProcess changes the passphrase in one Storage while
+// a second one on the same directory, like the ball, still holds the
+// old key and reads in the middle.
+func TestRecodeWithOtherProcess(t *testing.T) {
+	for _, kind := range []string{BackendJSON, BackendBolt} {
+		t.Run(kind, func(t *testing.T) {
+			dir := t.TempDir()
+			if kind == BackendBolt {
+				if _, err := NewBoltStore(filepath.Join(dir, BoltFileName), PlainCodec{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			main, err := NewStorage(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := main.EnableEncryption("old passphrase"); err != nil {
+				t.Fatal(err)
+			}
+			item := models.TodoItem{ID: "1", Title: "keep me", DueDate: time.Date(2026, 3, 4, 17, 0, 0, 0, time.UTC)}
+			if err := main.AddTodo(item); err != nil {
+				t.Fatal(err)
+			}
+			ball, err := NewStorage(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ball.Unlock("old passphrase"); err != nil {
+				t.Fatal(err)
+			}
+
+			newInfo, newKey, err := newEncryptionInfo("new passphrase")
+			if err != nil {
+				t.Fatal(err)
+			}
+			main.mu.Lock()
+			from := keyCodec{main.enc, main.key}
+			err = main.backend.Recode(main.allListIDsLocked(), from, keyCodec{newInfo, newKey}, func() error {
+				// Staged but not committed: the old records are current
+				if err := ball.LoadTodos(); err != nil || len(ball.Todos) != 1 {
+					t.Errorf("before commit the other process read %v, %v", ball.Todos, err)
+				}
+				if err := main.saveEncryptionLocked(newInfo); err != nil {
+					return err
+				}
+				main.enc, main.key = newInfo, newKey
+				// Committed but not moved: the other process, still on
+				// the old key, may finish the move but must not undo it
+				ball.mu.Lock()
+				_, err := ball.backend.Todos(ball.config.ActiveList)
+				ball.mu.Unlock()
+				if !errors.Is(err, ErrWrongPassphrase) {
+					t.Errorf("after commit the old key read the list: %v", err)
+				}
+				return nil
+			})
+			main.mu.Unlock()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := main.LoadTodos(); err != nil || len(main.Todos) != 1 || main.Todos[0].Title != item.Title {
+				t.Errorf("after the change main read %v, %v", main.Todos, err)
+			}
+			if err := ball.LoadTodos(); !errors.Is(err, ErrLocked) {
+				t.Errorf("the old key still loads after the change: %v", err)
+			}
+			if err := ball.Unlock("new passphrase"); err != nil || len(ball.Todos) != 1 {
+				t.Errorf("unlocking with the new passphrase read %v, %v", ball.Todos, err)
+			}
+		})
+	}
+}
+
+// TestRecodeLeftOver checks that copies staged by a Recode that never
+// committed are neither used nor in the way of the next one.
+func TestRecodeLeftOver(t *testing.T) {
+	for _, kind := range []string{BackendJSON, BackendBolt} {
+		t.Run(kind, func(t *testing.T) {
+			dir := t.TempDir()
+			if kind == BackendBolt {
+				if _, err := NewBoltStore(filepath.Join(dir, BoltFileName), PlainCodec{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			s, err := NewStorage(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			item := models.TodoItem{ID: "1", Title: "keep me", DueDate: time.Date(2026, 3, 4, 17, 0, 0, 0, time.UTC)}
+			if err := s.AddTodo(item); err != nil {
+				t.Fatal(err)
+			}
+			info, key, err := newEncryptionInfo("never committed")
+			if err != nil {
+				t.Fatal(err)
+			}
+			failed := errors.New("crash")
+			s.mu.Lock()
+			err = s.backend.Recode(s.allListIDsLocked(), keyCodec{}, keyCodec{info, key}, func() error { return failed })
+			s.mu.Unlock()
+			if !errors.Is(err, failed) {
+				t.Fatalf("Recode returned %v, want the commit error", err)
+			}
+
+			if err := s.LoadTodos(); err != nil || len(s.Todos) != 1 {
+				t.Errorf("after a failed Recode read %v, %v", s.Todos, err)
+			}
+			if err := s.EnableEncryption("real passphrase"); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.LoadTodos(); err != nil || len(s.Todos) != 1 || s.Todos[0].Title != item.Title {
+				t.Errorf("after enabling read %v, %v", s.Todos, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package storage_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"todo-ball/storage"
+)
+
+// failSaves makes the next atomic write of name fail, by putting a
+// directory where its temporary file goes.
+func failSaves(t *testing.T, s *storage.Storage, name string) {
+	t.Helper()
+	if err := os.Mkdir(filepath.Join(s.DataDir, name+".tmp"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newStorage(t *testing.T) *storage.Storage {
+	t.Helper()
+	s, err := storage.NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestUpdateConfigRollback(t *testing.T) {
+	s := newStorage(t)
+	before := s.Config()
+	cfg := before
+	cfg.NotificationDays = before.NotificationDays + 1
+	failSaves(t, s, storage.ConfigFileName)
+	if err := s.UpdateConfig(cfg); err == nil {
+		t.Fatal("UpdateConfig saved over a blocked file")
+	}
+	if got := s.Config().NotificationDays; got != before.NotificationDays {
+		t.Errorf("config kept the unsaved value %d, want %d", got, before.NotificationDays)
+	}
+}
+
+func TestApplyThemeRollback(t *testing.T) {
+	s := newStorage(t)
+	before := s.Config()
+	failSaves(t, s, storage.ConfigFileName)
+	cfg, err := s.ApplyTheme("海洋")
+	if err == nil {
+		t.Fatal("ApplyTheme saved over a blocked file")
+	}
+	if cfg.ThemeColor != before.ThemeColor || s.Config().ThemeColor != before.ThemeColor {
+		t.Errorf("theme colour returned %s, kept %s, want %s", cfg.ThemeColor, s.Config().ThemeColor, before.ThemeColor)
+	}
+}
+
+func TestDeleteListRollback(t *testing.T) {
+	s := newStorage(t)
+	list, err := s.CreateList("Work", "#3498db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	failSaves(t, s, storage.ListsFileName)
+	if err := s.DeleteList(list.ID); err == nil {
+		t.Fatal("DeleteList saved over a blocked file")
+	}
+	if lists := s.GetLists(); len(lists) != 2 || lists[1].ID != list.ID {
+		t.Errorf("lists after a failed delete = %+v, want the list kept", s.GetLists())
+	}
+}
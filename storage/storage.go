@@ -2,9 +2,13 @@ package storage
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 	"todo-ball/models"
 )
 
@@ -13,82 +17,105 @@ const (
 	ConfigFileName = "config.json"
 )
 
+var (
+	ErrNotFound    = errors.New("todo not found")
+	ErrDuplicateID = errors.New("todo id already exists")
+)
+
 type Storage struct {
 	mu     sync.RWMutex
 	Todos  []models.TodoItem
-	Config models.AppConfig
-	AppDir string
+	config models.AppConfig // Read through Config
+	Lists  []models.TodoList
+	// DataDir holds todos.json and config.json, see ResolveDataDir
+	DataDir string
+
+	// Persists the todos, see DetectBackend
+	backend Store
+
+	// Set when encryption.json exists, see crypto.go
+	enc *encryptionInfo
+	key []byte
 }
 
-func NewStorage() (*Storage, error) {
-	// Get executable dir
-	exe, err := os.Executable()
-	if err != nil {
+// NewStorage opens the store in dir, creating it if needed and
+// migrating data files left next to the executable by older versions.
+func NewStorage(dir string) (*Storage, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, err
 	}
-	dir := filepath.Dir(exe)
+	migrateLegacyFiles(dir)
 
 	s := &Storage{
-		AppDir: dir,
-		Config: models.DefaultConfig(),
-		Todos:  []models.TodoItem{},
+		DataDir: dir,
+		config:  models.DefaultConfig(),
+		Todos:   []models.TodoItem{},
 	}
 
+	if err := s.loadEncryptionLocked(); err != nil {
+		return nil, err
+	}
+	backend, err := OpenStore(DetectBackend(dir), dir, liveCodec{s})
+	if err != nil {
+		return nil, err
+	}
+	s.backend = backend
+	s.LoadLists()
 	s.LoadConfig()
 	s.LoadTodos()
 
 	return s, nil
 }
 
+// LoadTodos reloads the active list. While the store is locked the
+// list stays empty and ErrLocked is returned.
 func (s *Storage) LoadTodos() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	path := filepath.Join(s.AppDir, DataFileName)
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return nil
+	// The other process may have enabled encryption or changed the passphrase
+	if err := s.loadEncryptionLocked(); err != nil {
+		return err
 	}
-
-	var data []byte
-	var err error
-
-	// Retry loop for file locking
-	for i := 0; i < 5; i++ {
-		data, err = os.ReadFile(path)
-		if err == nil {
-			break
-		}
-		time.Sleep(50 * time.Millisecond)
+	if s.lockedLocked() {
+		s.Todos = []models.TodoItem{}
+		return ErrLocked
 	}
 
+	todos, err := s.backend.Todos(s.config.ActiveList)
 	if err != nil {
 		return err
 	}
-
-	return json.Unmarshal(data, &s.Todos)
+	s.Todos = todos
+	return nil
 }
 
-func (s *Storage) SaveTodos() error {
+// Backend returns the name of the backend in use, see OpenStore.
+func (s *Storage) Backend() string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.saveTodosLocked()
-}
-
-func (s *Storage) saveTodosLocked() error {
-	data, err := json.MarshalIndent(s.Todos, "", "  ")
-	if err != nil {
-		return err
+	switch s.backend.(type) {
+	case *boltStore:
+		return BackendBolt
 	}
+	return BackendJSON
+}
 
-	path := filepath.Join(s.AppDir, DataFileName)
-	return os.WriteFile(path, data, 0644)
+// Close releases the backend.
+func (s *Storage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.backend.Close()
 }
 
 func (s *Storage) LoadConfig() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.loadConfigLocked()
+}
 
-	path := filepath.Join(s.AppDir, ConfigFileName)
+func (s *Storage) loadConfigLocked() error {
+	path := filepath.Join(s.DataDir, ConfigFileName)
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return nil
 	}
@@ -98,7 +125,40 @@ func (s *Storage) LoadConfig() error {
 		return err
 	}
 
-	return json.Unmarshal(data, &s.Config)
+	cfg := models.DefaultConfig()
+	// Left empty to tell configs from before ball_content existed
+	cfg.BallContent = ""
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return err
+	}
+	if cfg.BallContent == "" {
+		cfg.BallContent = models.BallContentFor(cfg.FloatingBallMode)
+	}
+	// Hand-edited or outdated files may hold values the UI can't handle
+	var repaired models.ValidationErrors
+	s.config, repaired = models.RepairConfig(cfg)
+	if len(repaired) > 0 {
+		slog.Warn("repaired invalid config values", "path", path, "fields", repaired.Error())
+	}
+	if s.findListLocked(s.config.ActiveList) < 0 {
+		slog.Warn("active list not found, using default", "list", s.config.ActiveList)
+		s.config.ActiveList = models.DefaultListID
+	}
+	return nil
+}
+
+// Config returns a copy of the config as last loaded or saved. Safe to
+// call from any goroutine.
+func (s *Storage) Config() models.AppConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cfg := s.config
+	cfg.QuietHours = make([]models.QuietPeriod, len(s.config.QuietHours))
+	for i, p := range s.config.QuietHours {
+		p.Days = append([]int(nil), p.Days...)
+		cfg.QuietHours[i] = p
+	}
+	return cfg
 }
 
 func (s *Storage) SaveConfig() error {
@@ -108,57 +168,90 @@ func (s *Storage) SaveConfig() error {
 }
 
 func (s *Storage) saveConfigLocked() error {
-	data, err := json.MarshalIndent(s.Config, "", "  ")
+	data, err := json.MarshalIndent(s.config, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	path := filepath.Join(s.AppDir, ConfigFileName)
-	return os.WriteFile(path, data, 0644)
+	path := filepath.Join(s.DataDir, ConfigFileName)
+	return writeFileAtomic(path, data)
 }
 
 func (s *Storage) AddTodo(item models.TodoItem) error {
+	if err := models.ValidateTodo(item); err != nil {
+		return err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if s.lockedLocked() {
+		return ErrLocked
+	}
+	if err := s.backend.Add(s.config.ActiveList, item); err != nil {
+		return err
+	}
 	s.Todos = append(s.Todos, item)
-	return s.saveTodosLocked()
+	return nil
 }
 
 func (s *Storage) UpdateTodo(item models.TodoItem) error {
+	if err := models.ValidateTodo(item); err != nil {
+		return err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if s.lockedLocked() {
+		return ErrLocked
+	}
+	if err := s.backend.Update(s.config.ActiveList, item); err != nil {
+		return err
+	}
 	for i, t := range s.Todos {
 		if t.ID == item.ID {
 			s.Todos[i] = item
-			break
 		}
 	}
-	return s.saveTodosLocked()
+	return nil
 }
 
 // ToggleTodo toggles the completed status of a todo item
 func (s *Storage) ToggleTodo(id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if s.lockedLocked() {
+		return ErrLocked
+	}
 
 	for i, t := range s.Todos {
 		if t.ID == id {
-			s.Todos[i].Completed = !s.Todos[i].Completed
-			s.Todos[i].CompletedAt = nil
-			if s.Todos[i].Completed {
-				now := time.Now()
-				s.Todos[i].CompletedAt = &now
+			now := time.Now()
+			t.Completed = !t.Completed
+			t.CompletedAt = nil
+			event := models.TodoEvent{Type: models.EventUncompleted, At: now}
+			if t.Completed {
+				t.CompletedAt = &now
+				event.Type = models.EventCompleted
+			}
+			// CompletedAt only describes the current state, the history keeps every change
+			t.History = append(append([]models.TodoEvent(nil), t.History...), event)
+			if err := s.backend.Update(s.config.ActiveList, t); err != nil {
+				return err
 			}
-			break
+			s.Todos[i] = t
+			return nil
 		}
 	}
-	// We should save after modification
-	return s.saveTodosLocked()
+	return ErrNotFound
 }
 
 func (s *Storage) DeleteTodo(id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if s.lockedLocked() {
+		return ErrLocked
+	}
+	if err := s.backend.Delete(s.config.ActiveList, id); err != nil {
+		return err
+	}
 	newTodos := []models.TodoItem{}
 	for _, t := range s.Todos {
 		if t.ID != id {
@@ -166,7 +259,7 @@ func (s *Storage) DeleteTodo(id string) error {
 		}
 	}
 	s.Todos = newTodos
-	return s.saveTodosLocked()
+	return nil
 }
 
 func (s *Storage) GetTodos() []models.TodoItem {
@@ -178,9 +271,78 @@ func (s *Storage) GetTodos() []models.TodoItem {
 	return todos
 }
 
+// DueBetween returns todos of the active list due in [from, to), earliest first.
+func (s *Storage) DueBetween(from, to time.Time) ([]models.TodoItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.lockedLocked() {
+		return nil, ErrLocked
+	}
+	return s.backend.DueBetween(s.config.ActiveList, from, to)
+}
+
+// Pending returns the todos of the active list that are not completed.
+func (s *Storage) Pending() ([]models.TodoItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.lockedLocked() {
+		return nil, ErrLocked
+	}
+	return s.backend.ByCompletion(s.config.ActiveList, false)
+}
+
+// Query returns the todos of the active list that match filter at now,
+// see models.FilterTodos.
+func (s *Storage) Query(filter string, now time.Time) ([]models.TodoItem, error) {
+	if !models.ValidFilter(filter) {
+		return nil, fmt.Errorf("unknown todo filter %q", filter)
+	}
+	if filter == "" || filter == models.FilterAll {
+		return s.GetTodos(), nil
+	}
+	// Both other filters only match pending todos
+	pending, err := s.Pending()
+	if err != nil {
+		return nil, err
+	}
+	return models.FilterTodos(pending, filter, now), nil
+}
+
+// Available returns the pending todos of the active list that have
+// started: what the ball counts and reminds of.
+func (s *Storage) Available(now time.Time) ([]models.TodoItem, error) {
+	return s.Query(models.FilterAvailable, now)
+}
+
 func (s *Storage) UpdateConfig(cfg models.AppConfig) error {
+	if err := models.ValidateConfig(cfg); err != nil {
+		return err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.Config = cfg
-	return s.saveConfigLocked()
+	// The active list only changes through SwitchList, so a settings page
+	// opened before a switch can't flip it back
+	cfg.ActiveList = s.config.ActiveList
+	// Same for click-through, toggled by hotkey while settings may be open
+	cfg.ClickThrough = s.config.ClickThrough
+	prev := s.config
+	s.config = cfg
+	if err := s.saveConfigLocked(); err != nil {
+		s.config = prev
+		return err
+	}
+	return nil
+}
+
+// SetClickThrough turns click-through of the ball on or off.
+func (s *Storage) SetClickThrough(on bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev := s.config.ClickThrough
+	s.config.ClickThrough = on
+	if err := s.saveConfigLocked(); err != nil {
+		s.config.ClickThrough = prev
+		return err
+	}
+	return nil
 }
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+	"todo-ball/models"
+)
+
+// Backend names accepted by OpenStore and the migrate command.
+const (
+	BackendJSON = "json"
+	BackendBolt = "bolt"
+)
+
+// Store persists the todos of every list. Storage keeps the active list
+// cached in memory and calls a Store for every change, so backends only
+// need to be correct, not fast to read in bulk.
+//
+// Missing lists behave as empty. Every backend must pass
+// storetest.TestStore.
+type Store interface {
+	// Todos returns every todo in list.
+	Todos(list string) ([]models.TodoItem, error)
+	// Add inserts item. Returns ErrDuplicateID if the ID is taken.
+	Add(list string, item models.TodoItem) error
+	// Update replaces the todo with item.ID. Returns ErrNotFound if missing.
+	Update(list string, item models.TodoItem) error
+	// Delete removes the todo with id. Returns ErrNotFound if missing.
+	Delete(list string, id string) error
+	// Replace swaps the whole content of list in one step.
+	Replace(list string, todos []models.TodoItem) error
+
+	// DueBetween returns todos due in [from, to), earliest first.
+	DueBetween(list string, from, to time.Time) ([]models.TodoItem, error)
+	// ByCompletion returns the completed or the pending todos of list.
+	ByCompletion(list string, completed bool) ([]models.TodoItem, error)
+
+	// DropList deletes list and all of its todos.
+	DropList(list string) error
+
+	// Recode re-encodes every record of lists from one codec to another,
+	// calling commit once the new copies are safely written. A crash at
+	// any point must leave the data readable with either codec, whichever
+	// commit had persisted. Readers that find copies staged by an
+	// unfinished Recode move them into place only once committed, see
+	// recodeCommitted; the caller still keeps the other process out.
+	Recode(lists []string, from, to Codec, commit func() error) error
+
+	Close() error
+}
+
+// Codec turns records into the bytes a Store writes, and back.
+// name identifies the record (a file name or list/ID) and must be the
+// same when decoding, see keyCodec.
+type Codec interface {
+	Encode(name string, plain []byte) ([]byte, error)
+	Decode(name string, data []byte) ([]byte, error)
+}
+
+// commitChecker is implemented by codecs that know which encoding is
+// committed even when they hold an older one, as in a process that
+// hasn't seen the other one change the passphrase yet.
+type commitChecker interface {
+	// Committed reports whether data is encoded the committed way.
+	Committed(name string, data []byte) (bool, error)
+}
+
+// recodeCommitted reports whether staged, a record re-encoded by an
+// unfinished Recode, is on the committed side, so it must replace the
+// original. If not, the Recode failed before commit or is still running
+// in the other process, and the staged copy must be left alone. Codecs
+// that can't tell are asked to decode it.
+func recodeCommitted(codec Codec, name string, staged []byte) (bool, error) {
+	if c, ok := codec.(commitChecker); ok {
+		return c.Committed(name, staged)
+	}
+	_, err := codec.Decode(name, staged)
+	return err == nil, nil
+}
+
+// PlainCodec stores records unchanged.
+type PlainCodec struct{}
+
+func (PlainCodec) Encode(name string, plain []byte) ([]byte, error) { return plain, nil }
+func (PlainCodec) Decode(name string, data []byte) ([]byte, error)  { return data, nil }
+
+// OpenStore opens the backend kind in dir.
+func OpenStore(kind, dir string, codec Codec) (Store, error) {
+	switch kind {
+	case BackendJSON:
+		return NewJSONStore(dir, codec), nil
+	case BackendBolt:
+		return NewBoltStore(filepath.Join(dir, BoltFileName), codec)
+	}
+	return nil, fmt.Errorf("unknown storage backend %q", kind)
+}
+
+// DetectBackend reports which backend holds the data in dir.
+// JSON is the default for new and existing installs.
+func DetectBackend(dir string) string {
+	if _, err := os.Stat(filepath.Join(dir, BoltFileName)); err == nil {
+		return BackendBolt
+	}
+	return BackendJSON
+}
+
+// ListFileName is the JSON file backing a list.
+func ListFileName(id string) string {
+	if id == models.DefaultListID {
+		return DataFileName
+	}
+	return "todos-" + id + ".json"
+}
+
+// sortByDue orders todos by due date, then ID, like the bolt index.
+func sortByDue(todos []models.TodoItem) {
+	sort.SliceStable(todos, func(i, j int) bool {
+		if !todos[i].DueDate.Equal(todos[j].DueDate) {
+			return todos[i].DueDate.Before(todos[j].DueDate)
+		}
+		return todos[i].ID < todos[j].ID
+	})
+}
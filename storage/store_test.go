@@ -0,0 +1,56 @@
+package storage_test
+
+import (
+	"testing"
+	"todo-ball/storage"
+	"todo-ball/storage/storetest"
+)
+
+// layered encodes records with the codec of the store, then with the one
+// storetest hands out, so its Recode checks see their own encoding
+// outermost and the records inside stay encrypted.
+type layered struct {
+	store, test storage.Codec
+}
+
+func (c layered) Encode(name string, plain []byte) ([]byte, error) {
+	data, err := c.store.Encode(name, plain)
+	if err != nil {
+		return nil, err
+	}
+	return c.test.Encode(name, data)
+}
+
+func (c layered) Decode(name string, data []byte) ([]byte, error) {
+	plain, err := c.test.Decode(name, data)
+	if err != nil {
+		return nil, err
+	}
+	return c.store.Decode(name, plain)
+}
+
+func TestBackends(t *testing.T) {
+	encrypted, err := storage.NewKeyCodec("correct horse battery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	codecs := []struct {
+		name  string
+		codec storage.Codec
+	}{
+		{"plain", storage.PlainCodec{}},
+		{"encrypted", encrypted},
+	}
+	for _, kind := range []string{storage.BackendJSON, storage.BackendBolt} {
+		for _, c := range codecs {
+			t.Run(kind+"/"+c.name, func(t *testing.T) {
+				err := storetest.TestStore(func(codec storage.Codec) (storage.Store, error) {
+					return storage.OpenStore(kind, t.TempDir(), layered{store: c.codec, test: codec})
+				})
+				if err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,368 @@
+// Package storetest checks that a storage.Store behaves like the
+// built-in backends, in the spirit of testing/fstest.TestFS.
+package storetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"todo-ball/models"
+	"todo-ball/storage"
+)
+
+// OpenFunc must return a new, empty store in its own location that
+// encodes records with codec.
+type OpenFunc func(codec storage.Codec) (storage.Store, error)
+
+// TestStore runs every check against stores returned by open and
+// reports all failures in one error.
+func TestStore(open OpenFunc) error {
+	var failures []string
+	for _, c := range checks {
+		if err := run(open, c.fn); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", c.name, err))
+		}
+	}
+	if len(failures) > 0 {
+		return errors.New("storetest: " + strings.Join(failures, "\n\t"))
+	}
+	return nil
+}
+
+func run(open OpenFunc, fn func(s storage.Store, codec *switchCodec) error) (err error) {
+	codec := &switchCodec{current: tagCodec("a")}
+	s, err := open(codec)
+	if err != nil {
+		return fmt.Errorf("open: %w", err)
+	}
+	defer func() {
+		if cerr := s.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("close: %w", cerr)
+		}
+	}()
+	return fn(s, codec)
+}
+
+var checks = []struct {
+	name string
+	fn   func(s storage.Store, codec *switchCodec) error
+}{
+	{"empty", checkEmpty},
+	{"add", checkAdd},
+	{"update", checkUpdate},
+	{"delete", checkDelete},
+	{"lists", checkListsIndependent},
+	{"replace", checkReplace},
+	{"due index", checkDueBetween},
+	{"completion index", checkByCompletion},
+	{"drop list", checkDropList},
+	{"recode", checkRecode},
+	{"recode abort", checkRecodeAbort},
+}
+
+var base = time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+func todo(id string, dueHours int, completed bool) models.TodoItem {
+	return models.TodoItem{
+		ID:           id,
+		Title:        "todo " + id,
+		Completed:    completed,
+		DueDate:      base.Add(time.Duration(dueHours) * time.Hour),
+		CreatedAt:    base,
+		ReminderDays: 1,
+		Tags:         []string{"t" + id},
+	}
+}
+
+// expect compares by ID, ignoring order.
+func expect(got []models.TodoItem, err error, want ...models.TodoItem) error {
+	if err != nil {
+		return err
+	}
+	key := func(todos []models.TodoItem) string {
+		sorted := append([]models.TodoItem(nil), todos...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+		data, _ := json.Marshal(sorted)
+		return string(data)
+	}
+	if key(got) != key(want) {
+		return fmt.Errorf("got %s, want %s", key(got), key(want))
+	}
+	return nil
+}
+
+// expectOrdered also checks the order.
+func expectOrdered(got []models.TodoItem, err error, want ...models.TodoItem) error {
+	if err := expect(got, err, want...); err != nil {
+		return err
+	}
+	for i := range want {
+		if got[i].ID != want[i].ID {
+			return fmt.Errorf("position %d: got %s, want %s", i, got[i].ID, want[i].ID)
+		}
+	}
+	return nil
+}
+
+func expectErr(err, want error) error {
+	if !errors.Is(err, want) {
+		return fmt.Errorf("got error %v, want %v", err, want)
+	}
+	return nil
+}
+
+func addAll(s storage.Store, list string, todos ...models.TodoItem) error {
+	for _, t := range todos {
+		if err := s.Add(list, t); err != nil {
+			return fmt.Errorf("add %s: %w", t.ID, err)
+		}
+	}
+	return nil
+}
+
+func checkEmpty(s storage.Store, _ *switchCodec) error {
+	todos, err := s.Todos("missing")
+	if err := expect(todos, err); err != nil {
+		return err
+	}
+	if err := expectErr(s.Update("missing", todo("1", 0, false)), storage.ErrNotFound); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+	if err := expectErr(s.Delete("missing", "1"), storage.ErrNotFound); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+	return nil
+}
+
+func checkAdd(s storage.Store, _ *switchCodec) error {
+	a, b := todo("1", 1, false), todo("2", 2, true)
+	if err := addAll(s, "l", a, b); err != nil {
+		return err
+	}
+	if err := expectErr(s.Add("l", todo("1", 5, false)), storage.ErrDuplicateID); err != nil {
+		return fmt.Errorf("duplicate: %w", err)
+	}
+	todos, err := s.Todos("l")
+	return expect(todos, err, a, b)
+}
+
+func checkUpdate(s storage.Store, _ *switchCodec) error {
+	a, b := todo("1", 1, false), todo("2", 2, false)
+	if err := addAll(s, "l", a, b); err != nil {
+		return err
+	}
+	a.Title = "changed"
+	a.Completed = true
+	if err := s.Update("l", a); err != nil {
+		return err
+	}
+	if err := expectErr(s.Update("l", todo("3", 0, false)), storage.ErrNotFound); err != nil {
+		return fmt.Errorf("missing: %w", err)
+	}
+	todos, err := s.Todos("l")
+	return expect(todos, err, a, b)
+}
+
+func checkDelete(s storage.Store, _ *switchCodec) error {
+	a, b := todo("1", 1, false), todo("2", 2, false)
+	if err := addAll(s, "l", a, b); err != nil {
+		return err
+	}
+	if err := s.Delete("l", "1"); err != nil {
+		return err
+	}
+	if err := expectErr(s.Delete("l", "1"), storage.ErrNotFound); err != nil {
+		return fmt.Errorf("twice: %w", err)
+	}
+	todos, err := s.Todos("l")
+	return expect(todos, err, b)
+}
+
+func checkListsIndependent(s storage.Store, _ *switchCodec) error {
+	a, b := todo("1", 1, false), todo("1", 2, true)
+	if err := addAll(s, "x", a); err != nil {
+		return err
+	}
+	// The same ID in another list is not a duplicate
+	if err := addAll(s, "y", b); err != nil {
+		return err
+	}
+	todos, err := s.Todos("x")
+	if err := expect(todos, err, a); err != nil {
+		return fmt.Errorf("x: %w", err)
+	}
+	todos, err = s.Todos("y")
+	return expect(todos, err, b)
+}
+
+func checkReplace(s storage.Store, _ *switchCodec) error {
+	if err := addAll(s, "l", todo("1", 1, false), todo("2", 2, false)); err != nil {
+		return err
+	}
+	c := todo("3", 3, false)
+	if err := s.Replace("l", []models.TodoItem{c}); err != nil {
+		return err
+	}
+	todos, err := s.Todos("l")
+	if err := expect(todos, err, c); err != nil {
+		return err
+	}
+	// Indexes must follow too
+	todos, err = s.DueBetween("l", base, base.Add(24*time.Hour))
+	return expect(todos, err, c)
+}
+
+func checkDueBetween(s storage.Store, _ *switchCodec) error {
+	early, mid, late := todo("3", -2, false), todo("1", 5, false), todo("2", 10, false)
+	if err := addAll(s, "l", late, early, mid); err != nil {
+		return err
+	}
+	todos, err := s.DueBetween("l", base.Add(-2*time.Hour), base.Add(10*time.Hour))
+	if err := expectOrdered(todos, err, early, mid); err != nil {
+		return fmt.Errorf("from inclusive, to exclusive: %w", err)
+	}
+
+	// Moving a due date must move it in the index
+	mid.DueDate = base.Add(20 * time.Hour)
+	if err := s.Update("l", mid); err != nil {
+		return err
+	}
+	todos, err = s.DueBetween("l", base, base.Add(24*time.Hour))
+	if err := expectOrdered(todos, err, late, mid); err != nil {
+		return fmt.Errorf("after update: %w", err)
+	}
+
+	// Other time zones describe the same instants
+	shanghai := time.FixedZone("CST", 8*3600)
+	todos, err = s.DueBetween("l", base.In(shanghai), base.Add(24*time.Hour).In(shanghai))
+	return expectOrdered(todos, err, late, mid)
+}
+
+func checkByCompletion(s storage.Store, _ *switchCodec) error {
+	a, b, c := todo("1", 1, false), todo("2", 2, true), todo("3", 3, false)
+	if err := addAll(s, "l", a, b, c); err != nil {
+		return err
+	}
+	todos, err := s.ByCompletion("l", false)
+	if err := expect(todos, err, a, c); err != nil {
+		return fmt.Errorf("pending: %w", err)
+	}
+
+	a.Completed = true
+	if err := s.Update("l", a); err != nil {
+		return err
+	}
+	if err := s.Delete("l", "2"); err != nil {
+		return err
+	}
+	todos, err = s.ByCompletion("l", true)
+	if err := expect(todos, err, a); err != nil {
+		return fmt.Errorf("completed: %w", err)
+	}
+	todos, err = s.ByCompletion("l", false)
+	return expect(todos, err, c)
+}
+
+func checkDropList(s storage.Store, _ *switchCodec) error {
+	a := todo("1", 1, false)
+	if err := addAll(s, "x", a); err != nil {
+		return err
+	}
+	if err := addAll(s, "y", todo("2", 1, false)); err != nil {
+		return err
+	}
+	if err := s.DropList("y"); err != nil {
+		return err
+	}
+	if err := s.DropList("never-existed"); err != nil {
+		return fmt.Errorf("missing list: %w", err)
+	}
+	todos, err := s.Todos("y")
+	if err := expect(todos, err); err != nil {
+		return err
+	}
+	todos, err = s.Todos("x")
+	return expect(todos, err, a)
+}
+
+func checkRecode(s storage.Store, codec *switchCodec) error {
+	a, b := todo("1", 1, false), todo("2", 2, false)
+	if err := addAll(s, "x", a); err != nil {
+		return err
+	}
+	if err := addAll(s, "y", b); err != nil {
+		return err
+	}
+	from, to := tagCodec("a"), tagCodec("b")
+	err := s.Recode([]string{"x", "y", "empty"}, from, to, func() error {
+		codec.current = to
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	todos, err := s.Todos("x")
+	if err := expect(todos, err, a); err != nil {
+		return err
+	}
+	todos, err = s.Todos("y")
+	if err := expect(todos, err, b); err != nil {
+		return err
+	}
+	// Records written afterwards use the new codec as well
+	c := todo("3", 3, false)
+	if err := addAll(s, "x", c); err != nil {
+		return err
+	}
+	todos, err = s.Todos("x")
+	return expect(todos, err, a, c)
+}
+
+func checkRecodeAbort(s storage.Store, _ *switchCodec) error {
+	a := todo("1", 1, false)
+	if err := addAll(s, "x", a); err != nil {
+		return err
+	}
+	failed := errors.New("commit failed")
+	err := s.Recode([]string{"x"}, tagCodec("a"), tagCodec("b"), func() error { return failed })
+	if !errors.Is(err, failed) {
+		return fmt.Errorf("got error %v, want the commit error", err)
+	}
+	// Nothing changed: the old codec still reads everything
+	todos, err := s.Todos("x")
+	return expect(todos, err, a)
+}
+
+// tagCodec prefixes records with its tag and rejects any other tag, so
+// reading a record with the wrong codec is caught.
+type tagCodec string
+
+func (c tagCodec) Encode(name string, plain []byte) ([]byte, error) {
+	return append([]byte(string(c)+":"+name+":"), plain...), nil
+}
+
+func (c tagCodec) Decode(name string, data []byte) ([]byte, error) {
+	prefix := []byte(string(c) + ":" + name + ":")
+	if !bytes.HasPrefix(data, prefix) {
+		return nil, fmt.Errorf("record %q not encoded by codec %q", name, string(c))
+	}
+	return data[len(prefix):], nil
+}
+
+// switchCodec is what the store under test holds; Recode checks swap
+// its current codec in commit, like Storage does with encryption.
+type switchCodec struct {
+	current storage.Codec
+}
+
+func (c *switchCodec) Encode(name string, plain []byte) ([]byte, error) {
+	return c.current.Encode(name, plain)
+}
+
+func (c *switchCodec) Decode(name string, data []byte) ([]byte, error) {
+	return c.current.Decode(name, data)
+}
@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"todo-ball/models"
+)
+
+const ThemesFileName = "themes.json"
+
+var (
+	ErrThemeNotFound = errors.New("theme not found")
+	ErrBuiltinTheme  = errors.New("built-in themes cannot be changed")
+	ErrTooManyThemes = errors.New("too many themes")
+)
+
+// Themes returns the built-in themes followed by the user's, which are
+// read from themes.json on every call so both processes agree.
+func (s *Storage) Themes() ([]models.Theme, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	user, err := s.loadThemesLocked()
+	if err != nil {
+		return nil, err
+	}
+	themes := models.BuiltinThemes()
+	for i := range themes {
+		themes[i].Builtin = true
+	}
+	return append(themes, user...), nil
+}
+
+// Theme finds a built-in or user theme by name.
+func (s *Storage) Theme(name string) (models.Theme, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.findThemeLocked(name)
+}
+
+func (s *Storage) findThemeLocked(name string) (models.Theme, error) {
+	if t, ok := models.FindBuiltinTheme(name); ok {
+		return t, nil
+	}
+	user, err := s.loadThemesLocked()
+	if err != nil {
+		return models.Theme{}, err
+	}
+	for _, t := range user {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return models.Theme{}, ErrThemeNotFound
+}
+
+// SaveTheme adds a user theme, replacing one of the same name.
+func (s *Storage) SaveTheme(t models.Theme) error {
+	t.Name = strings.TrimSpace(t.Name)
+	t.Builtin = false
+	if err := models.ValidateTheme(t); err != nil {
+		return err
+	}
+	if _, ok := models.FindBuiltinTheme(t.Name); ok {
+		return ErrBuiltinTheme
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, err := s.loadThemesLocked()
+	if err != nil {
+		return err
+	}
+	for i := range user {
+		if user[i].Name == t.Name {
+			user[i] = t
+			return s.saveThemesLocked(user)
+		}
+	}
+	if len(user) >= models.MaxThemes {
+		return ErrTooManyThemes
+	}
+	return s.saveThemesLocked(append(user, t))
+}
+
+// DeleteTheme removes a user theme.
+func (s *Storage) DeleteTheme(name string) error {
+	if _, ok := models.FindBuiltinTheme(name); ok {
+		return ErrBuiltinTheme
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, err := s.loadThemesLocked()
+	if err != nil {
+		return err
+	}
+	for i := range user {
+		if user[i].Name == name {
+			return s.saveThemesLocked(append(user[:i], user[i+1:]...))
+		}
+	}
+	return ErrThemeNotFound
+}
+
+// ApplyTheme copies a theme into the config and saves it in one step.
+// config.json is read again first, so settings changed meanwhile by the
+// other window are kept.
+func (s *Storage) ApplyTheme(name string) (models.AppConfig, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadConfigLocked(); err != nil {
+		return s.config, err
+	}
+	t, err := s.findThemeLocked(name)
+	if err != nil {
+		return s.config, err
+	}
+	cfg := s.config.WithTheme(t)
+	if err := models.ValidateConfig(cfg); err != nil {
+		return s.config, err
+	}
+	prev := s.config
+	s.config = cfg
+	if err := s.saveConfigLocked(); err != nil {
+		s.config = prev
+		return prev, err
+	}
+	return cfg, nil
+}
+
+// loadThemesLocked reads the user themes, skipping invalid entries of a
+// hand-edited file.
+func (s *Storage) loadThemesLocked() ([]models.Theme, error) {
+	data, err := os.ReadFile(filepath.Join(s.DataDir, ThemesFileName))
+	if os.IsNotExist(err) {
+		return []models.Theme{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var themes []models.Theme
+	if err := json.Unmarshal(data, &themes); err != nil {
+		return nil, err
+	}
+	valid := []models.Theme{}
+	for _, t := range themes {
+		if _, builtin := models.FindBuiltinTheme(t.Name); builtin || models.ValidateTheme(t) != nil {
+			continue
+		}
+		t.Builtin = false
+		valid = append(valid, t)
+	}
+	return valid, nil
+}
+
+func (s *Storage) saveThemesLocked(themes []models.Theme) error {
+	data, err := json.MarshalIndent(themes, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(s.DataDir, ThemesFileName), data)
+}
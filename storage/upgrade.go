@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"todo-ball/models"
+)
+
+// PinTimeZones gives every todo saved without a time zone the zone it was
+// entered in, see models.TodoItem.PinTimeZone, and returns how many
+// changed. Older versions sent due dates as UTC instants of the computer's
+// wall-clock time, so that zone keeps their wall-clock time intact.
+func (s *Storage) PinTimeZones(zone string) (int, error) {
+	if zone == "" {
+		return 0, nil
+	}
+	return s.upgradeTodos(func(t models.TodoItem) (models.TodoItem, bool) {
+		return t.PinTimeZone(zone)
+	})
+}
+
+// MigrateReminderDays turns the single reminder of todos saved by older
+// versions into a reminder list, see models.TodoItem.MigrateReminderDays,
+// and returns how many changed.
+func (s *Storage) MigrateReminderDays() (int, error) {
+	return s.upgradeTodos(models.TodoItem.MigrateReminderDays)
+}
+
+// upgradeTodos applies fix to the todos of every list and archive,
+// rewriting only the lists where it changed something.
+func (s *Storage) upgradeTodos(fix func(models.TodoItem) (models.TodoItem, bool)) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lockedLocked() {
+		return 0, ErrLocked
+	}
+
+	upgraded := 0
+	for _, id := range s.allListIDsLocked() {
+		todos, err := s.backend.Todos(id)
+		if err != nil {
+			return upgraded, err
+		}
+		changed := 0
+		for i, t := range todos {
+			var ok bool
+			if todos[i], ok = fix(t); ok {
+				changed++
+			}
+		}
+		if changed == 0 {
+			continue
+		}
+		if err := s.backend.Replace(id, todos); err != nil {
+			return upgraded, err
+		}
+		upgraded += changed
+	}
+
+	if upgraded > 0 {
+		todos, err := s.backend.Todos(s.config.ActiveList)
+		if err != nil {
+			return upgraded, err
+		}
+		s.Todos = todos
+	}
+	return upgraded, nil
+}
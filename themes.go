@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"todo-ball/apperr"
+	"todo-ball/models"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// maxThemeFileSize bounds imported theme files; real ones are a few hundred bytes.
+const maxThemeFileSize = 64 << 10
+
+// ListThemes returns the built-in themes followed by the user's.
+func (a *App) ListThemes() ([]models.Theme, error) {
+	themes, err := a.Store.Themes()
+	if err != nil {
+		return nil, storeError("theme.load_failed", err)
+	}
+	return themes, nil
+}
+
+// ApplyTheme switches the appearance settings to a theme and tells the
+// ball to repaint. Returns the updated config.
+func (a *App) ApplyTheme(name string) (models.AppConfig, error) {
+	cfg, err := a.Store.ApplyTheme(name)
+	if err != nil {
+		return cfg, storeError("config.save_failed", err)
+	}
+	slog.Info("theme applied", "theme", name)
+	a.notifyUpdate()
+	return cfg, nil
+}
+
+// SaveTheme stores a user theme, replacing one of the same name.
+func (a *App) SaveTheme(theme models.Theme) error {
+	if err := a.Store.SaveTheme(theme); err != nil {
+		return storeError("theme.save_failed", err)
+	}
+	return nil
+}
+
+// DeleteTheme removes a user theme.
+func (a *App) DeleteTheme(name string) error {
+	if err := a.Store.DeleteTheme(name); err != nil {
+		return storeError("theme.save_failed", err)
+	}
+	return nil
+}
+
+// ExportTheme writes a theme to a JSON file picked by the user. Returns
+// the path, or "" if the dialog was cancelled.
+func (a *App) ExportTheme(name string) (string, error) {
+	theme, err := a.Store.Theme(name)
+	if err != nil {
+		return "", storeError("theme.load_failed", err)
+	}
+	theme.Builtin = false
+	data, err := json.MarshalIndent(theme, "", "  ")
+	if err != nil {
+		return "", apperr.Wrap(apperr.Internal, "theme.save_failed", err)
+	}
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "导出主题",
+		DefaultFilename: "主题-" + theme.Name + ".json",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "主题文件", Pattern: "*.json"},
+		},
+	})
+	if err != nil {
+		return "", apperr.Wrap(apperr.IO, "dialog.failed", err)
+	}
+	if path == "" {
+		return "", nil
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", apperr.Wrap(apperr.IO, "theme.save_failed", err)
+	}
+	return path, nil
+}
+
+// ImportTheme reads a theme exported by ExportTheme and adds it to the
+// user themes. Returns a zero theme if the dialog was cancelled.
+func (a *App) ImportTheme() (models.Theme, error) {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "导入主题",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "主题文件", Pattern: "*.json"},
+		},
+	})
+	if err != nil {
+		return models.Theme{}, apperr.Wrap(apperr.IO, "dialog.failed", err)
+	}
+	if path == "" {
+		return models.Theme{}, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return models.Theme{}, apperr.Wrap(apperr.IO, "file.read_failed", err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxThemeFileSize+1))
+	if err != nil {
+		return models.Theme{}, apperr.Wrap(apperr.IO, "file.read_failed", err)
+	}
+	var theme models.Theme
+	if len(data) > maxThemeFileSize || json.Unmarshal(data, &theme) != nil {
+		return models.Theme{}, apperr.New(apperr.Validation, "theme.invalid_file")
+	}
+	if err := a.Store.SaveTheme(theme); err != nil {
+		return models.Theme{}, storeError("theme.save_failed", err)
+	}
+	theme.Name = strings.TrimSpace(theme.Name)
+	theme.Builtin = false
+	slog.Info("theme imported", "theme", theme.Name, "path", path)
+	return theme, nil
+}
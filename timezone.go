@@ -0,0 +1,30 @@
+package main
+
+import (
+	"log/slog"
+	"todo-ball/apperr"
+	"todo-ball/dates"
+)
+
+// PinTimeZones gives todos saved before time zones were kept the
+// computer's zone. Only the main window calls it, on load and again
+// after unlocking, passing the zone its browser engine reports: Windows
+// doesn't name its zones the IANA way, so the backend can't tell.
+func (a *App) PinTimeZones(zone string) error {
+	if !dates.ValidZone(zone) {
+		return apperr.Invalid("time_zone", "todo.invalid_time_zone", zone)
+	}
+	if zone == "" || a.Store.IsLocked() {
+		return nil
+	}
+	pinned, err := a.Store.PinTimeZones(zone)
+	if err != nil {
+		slog.Error("pin time zones failed", "zone", zone, "err", err)
+		return storeError("todo.save_failed", err)
+	}
+	if pinned > 0 {
+		slog.Info("pinned todo time zones", "count", pinned, "zone", zone)
+		a.notifyUpdate()
+	}
+	return nil
+}
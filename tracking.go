@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+	"todo-ball/apperr"
+	"todo-ball/platform"
+	"todo-ball/storage"
+	"todo-ball/tracking"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// idleCheckInterval is how often the main process asks Windows for the
+// time since the last input
+const idleCheckInterval = 15 * time.Second
+
+// StartTracking tracks time on a todo of the active list, stopping the
+// tracker of any other todo first.
+func (a *App) StartTracking(id string) (tracking.Status, error) {
+	found := false
+	for _, t := range a.Store.GetTodos() {
+		if t.ID == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return tracking.Status{}, storeError("todo.not_found", storage.ErrNotFound)
+	}
+	return a.trackingChanged(a.tracking.Start(id, a.Store.Config().ActiveList))
+}
+
+// StopTracking ends the running tracker and records its entry.
+func (a *App) StopTracking() (tracking.Status, error) {
+	return a.trackingChanged(a.tracking.Stop())
+}
+
+// GetTrackingStatus returns the running tracker, if any.
+func (a *App) GetTrackingStatus() (tracking.Status, error) {
+	status, err := a.tracking.Status()
+	if err != nil {
+		return tracking.Status{}, trackingError(err)
+	}
+	return status, nil
+}
+
+// GetTimeEntries returns the time entries of a todo, or all of them if
+// id is empty, including the running one.
+func (a *App) GetTimeEntries(id string) ([]tracking.Entry, error) {
+	entries, err := a.tracking.Entries(id)
+	if err != nil {
+		return nil, trackingError(err)
+	}
+	return entries, nil
+}
+
+// GetTimeTotals sums tracked time per todo, tag and day. from and to are
+// as in GetArchive.
+func (a *App) GetTimeTotals(from string, to string) (tracking.Totals, error) {
+	entries, err := a.timeEntriesBetween(from, to)
+	if err != nil {
+		return tracking.Totals{}, err
+	}
+	todos, err := a.Store.AllTodos()
+	if err != nil {
+		return tracking.Totals{}, storeError("file.read_failed", err)
+	}
+	return tracking.Summarize(entries, todos, time.Local), nil
+}
+
+// ExportTimesheet asks where to save the time entries between from and
+// to as CSV and writes them. Returns the chosen path, or "" if the dialog
+// was cancelled.
+func (a *App) ExportTimesheet(from string, to string) (string, error) {
+	entries, err := a.timeEntriesBetween(from, to)
+	if err != nil {
+		return "", err
+	}
+	todos, err := a.Store.AllTodos()
+	if err != nil {
+		return "", storeError("file.read_failed", err)
+	}
+	var buf bytes.Buffer
+	if err := tracking.WriteCSV(&buf, entries, todos, a.Store.GetLists(), time.Local); err != nil {
+		return "", apperr.Wrap(apperr.Internal, "internal", err)
+	}
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "导出工时表",
+		DefaultFilename: "工时-" + time.Now().Format(archiveDateLayout) + ".csv",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "CSV", Pattern: "*.csv"},
+		},
+	})
+	if err != nil {
+		return "", apperr.Wrap(apperr.IO, "dialog.failed", err)
+	}
+	if path == "" {
+		return "", nil
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return "", apperr.Wrap(apperr.IO, "report.save_failed", err)
+	}
+	return path, nil
+}
+
+func (a *App) timeEntriesBetween(from, to string) ([]tracking.Entry, error) {
+	start, _, err := parseArchiveTime("from", from)
+	if err != nil {
+		return nil, err
+	}
+	end, dateOnly, err := parseArchiveTime("to", to)
+	if err != nil {
+		return nil, err
+	}
+	if dateOnly {
+		end = end.AddDate(0, 0, 1)
+	}
+	entries, err := a.tracking.Entries("")
+	if err != nil {
+		return nil, trackingError(err)
+	}
+	return tracking.Clip(entries, start, end), nil
+}
+
+func (a *App) trackingChanged(status tracking.Status, err error) (tracking.Status, error) {
+	if err != nil {
+		return tracking.Status{}, trackingError(err)
+	}
+	runtime.EventsEmit(a.ctx, "tracking_updated", status)
+	return status, nil
+}
+
+func trackingError(err error) error {
+	if errors.Is(err, tracking.ErrNotRunning) {
+		return apperr.Wrap(apperr.Conflict, "tracking.not_running", err)
+	}
+	slog.Error("time tracking failed", "err", err)
+	return apperr.Wrap(apperr.IO, "tracking.save_failed", err)
+}
+
+// startIdleWatcher pauses tracking after IdleMinutes without keyboard or
+// mouse input, ending the entry at the last input, and resumes it when
+// the user is back. Only the main process watches.
+func (a *App) startIdleWatcher() {
+	go func() {
+		ticker := time.NewTicker(idleCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-a.ctx.Done():
+				return
+			}
+			limit := time.Duration(a.Store.Config().IdleMinutes) * time.Minute
+			if limit <= 0 {
+				continue
+			}
+			idle, err := platform.IdleTime()
+			if err != nil {
+				slog.Warn("GetLastInputInfo failed, idle detection off", "err", err)
+				return
+			}
+			status, err := a.tracking.Status()
+			if err != nil || !status.Active {
+				continue
+			}
+			switch {
+			case !status.Idle && idle >= limit:
+				slog.Info("user idle, pausing time tracking", "idle", idle.Round(time.Second))
+				a.trackingChanged(a.tracking.Idle(time.Now().Add(-idle)))
+			case status.Idle && idle < limit:
+				slog.Info("user back, resuming time tracking")
+				a.trackingChanged(a.tracking.Back())
+			}
+		}
+	}()
+}
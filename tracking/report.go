@@ -0,0 +1,178 @@
+package tracking
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"todo-ball/models"
+)
+
+const dayLayout = "2006-01-02"
+
+type TodoTotal struct {
+	TodoID  string `json:"todo_id"`
+	ListID  string `json:"list_id"`
+	Title   string `json:"title"` // Empty if the todo was deleted
+	Seconds int    `json:"seconds"`
+}
+
+type TagTotal struct {
+	Tag     string `json:"tag"` // Empty for untagged todos
+	Seconds int    `json:"seconds"`
+}
+
+type DayTotal struct {
+	Date    string `json:"date"` // YYYY-MM-DD
+	Seconds int    `json:"seconds"`
+}
+
+// Totals sums the tracked time over a range, largest first (days in
+// calendar order). A todo with several tags counts fully for each.
+type Totals struct {
+	Seconds int         `json:"seconds"`
+	ByTodo  []TodoTotal `json:"by_todo"`
+	ByTag   []TagTotal  `json:"by_tag"`
+	ByDay   []DayTotal  `json:"by_day"`
+}
+
+// Clip cuts entries to [from, to), dropping those outside. A zero from
+// or to leaves that end open.
+func Clip(entries []Entry, from, to time.Time) []Entry {
+	clipped := []Entry{}
+	for _, e := range entries {
+		if !from.IsZero() && e.Start.Before(from) {
+			e.Start = from
+		}
+		if !to.IsZero() && e.End.After(to) {
+			e.End = to
+		}
+		if e.End.After(e.Start) {
+			clipped = append(clipped, e)
+		}
+	}
+	return clipped
+}
+
+// todoIndex finds todos by ID.
+func todoIndex(todos []models.TodoItem) map[string]models.TodoItem {
+	index := make(map[string]models.TodoItem, len(todos))
+	for _, t := range todos {
+		index[t.ID] = t
+	}
+	return index
+}
+
+// Summarize totals entries (already clipped) per todo, tag and calendar
+// day in loc. Entries across midnight are split between the days.
+func Summarize(entries []Entry, todos []models.TodoItem, loc *time.Location) Totals {
+	index := todoIndex(todos)
+	var total time.Duration
+	perTodo := map[[2]string]time.Duration{}
+	perTag := map[string]time.Duration{}
+	perDay := map[string]time.Duration{}
+
+	for _, e := range entries {
+		d := e.Duration()
+		total += d
+		perTodo[[2]string{e.ListID, e.TodoID}] += d
+
+		tags := index[e.TodoID].Tags
+		if len(tags) == 0 {
+			tags = []string{""}
+		}
+		for _, tag := range tags {
+			perTag[tag] += d
+		}
+
+		for start := e.Start.In(loc); start.Before(e.End); {
+			y, m, day := start.Date()
+			end := time.Date(y, m, day+1, 0, 0, 0, 0, loc)
+			if end.After(e.End) {
+				end = e.End
+			}
+			perDay[start.Format(dayLayout)] += end.Sub(start)
+			start = end
+		}
+	}
+
+	totals := Totals{
+		Seconds: seconds(total),
+		ByTodo:  []TodoTotal{},
+		ByTag:   []TagTotal{},
+		ByDay:   []DayTotal{},
+	}
+	for key, d := range perTodo {
+		totals.ByTodo = append(totals.ByTodo, TodoTotal{ListID: key[0], TodoID: key[1], Title: index[key[1]].Title, Seconds: seconds(d)})
+	}
+	sort.Slice(totals.ByTodo, func(i, j int) bool {
+		a, b := totals.ByTodo[i], totals.ByTodo[j]
+		if a.Seconds != b.Seconds {
+			return a.Seconds > b.Seconds
+		}
+		return a.TodoID < b.TodoID
+	})
+	for tag, d := range perTag {
+		totals.ByTag = append(totals.ByTag, TagTotal{Tag: tag, Seconds: seconds(d)})
+	}
+	sort.Slice(totals.ByTag, func(i, j int) bool {
+		a, b := totals.ByTag[i], totals.ByTag[j]
+		if a.Seconds != b.Seconds {
+			return a.Seconds > b.Seconds
+		}
+		return a.Tag < b.Tag
+	})
+	for day, d := range perDay {
+		totals.ByDay = append(totals.ByDay, DayTotal{Date: day, Seconds: seconds(d)})
+	}
+	sort.Slice(totals.ByDay, func(i, j int) bool { return totals.ByDay[i].Date < totals.ByDay[j].Date })
+	return totals
+}
+
+func seconds(d time.Duration) int {
+	return int(d.Round(time.Second) / time.Second)
+}
+
+// WriteCSV writes a timesheet with one row per entry, oldest first. It
+// starts with a UTF-8 byte order mark so Excel reads the Chinese headers.
+func WriteCSV(w io.Writer, entries []Entry, todos []models.TodoItem, lists []models.TodoList, loc *time.Location) error {
+	index := todoIndex(todos)
+	listNames := make(map[string]string, len(lists))
+	for _, l := range lists {
+		listNames[l.ID] = l.Name
+	}
+	sorted := append([]Entry(nil), entries...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"日期", "开始", "结束", "时长(小时)", "清单", "任务", "标签"})
+	for _, e := range sorted {
+		start, end := e.Start.In(loc), e.End.In(loc)
+		todo := index[e.TodoID]
+		cw.Write([]string{
+			start.Format(dayLayout),
+			start.Format("15:04:05"),
+			end.Format("15:04:05"),
+			fmt.Sprintf("%.2f", e.Duration().Hours()),
+			csvText(listNames[e.ListID]),
+			csvText(todo.Title),
+			csvText(strings.Join(todo.Tags, " ")),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvText quotes text typed by the user that a spreadsheet would take
+// for a formula, so opening the timesheet can't run it.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,226 @@
+// Package tracking records the time spent on todos, for billing.
+//
+// Like the focus timer, the tracker lives in a file shared by the main
+// window and the ball (tracking.json), so only one can run at a time
+// across both processes. Each stretch of tracked time is an Entry;
+// stopping, switching todos and going idle close the current one.
+package tracking
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+	"todo-ball/statefile"
+)
+
+const FileName = "tracking.json"
+
+var ErrNotRunning = errors.New("time tracking is not running")
+
+// Entry is one stretch of time spent on a todo.
+type Entry struct {
+	ID     string    `json:"id"`
+	TodoID string    `json:"todo_id"`
+	ListID string    `json:"list_id"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	// Set on the entry of the running tracker, whose End is now
+	Running bool `json:"running,omitempty"`
+}
+
+func (e Entry) Duration() time.Duration {
+	return e.End.Sub(e.Start)
+}
+
+// tracker is the running tracker. While idle no entry is open and Since
+// is when the user went idle.
+type tracker struct {
+	TodoID string    `json:"todo_id"`
+	ListID string    `json:"list_id"`
+	Since  time.Time `json:"since"`
+	Idle   bool      `json:"idle,omitempty"`
+}
+
+// Status is what the UI shows. Inactive when nothing is tracked.
+type Status struct {
+	Active bool      `json:"active"`
+	TodoID string    `json:"todo_id"`
+	ListID string    `json:"list_id"`
+	Since  time.Time `json:"since"` // Start of the open entry, or of the idle time
+	Idle   bool      `json:"idle"`  // Paused until the user is back
+}
+
+type state struct {
+	Active  *tracker `json:"active,omitempty"`
+	Entries []Entry  `json:"entries"`
+}
+
+// Manager owns tracking.json and re-reads it when the other process
+// changed it.
+type Manager struct {
+	// Now replaces time.Now, for tests.
+	Now func() time.Time
+
+	mu    sync.Mutex
+	file  *statefile.File[state]
+	state state
+}
+
+// NewManager keeps its state in dir/tracking.json.
+func NewManager(dir string) *Manager {
+	return &Manager{file: statefile.New[state](filepath.Join(dir, FileName))}
+}
+
+func (m *Manager) now() time.Time {
+	if m.Now != nil {
+		return m.Now()
+	}
+	return time.Now()
+}
+
+func (m *Manager) loadLocked() error {
+	return m.file.Load(&m.state)
+}
+
+func (m *Manager) saveLocked() error {
+	return m.file.Save(m.state)
+}
+
+// update loads the state, applies fn and saves if it changed anything.
+func (m *Manager) update(fn func(now time.Time) (bool, error)) (Status, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.loadLocked(); err != nil {
+		return Status{}, err
+	}
+	now := m.now()
+	changed, err := fn(now)
+	if err != nil {
+		return Status{}, err
+	}
+	if changed {
+		if err := m.saveLocked(); err != nil {
+			return Status{}, err
+		}
+	}
+	return m.statusLocked(), nil
+}
+
+func (m *Manager) statusLocked() Status {
+	t := m.state.Active
+	if t == nil {
+		return Status{}
+	}
+	return Status{Active: true, TodoID: t.TodoID, ListID: t.ListID, Since: t.Since, Idle: t.Idle}
+}
+
+// closeLocked ends the open entry of the running tracker at end.
+// Entries shorter than a second are dropped.
+func (m *Manager) closeLocked(end time.Time) {
+	t := m.state.Active
+	if t == nil || t.Idle || end.Sub(t.Since) < time.Second {
+		return
+	}
+	m.state.Entries = append(m.state.Entries, Entry{
+		ID:     fmt.Sprintf("%d", t.Since.UnixNano()),
+		TodoID: t.TodoID,
+		ListID: t.ListID,
+		Start:  t.Since,
+		End:    end,
+	})
+}
+
+// Status returns the running tracker, if any.
+func (m *Manager) Status() (Status, error) {
+	return m.update(func(time.Time) (bool, error) { return false, nil })
+}
+
+// Start tracks time on a todo from now. A tracker running on another
+// todo is stopped first; starting the same todo again does nothing.
+func (m *Manager) Start(todoID, listID string) (Status, error) {
+	return m.update(func(now time.Time) (bool, error) {
+		if t := m.state.Active; t != nil && t.TodoID == todoID && t.ListID == listID && !t.Idle {
+			return false, nil
+		}
+		m.closeLocked(now)
+		m.state.Active = &tracker{TodoID: todoID, ListID: listID, Since: now}
+		return true, nil
+	})
+}
+
+// Stop ends tracking and records the open entry.
+func (m *Manager) Stop() (Status, error) {
+	return m.update(func(now time.Time) (bool, error) {
+		if m.state.Active == nil {
+			return false, ErrNotRunning
+		}
+		m.closeLocked(now)
+		m.state.Active = nil
+		return true, nil
+	})
+}
+
+// Idle pauses tracking because the user has been away since lastInput,
+// which ends the open entry there. Does nothing unless tracking.
+func (m *Manager) Idle(lastInput time.Time) (Status, error) {
+	return m.update(func(now time.Time) (bool, error) {
+		t := m.state.Active
+		if t == nil || t.Idle {
+			return false, nil
+		}
+		end := lastInput
+		if end.Before(t.Since) {
+			end = t.Since
+		} else if end.After(now) {
+			end = now
+		}
+		m.closeLocked(end)
+		t.Idle = true
+		t.Since = end
+		return true, nil
+	})
+}
+
+// Back resumes tracking paused by Idle with a new entry from now.
+func (m *Manager) Back() (Status, error) {
+	return m.update(func(now time.Time) (bool, error) {
+		t := m.state.Active
+		if t == nil || !t.Idle {
+			return false, nil
+		}
+		t.Idle = false
+		t.Since = now
+		return true, nil
+	})
+}
+
+// Entries returns the entries of a todo, or all of them if todoID is
+// empty, oldest first. The running tracker is included, ending now.
+func (m *Manager) Entries(todoID string) ([]Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.loadLocked(); err != nil {
+		return nil, err
+	}
+	entries := []Entry{}
+	for _, e := range m.state.Entries {
+		if todoID == "" || e.TodoID == todoID {
+			entries = append(entries, e)
+		}
+	}
+	if t := m.state.Active; t != nil && !t.Idle && (todoID == "" || t.TodoID == todoID) {
+		entries = append(entries, Entry{
+			ID:      fmt.Sprintf("%d", t.Since.UnixNano()),
+			TodoID:  t.TodoID,
+			ListID:  t.ListID,
+			Start:   t.Since,
+			End:     m.now(),
+			Running: true,
+		})
+	}
+	return entries, nil
+}
@@ -0,0 +1,230 @@
+package tracking
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"todo-ball/models"
+)
+
+func mustZone(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("zone %s not available: %v", name, err)
+	}
+	return loc
+}
+
+func TestIdleAndBack(t *testing.T) {
+	at := func(h, min int) time.Time { return time.Date(2026, 3, 4, h, min, 0, 0, time.UTC) }
+	now := at(9, 0)
+	m := NewManager(t.TempDir())
+	m.Now = func() time.Time { return now }
+
+	if _, err := m.Start("t1", "l1"); err != nil {
+		t.Fatal(err)
+	}
+	// Idle ends the entry at the last input, not when it was noticed
+	now = at(9, 30)
+	st, err := m.Idle(at(9, 20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Status{Active: true, TodoID: "t1", ListID: "l1", Since: at(9, 20), Idle: true}); st != want {
+		t.Errorf("Idle = %+v, want %+v", st, want)
+	}
+	// Already idle: nothing changes
+	now = at(9, 40)
+	if st, _ := m.Idle(at(9, 35)); !st.Since.Equal(at(9, 20)) {
+		t.Errorf("second Idle moved Since to %s", st.Since)
+	}
+	// Idle time isn't tracked
+	if entries, _ := m.Entries(""); len(entries) != 1 {
+		t.Errorf("entries while idle = %+v, want only the closed one", entries)
+	}
+
+	now = at(9, 45)
+	st, err = m.Back()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Status{Active: true, TodoID: "t1", ListID: "l1", Since: at(9, 45)}); st != want {
+		t.Errorf("Back = %+v, want %+v", st, want)
+	}
+
+	// The running entry ends now
+	now = at(10, 0)
+	entries, err := m.Entries("t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || !entries[1].Running || !entries[1].End.Equal(now) {
+		t.Errorf("entries = %+v, want the running one last", entries)
+	}
+
+	if _, err := m.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Stop(); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("second Stop: got %v, want ErrNotRunning", err)
+	}
+	entries, _ = m.Entries("")
+	var spans [][2]time.Time
+	for _, e := range entries {
+		spans = append(spans, [2]time.Time{e.Start, e.End})
+	}
+	want := [][2]time.Time{{at(9, 0), at(9, 20)}, {at(9, 45), at(10, 0)}}
+	if !reflect.DeepEqual(spans, want) {
+		t.Errorf("entries = %v, want %v", spans, want)
+	}
+}
+
+func TestIdleClamped(t *testing.T) {
+	now := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	m := NewManager(t.TempDir())
+	m.Now = func() time.Time { return now }
+
+	// Idle and Back do nothing unless tracking
+	if st, err := m.Idle(now); err != nil || st.Active {
+		t.Errorf("Idle without tracking = %+v, %v", st, err)
+	}
+	if _, err := m.Start("t1", "l1"); err != nil {
+		t.Fatal(err)
+	}
+	if st, _ := m.Back(); st.Idle || !st.Since.Equal(now) {
+		t.Errorf("Back while tracking = %+v, want unchanged", st)
+	}
+	// Last input before tracking started leaves no entry
+	now = now.Add(time.Hour)
+	if st, _ := m.Idle(now.Add(-2 * time.Hour)); !st.Since.Equal(now.Add(-time.Hour)) {
+		t.Errorf("Idle since = %s, want the start", st.Since)
+	}
+	if entries, _ := m.Entries(""); len(entries) != 0 {
+		t.Errorf("entries = %+v, want none", entries)
+	}
+}
+
+func TestStartSwitches(t *testing.T) {
+	now := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	m := NewManager(t.TempDir())
+	m.Now = func() time.Time { return now }
+	m.Start("t1", "l1")
+	now = now.Add(10 * time.Minute)
+	// Same todo again keeps the entry open
+	if st, _ := m.Start("t1", "l1"); !st.Since.Equal(now.Add(-10 * time.Minute)) {
+		t.Errorf("restarting the same todo moved Since to %s", st.Since)
+	}
+	m.Start("t2", "l1")
+	now = now.Add(5 * time.Minute)
+	entries, _ := m.Entries("")
+	if len(entries) != 2 || entries[0].TodoID != "t1" || entries[0].Duration() != 10*time.Minute || entries[1].TodoID != "t2" {
+		t.Errorf("entries = %+v, want 10m on t1 then t2", entries)
+	}
+}
+
+func TestClip(t *testing.T) {
+	at := func(d, h int) time.Time { return time.Date(2026, 3, d, h, 0, 0, 0, time.UTC) }
+	entries := []Entry{
+		{ID: "before", Start: at(3, 8), End: at(3, 10)},
+		{ID: "across", Start: at(3, 22), End: at(4, 2)},
+		{ID: "inside", Start: at(4, 9), End: at(4, 10)},
+		{ID: "after", Start: at(5, 1), End: at(5, 2)},
+	}
+	got := Clip(entries, at(4, 0), at(5, 0))
+	want := []Entry{
+		{ID: "across", Start: at(4, 0), End: at(4, 2)},
+		{ID: "inside", Start: at(4, 9), End: at(4, 10)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Clip = %+v, want %+v", got, want)
+	}
+	if got := Clip(entries, time.Time{}, at(3, 9)); len(got) != 1 || !got[0].End.Equal(at(3, 9)) {
+		t.Errorf("Clip open start = %+v", got)
+	}
+	if got := Clip(entries, at(5, 0), time.Time{}); len(got) != 1 || got[0].ID != "after" {
+		t.Errorf("Clip open end = %+v", got)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	ny := mustZone(t, "America/New_York")
+	todos := []models.TodoItem{
+		{ID: "t1", Title: "Report", Tags: []string{"work", "client"}},
+		{ID: "t2", Title: "Gym"},
+	}
+	// US clocks go forward on 2026-03-08 and back on 2026-11-01
+	entries := []Entry{
+		{TodoID: "t1", ListID: "l1", Start: time.Date(2026, 3, 7, 23, 0, 0, 0, ny), End: time.Date(2026, 3, 8, 3, 30, 0, 0, ny)},
+		{TodoID: "t2", ListID: "l1", Start: time.Date(2026, 10, 31, 22, 0, 0, 0, ny), End: time.Date(2026, 11, 2, 1, 0, 0, 0, ny)},
+		{TodoID: "gone", ListID: "l2", Start: time.Date(2026, 3, 8, 12, 0, 0, 0, ny), End: time.Date(2026, 3, 8, 12, 30, 0, 0, ny)},
+	}
+	got := Summarize(entries, todos, ny)
+	h := 3600
+	want := Totals{
+		Seconds: 3*h + h/2 + 28*h + h/2,
+		ByTodo: []TodoTotal{
+			{TodoID: "t2", ListID: "l1", Title: "Gym", Seconds: 28 * h},
+			{TodoID: "t1", ListID: "l1", Title: "Report", Seconds: 3*h + h/2}, // An hour lost to the clocks
+			{TodoID: "gone", ListID: "l2", Seconds: h / 2},
+		},
+		ByTag: []TagTotal{
+			{Tag: "", Seconds: 28*h + h/2},
+			{Tag: "client", Seconds: 3*h + h/2},
+			{Tag: "work", Seconds: 3*h + h/2},
+		},
+		ByDay: []DayTotal{
+			{Date: "2026-03-07", Seconds: h},
+			{Date: "2026-03-08", Seconds: 3 * h}, // 00:00-03:30 is 2.5h, plus 12:00-12:30
+			{Date: "2026-10-31", Seconds: 2 * h},
+			{Date: "2026-11-01", Seconds: 25 * h},
+			{Date: "2026-11-02", Seconds: h},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Summarize =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if got := Summarize(nil, nil, ny); got.Seconds != 0 || got.ByTodo == nil || got.ByTag == nil || got.ByDay == nil {
+		t.Errorf("Summarize(nil) = %+v, want empty non-nil slices", got)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	shanghai := mustZone(t, "Asia/Shanghai")
+	todos := []models.TodoItem{
+		{ID: "t1", Title: "=HYPERLINK(\"http://x\")", Tags: []string{"-x", "work"}},
+		{ID: "t2", Title: "Plain, with comma"},
+	}
+	lists := []models.TodoList{{ID: "l1", Name: "@list"}, {ID: "l2", Name: "+1"}}
+	start := time.Date(2026, 3, 4, 1, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{TodoID: "t2", ListID: "l2", Start: start.Add(2 * time.Hour), End: start.Add(2*time.Hour + 45*time.Minute)},
+		{TodoID: "t1", ListID: "l1", Start: start, End: start.Add(90 * time.Minute)},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, entries, todos, lists, shanghai); err != nil {
+		t.Fatal(err)
+	}
+	text, ok := strings.CutPrefix(buf.String(), "\ufeff")
+	if !ok {
+		t.Error("no byte order mark")
+	}
+	rows, err := csv.NewReader(strings.NewReader(text)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"日期", "开始", "结束", "时长(小时)", "清单", "任务", "标签"},
+		{"2026-03-04", "09:00:00", "10:30:00", "1.50", "'@list", "'=HYPERLINK(\"http://x\")", "'-x work"},
+		{"2026-03-04", "11:00:00", "11:45:00", "0.75", "'+1", "Plain, with comma", ""},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("CSV rows =\n%q\nwant\n%q", rows, want)
+	}
+}
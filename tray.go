@@ -0,0 +1,332 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+	"todo-ball/dates"
+	"todo-ball/icons"
+	"todo-ball/models"
+	"unicode/utf8"
+
+	"github.com/energye/systray"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	// Pending todos listed in the tray menu, most urgent first
+	trayTodoCount = 5
+	// Longer titles are cut so the menu stays narrow
+	trayTitleLength = 30
+	// Refresh even without changes, so todos turn overdue on time
+	trayRefreshInterval = time.Minute
+)
+
+var overdueBadgeColor = color.NRGBA{R: 0xe7, G: 0x4c, B: 0x3c, A: 0xff}
+
+// trayMenu is the system tray icon of the main process. Its menu lists the
+// most urgent todos with actions for each.
+//
+// systray.ResetMenu doesn't forget the old items on Windows, so the
+// menu is built once with trayTodoCount slots that are retitled and
+// shown or hidden on refresh.
+type trayMenu struct {
+	app *App
+
+	mu    sync.Mutex
+	slots [trayTodoCount]traySlot
+	empty *systray.MenuItem
+	// Ticked while the ball lets mouse input through
+	clickThrough *systray.MenuItem
+	// Do-not-disturb, titled with its expiry while on
+	dnd    *systray.MenuItem
+	dndOff *systray.MenuItem
+
+	iconMu  sync.Mutex
+	iconKey string            // Icons shown, see updateIcons
+	badged  map[string][]byte // Overdue badges drawn on icons, by path
+}
+
+type traySlot struct {
+	item *systray.MenuItem
+	id   string // Todo shown in the slot, "" if hidden
+}
+
+// startTray runs the system tray (main mode only). On Windows it works in
+// a goroutine as long as it has its own message loop, which systray.Run
+// provides.
+func (a *App) startTray() {
+	slog.Debug("initializing system tray")
+	go systray.Run(func() {
+		slog.Debug("system tray ready")
+		a.tray.Store(newTrayMenu(a))
+		a.updateIcons()
+	}, nil)
+}
+
+// newTrayMenu builds the tray menu. Call it from the systray onReady
+// callback, then updateIcons to set the icon.
+func newTrayMenu(app *App) *trayMenu {
+	t := &trayMenu{app: app, badged: map[string][]byte{}}
+
+	systray.SetIcon(iconData)
+	systray.SetTitle("待办事项")
+	systray.SetTooltip("待办事项")
+
+	mQuickAdd := systray.AddMenuItem("快速添加...", "Quick Add")
+	mQuickAdd.Click(app.openQuickAdd)
+	systray.AddSeparator()
+
+	for i := range t.slots {
+		slot := &t.slots[i]
+		slot.item = systray.AddMenuItem("", "")
+		t.addAction(slot, "完成", func(id string) {
+			if err := app.ToggleTodo(id); err != nil {
+				slog.Error("complete todo from tray failed", "id", id, "err", err)
+			}
+		})
+		t.addAction(slot, "推迟 1 小时", func(id string) {
+			app.snoozeTodo(id, time.Hour)
+		})
+		t.addAction(slot, "推迟 1 天", func(id string) {
+			app.snoozeTodo(id, 24*time.Hour)
+		})
+		t.addAction(slot, "打开", app.showTodo)
+		slot.item.Hide()
+	}
+	t.empty = systray.AddMenuItem("没有待办事项", "")
+	t.empty.Disable()
+	systray.AddSeparator()
+
+	t.dnd = systray.AddMenuItem("免打扰", "Do Not Disturb")
+	for _, opt := range []struct {
+		title   string
+		minutes int
+	}{{"30 分钟", 30}, {"1 小时", 60}, {"2 小时", 120}, {"8 小时", 480}} {
+		minutes := opt.minutes
+		t.dnd.AddSubMenuItem(opt.title, "").Click(func() { app.setDNDFromTray(minutes) })
+	}
+	t.dndOff = t.dnd.AddSubMenuItem("关闭免打扰", "")
+	t.dndOff.Click(func() { app.setDNDFromTray(0) })
+
+	t.clickThrough = systray.AddMenuItemCheckbox("悬浮球鼠标穿透", "Click Through Ball", false)
+	t.clickThrough.Click(app.toggleClickThrough)
+
+	mOpen := systray.AddMenuItem("显示主界面", "Show Main Window")
+	mOpen.Click(func() {
+		app.OpenMain()
+	})
+
+	mQuit := systray.AddMenuItem("退出", "Quit Application")
+	mQuit.Click(func() {
+		systray.Quit()
+		app.FullQuit()
+	})
+
+	t.refresh()
+	go func() {
+		ticker := time.NewTicker(trayRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-app.ctx.Done():
+				return
+			}
+			t.refresh()
+		}
+	}()
+	return t
+}
+
+// addAction adds a submenu entry to slot that runs fn on the todo
+// currently shown there.
+func (t *trayMenu) addAction(slot *traySlot, title string, fn func(id string)) {
+	slot.item.AddSubMenuItem(title, "").Click(func() {
+		t.mu.Lock()
+		id := slot.id
+		t.mu.Unlock()
+		if id != "" {
+			fn(id)
+		}
+	})
+}
+
+// refresh lists the most urgent pending todos of the active list and
+// updates the tooltip, the click-through tick and do-not-disturb.
+func (t *trayMenu) refresh() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.app.Store.Config().ClickThrough {
+		t.clickThrough.Check()
+	} else {
+		t.clickThrough.Uncheck()
+	}
+	if until, err := time.Parse(time.RFC3339, t.app.quietStatus(time.Now()).DNDUntil); err == nil {
+		t.dnd.SetTitle("免打扰（至 " + until.Format("15:04") + "）")
+		t.dndOff.Enable()
+	} else {
+		t.dnd.SetTitle("免打扰")
+		t.dndOff.Disable()
+	}
+
+	// Deferred todos stay out of the menu until they start
+	now := time.Now()
+	pending, err := t.app.Store.Available(now)
+	if err != nil {
+		for i := range t.slots {
+			t.slots[i].id = ""
+			t.slots[i].item.Hide()
+		}
+		t.empty.SetTitle("🔒 数据已加密")
+		t.empty.Show()
+		systray.SetTooltip("待办事项（已加密）")
+		return
+	}
+
+	models.SortByUrgency(pending)
+	overdue := 0
+	for _, item := range pending {
+		if item.IsOverdue(now) {
+			overdue++
+		}
+	}
+
+	for i := range t.slots {
+		slot := &t.slots[i]
+		if i >= len(pending) {
+			slot.id = ""
+			slot.item.Hide()
+			continue
+		}
+		slot.id = pending[i].ID
+		slot.item.SetTitle(trayTitle(pending[i], now))
+		slot.item.Show()
+	}
+	if len(pending) == 0 {
+		t.empty.SetTitle("没有待办事项")
+		t.empty.Show()
+	} else {
+		t.empty.Hide()
+	}
+
+	tooltip := fmt.Sprintf("待办事项\n待办 %d 项", len(pending))
+	if overdue > 0 {
+		tooltip += fmt.Sprintf("，已过期 %d 项", overdue)
+	}
+	systray.SetTooltip(tooltip)
+}
+
+// setIcon shows the icon of state from set, unless key says it is
+// already shown. Without an overdue icon of its own, the overdue state
+// gets the normal icon with a badge.
+func (t *trayMenu) setIcon(set IconSet, state, key string) {
+	t.iconMu.Lock()
+	defer t.iconMu.Unlock()
+	if key == t.iconKey {
+		return
+	}
+	path := set.For(state)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		slog.Warn("cannot read tray icon, using embedded icon", "path", path, "err", err)
+		data = iconData
+	}
+	if state == iconOverdue && set.Overdue == "" {
+		data = t.badge(path, data)
+	}
+	systray.SetIcon(data)
+	t.iconKey = key
+}
+
+// badge returns the icon read from path with an overdue badge, or the
+// icon itself if it can't be drawn.
+func (t *trayMenu) badge(path string, icon []byte) []byte {
+	if b, ok := t.badged[path]; ok {
+		return b
+	}
+	b := icon
+	if img, err := icons.DecodeICO(icon); err != nil {
+		slog.Warn("cannot decode tray icon, no overdue badge", "path", path, "err", err)
+	} else if badged, err := icons.EncodeICO(icons.Badge(img, overdueBadgeColor)); err != nil {
+		slog.Warn("cannot encode overdue tray icon", "err", err)
+	} else {
+		b = badged
+	}
+	t.badged[path] = b
+	return b
+}
+
+// trayTitle marks overdue (⚠) and upcoming (⏰) todos and adds the due time.
+func trayTitle(item models.TodoItem, now time.Time) string {
+	title := item.Title
+	if utf8.RuneCountInString(title) > trayTitleLength {
+		title = string([]rune(title)[:trayTitleLength]) + "…"
+	}
+	switch {
+	case item.IsOverdue(now):
+		title = "⚠ " + title
+	case item.IsUpcoming(now):
+		title = "⏰ " + title
+	}
+	switch {
+	case item.AllDay:
+		title += "  " + item.DueDay().Format("01-02") + " 全天"
+	case item.HasDueDate():
+		title += "  " + item.DueLocal().Format("01-02 15:04")
+	}
+	return title
+}
+
+// refreshTray updates the tray menu after a change, if this process has one.
+func (a *App) refreshTray() {
+	if t := a.tray.Load(); t != nil {
+		t.refresh()
+	}
+}
+
+// openQuickAdd shows the main window with the quick-add input focused.
+func (a *App) openQuickAdd() {
+	a.OpenMain()
+	runtime.EventsEmit(a.ctx, "quick_add")
+}
+
+// showTodo shows the main window scrolled to a todo of the active list.
+func (a *App) showTodo(id string) {
+	a.OpenMain()
+	runtime.EventsEmit(a.ctx, "show_todo", id)
+}
+
+// snoozeTodo moves the due date of a todo by d, counting from now if
+// it is already overdue or has none. Days are calendar days in the todo's
+// zone; an all-day todo stays all-day when snoozed by whole days.
+func (a *App) snoozeTodo(id string, d time.Duration) {
+	for _, item := range a.Store.GetTodos() {
+		if item.ID != id {
+			continue
+		}
+		loc := item.Location()
+		wholeDays := d%(24*time.Hour) == 0
+		base := time.Now()
+		switch {
+		case item.DueDate.After(base):
+			base = item.DueDate
+		case item.AllDay && wholeDays:
+			base = dates.EndOfDay(base, loc)
+		}
+		until := dates.Add(base, d, loc)
+		item.DueDate = until
+		item.AllDay = item.AllDay && wholeDays
+		if err := a.Store.UpdateTodo(item); err != nil {
+			slog.Error("snooze todo failed", "id", id, "err", err)
+			return
+		}
+		slog.Info("todo snoozed", "id", id, "until", until)
+		a.notifyUpdate()
+		return
+	}
+}